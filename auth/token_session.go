@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+)
+
+type TokenFunc func(retried bool) (string, error)
+
+type TokenSession struct {
+	tokenFunc          TokenFunc
+	tokenRefreshMetric prometheus.Counter
+
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	token     string
+}
+
+// NewTokenSession wraps the TokenFunc of a UAA session so it can be shared by
+// concurrent requests. The BOSH Director client asks for a new token when a
+// request is rejected with a 401; concurrent rejections only trigger a single
+// refresh and the other requests reuse its token. Requests that were not
+// rejected get the current token without waiting for a refresh in progress.
+func NewTokenSession(tokenFunc TokenFunc, tokenRefreshMetric prometheus.Counter) *TokenSession {
+	return &TokenSession{
+		tokenFunc:          tokenFunc,
+		tokenRefreshMetric: tokenRefreshMetric,
+	}
+}
+
+func (s *TokenSession) TokenFunc(retried bool) (string, error) {
+	// The BOSH Director client does not tell which token was rejected, but
+	// requests are only sent with tokens handed out before, so it is at most
+	// the current one. Once another request refreshed it, it is reused.
+	rejected := s.currentToken()
+	if !retried && rejected != "" {
+		return rejected, nil
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	if token := s.currentToken(); token != rejected {
+		return token, nil
+	}
+
+	if retried {
+		log.Debugln("Refreshing UAA token")
+		s.tokenRefreshMetric.Inc()
+	}
+
+	token, err := s.tokenFunc(retried)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+
+	return token, nil
+}
+
+func (s *TokenSession) currentToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.token
+}
@@ -0,0 +1,182 @@
+package auth_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/auth"
+)
+
+func init() {
+	_ = log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("TokenSession", func() {
+	var (
+		requests           int32
+		refreshes          int32
+		tokenErr           error
+		tokenFunc          TokenFunc
+		tokenRefreshMetric prometheus.Counter
+		tokenSession       *TokenSession
+	)
+
+	refreshTotal := func() float64 {
+		metric := &dto.Metric{}
+		Expect(tokenRefreshMetric.Write(metric)).To(Succeed())
+		return metric.GetCounter().GetValue()
+	}
+
+	BeforeEach(func() {
+		requests = 0
+		refreshes = 0
+		tokenErr = nil
+		tokenFunc = func(retried bool) (string, error) {
+			if !retried {
+				atomic.AddInt32(&requests, 1)
+				return "bearer fake-token", tokenErr
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&refreshes, 1)
+			return "bearer fake-refreshed-token", tokenErr
+		}
+		tokenRefreshMetric = prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "test_namespace",
+			Subsystem: "uaa",
+			Name:      "token_refresh_total",
+			Help:      "Test Counter.",
+		})
+	})
+
+	JustBeforeEach(func() {
+		tokenSession = NewTokenSession(tokenFunc, tokenRefreshMetric)
+	})
+
+	Describe("TokenFunc", func() {
+		Context("when the token was not rejected", func() {
+			It("returns the current token", func() {
+				token, err := tokenSession.TokenFunc(false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).To(Equal("bearer fake-token"))
+				Expect(refreshTotal()).To(Equal(float64(0)))
+			})
+
+			It("reuses the token of the previous requests", func() {
+				_, err := tokenSession.TokenFunc(false)
+				Expect(err).ToNot(HaveOccurred())
+				token, err := tokenSession.TokenFunc(false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).To(Equal("bearer fake-token"))
+				Expect(requests).To(Equal(int32(1)))
+			})
+		})
+
+		Context("when the token was rejected", func() {
+			It("refreshes the token", func() {
+				token, err := tokenSession.TokenFunc(true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).To(Equal("bearer fake-refreshed-token"))
+				Expect(refreshes).To(Equal(int32(1)))
+				Expect(refreshTotal()).To(Equal(float64(1)))
+			})
+
+			It("refreshes the token again when rejected after a refresh", func() {
+				_, err := tokenSession.TokenFunc(true)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = tokenSession.TokenFunc(true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(refreshes).To(Equal(int32(2)))
+				Expect(refreshTotal()).To(Equal(float64(2)))
+			})
+		})
+
+		Context("when the token is rejected concurrently", func() {
+			It("refreshes the token only once", func() {
+				wg := &sync.WaitGroup{}
+				tokens := make(chan string, 10)
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+
+						token, err := tokenSession.TokenFunc(true)
+						Expect(err).ToNot(HaveOccurred())
+						tokens <- token
+					}()
+				}
+				wg.Wait()
+				close(tokens)
+
+				for token := range tokens {
+					Expect(token).To(Equal("bearer fake-refreshed-token"))
+				}
+				Expect(refreshes).To(Equal(int32(1)))
+				Expect(refreshTotal()).To(Equal(float64(1)))
+			})
+		})
+
+		Context("when the token is being refreshed", func() {
+			var (
+				started chan struct{}
+				release chan struct{}
+			)
+
+			BeforeEach(func() {
+				started = make(chan struct{}, 1)
+				release = make(chan struct{})
+				tokenFunc = func(retried bool) (string, error) {
+					if !retried {
+						return "bearer fake-token", nil
+					}
+
+					started <- struct{}{}
+					<-release
+					return "bearer fake-refreshed-token", nil
+				}
+			})
+
+			It("does not hold back the requests that were not rejected", func() {
+				_, err := tokenSession.TokenFunc(false)
+				Expect(err).ToNot(HaveOccurred())
+
+				refreshed := make(chan string, 1)
+				go func() {
+					token, _ := tokenSession.TokenFunc(true)
+					refreshed <- token
+				}()
+				Eventually(started).Should(Receive())
+
+				token, err := tokenSession.TokenFunc(false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).To(Equal("bearer fake-token"))
+
+				close(release)
+				Eventually(refreshed).Should(Receive(Equal("bearer fake-refreshed-token")))
+			})
+		})
+
+		Context("when refreshing the token fails", func() {
+			BeforeEach(func() {
+				tokenErr = errors.New("invalid_client")
+			})
+
+			It("returns an error", func() {
+				_, err := tokenSession.TokenFunc(true)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("invalid_client"))
+				Expect(refreshTotal()).To(Equal(float64(1)))
+			})
+		})
+	})
+})
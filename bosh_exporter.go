@@ -1,30 +1,60 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/cloudfoundry/bosh-cli/director"
 	"github.com/cloudfoundry/bosh-cli/uaa"
 	"github.com/cloudfoundry/bosh-utils/logger"
-	"github.com/cloudfoundry/bosh-utils/system"
+	proxy "github.com/cloudfoundry/socks5-proxy"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/log"
 	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
+	"golang.org/x/crypto/ssh"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 
+	"github.com/bosh-prometheus/bosh_exporter/auth"
+	"github.com/bosh-prometheus/bosh_exporter/certs"
 	"github.com/bosh-prometheus/bosh_exporter/collectors"
+	"github.com/bosh-prometheus/bosh_exporter/configs"
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/directors"
+	"github.com/bosh-prometheus/bosh_exporter/disks"
+	"github.com/bosh-prometheus/bosh_exporter/errands"
+	"github.com/bosh-prometheus/bosh_exporter/events"
 	"github.com/bosh-prometheus/bosh_exporter/filters"
+	"github.com/bosh-prometheus/bosh_exporter/inventory"
+	"github.com/bosh-prometheus/bosh_exporter/probe"
+	"github.com/bosh-prometheus/bosh_exporter/readiness"
+	"github.com/bosh-prometheus/bosh_exporter/resurrection"
+	"github.com/bosh-prometheus/bosh_exporter/tasks"
+	"github.com/bosh-prometheus/bosh_exporter/textfile"
+	"github.com/bosh-prometheus/bosh_exporter/vmtypes"
 )
 
 var (
 	boshURL = kingpin.Flag(
 		"bosh.url", "BOSH URL ($BOSH_EXPORTER_BOSH_URL)",
-	).Envar("BOSH_EXPORTER_BOSH_URL").Required().String()
+	).Envar("BOSH_EXPORTER_BOSH_URL").String()
 
 	boshUsername = kingpin.Flag(
 		"bosh.username", "BOSH Username ($BOSH_EXPORTER_BOSH_USERNAME)",
@@ -34,6 +64,10 @@ var (
 		"bosh.password", "BOSH Password ($BOSH_EXPORTER_BOSH_PASSWORD)",
 	).Envar("BOSH_EXPORTER_BOSH_PASSWORD").String()
 
+	boshPasswordFile = kingpin.Flag(
+		"bosh.password-file", "File containing the BOSH Password, used when bosh.password is not set ($BOSH_EXPORTER_BOSH_PASSWORD_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_PASSWORD_FILE").ExistingFile()
+
 	boshUAAClientID = kingpin.Flag(
 		"bosh.uaa.client-id", "BOSH UAA Client ID ($BOSH_EXPORTER_BOSH_UAA_CLIENT_ID)",
 	).Envar("BOSH_EXPORTER_BOSH_UAA_CLIENT_ID").String()
@@ -42,22 +76,214 @@ var (
 		"bosh.uaa.client-secret", "BOSH UAA Client Secret ($BOSH_EXPORTER_BOSH_UAA_CLIENT_SECRET)",
 	).Envar("BOSH_EXPORTER_BOSH_UAA_CLIENT_SECRET").String()
 
+	boshUAAClientSecretFile = kingpin.Flag(
+		"bosh.uaa.client-secret-file", "File containing the BOSH UAA Client Secret, used when bosh.uaa.client-secret is not set ($BOSH_EXPORTER_BOSH_UAA_CLIENT_SECRET_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_UAA_CLIENT_SECRET_FILE").ExistingFile()
+
 	boshLogLevel = kingpin.Flag(
 		"bosh.log-level", "BOSH Log Level ($BOSH_EXPORTER_BOSH_LOG_LEVEL)",
 	).Envar("BOSH_EXPORTER_BOSH_LOG_LEVEL").Default("ERROR").String()
 
 	boshCACertFile = kingpin.Flag(
-		"bosh.ca-cert-file", "BOSH CA Certificate file ($BOSH_EXPORTER_BOSH_CA_CERT_FILE)",
-	).Envar("BOSH_EXPORTER_BOSH_CA_CERT_FILE").Required().ExistingFile()
+		"bosh.ca-cert-file", "BOSH CA Certificate file, or a directory of .pem/.crt CA Certificate files ($BOSH_EXPORTER_BOSH_CA_CERT_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_CA_CERT_FILE").ExistingFileOrDir()
+
+	boshProxy = kingpin.Flag(
+		"bosh.proxy", "HTTP(S) proxy URL to reach the BOSH Director and UAA, overriding $HTTPS_PROXY and $HTTP_PROXY ($BOSH_EXPORTER_BOSH_PROXY)",
+	).Envar("BOSH_EXPORTER_BOSH_PROXY").String()
+
+	boshSSHTunnelHost = kingpin.Flag(
+		"bosh.ssh-tunnel.host", "SSH gateway (jumpbox) host:port to tunnel the BOSH Director and UAA traffic through ($BOSH_EXPORTER_BOSH_SSH_TUNNEL_HOST)",
+	).Envar("BOSH_EXPORTER_BOSH_SSH_TUNNEL_HOST").String()
+
+	boshSSHTunnelUser = kingpin.Flag(
+		"bosh.ssh-tunnel.user", "User to log in to the SSH gateway as ($BOSH_EXPORTER_BOSH_SSH_TUNNEL_USER)",
+	).Envar("BOSH_EXPORTER_BOSH_SSH_TUNNEL_USER").Default("jumpbox").String()
+
+	boshSSHTunnelPrivateKeyFile = kingpin.Flag(
+		"bosh.ssh-tunnel.private-key-file", "Path to the private key to log in to the SSH gateway with ($BOSH_EXPORTER_BOSH_SSH_TUNNEL_PRIVATE_KEY_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_SSH_TUNNEL_PRIVATE_KEY_FILE").String()
+
+	boshSSHTunnelHostKey = kingpin.Flag(
+		"bosh.ssh-tunnel.host-key", "Public host key of the SSH gateway in the authorized_keys format, the gateway is refused if it presents another key ($BOSH_EXPORTER_BOSH_SSH_TUNNEL_HOST_KEY)",
+	).Envar("BOSH_EXPORTER_BOSH_SSH_TUNNEL_HOST_KEY").String()
+
+	boshDeploymentsFile = kingpin.Flag(
+		"bosh.deployments-file", "Read deployments from a JSON file (as printed by --dump-json) instead of the BOSH Director ($BOSH_EXPORTER_BOSH_DEPLOYMENTS_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_DEPLOYMENTS_FILE").String()
+
+	boshDirectorsFile = kingpin.Flag(
+		"bosh.directors-file", "YAML file listing the BOSH Directors to export (name, url, credentials and ca_cert_file of each), instead of the --bosh.url flags ($BOSH_EXPORTER_BOSH_DIRECTORS_FILE)",
+	).Envar("BOSH_EXPORTER_BOSH_DIRECTORS_FILE").String()
+
+	boshMaxInFlight = kingpin.Flag(
+		"bosh.max-inflight", "Maximum number of BOSH deployments to fetch concurrently ($BOSH_EXPORTER_BOSH_MAX_INFLIGHT)",
+	).Envar("BOSH_EXPORTER_BOSH_MAX_INFLIGHT").Default("16").Int()
+
+	boshContinueOnError = kingpin.Flag(
+		"bosh.continue-on-error", "Flag the scrape as failed, listing the deployments that could not be fetched ($BOSH_EXPORTER_BOSH_CONTINUE_ON_ERROR)",
+	).Envar("BOSH_EXPORTER_BOSH_CONTINUE_ON_ERROR").Default("false").Bool()
+
+	boshMetadataCacheTTL = kingpin.Flag(
+		"bosh.metadata-cache-ttl", "How long to cache BOSH deployment releases and stemcells, and the releases and stemcells uploaded to BOSH, between scrapes, 0 disables the cache ($BOSH_EXPORTER_BOSH_METADATA_CACHE_TTL)",
+	).Envar("BOSH_EXPORTER_BOSH_METADATA_CACHE_TTL").Default("0s").Duration()
+
+	boshFetchTimeout = kingpin.Flag(
+		"bosh.fetch-timeout", "Maximum time to wait for all BOSH deployments to be fetched, 0 disables the timeout ($BOSH_EXPORTER_BOSH_FETCH_TIMEOUT)",
+	).Envar("BOSH_EXPORTER_BOSH_FETCH_TIMEOUT").Default("0s").Duration()
+
+	boshRetryAttempts = kingpin.Flag(
+		"bosh.retry-attempts", "Maximum number of attempts for BOSH Director calls failing with transient errors ($BOSH_EXPORTER_BOSH_RETRY_ATTEMPTS)",
+	).Envar("BOSH_EXPORTER_BOSH_RETRY_ATTEMPTS").Default("1").Int()
+
+	boshRetryBackoff = kingpin.Flag(
+		"bosh.retry-backoff", "Time to wait before the first retry of a BOSH Director call, doubled on every further retry ($BOSH_EXPORTER_BOSH_RETRY_BACKOFF)",
+	).Envar("BOSH_EXPORTER_BOSH_RETRY_BACKOFF").Default("1s").Duration()
+
+	boshRequestsPerSecond = kingpin.Flag(
+		"bosh.requests-per-second", "Maximum number of BOSH Director calls per second made while fetching deployments, 0 disables the limit ($BOSH_EXPORTER_BOSH_REQUESTS_PER_SECOND)",
+	).Envar("BOSH_EXPORTER_BOSH_REQUESTS_PER_SECOND").Default("0").Float64()
+
+	boshIncludeNoVMInstances = kingpin.Flag(
+		"bosh.include-novm-instances", "Include instances without a VM, reporting them as unhealthy without vitals ($BOSH_EXPORTER_BOSH_INCLUDE_NOVM_INSTANCES)",
+	).Envar("BOSH_EXPORTER_BOSH_INCLUDE_NOVM_INSTANCES").Default("false").Bool()
+
+	boshNoVMAsUnhealthy = kingpin.Flag(
+		"bosh.novm-as-unhealthy", "Report instances without a VM as unhealthy with the 'no_vm' reason instead of skipping them ($BOSH_EXPORTER_BOSH_NOVM_AS_UNHEALTHY)",
+	).Envar("BOSH_EXPORTER_BOSH_NOVM_AS_UNHEALTHY").Default("false").Bool()
+
+	boshPreferIPFamily = kingpin.Flag(
+		"bosh.prefer-ip-family", "IP family of Service Discovery targets: the first 'ipv4' or 'ipv6' address (falling back to any family), or 'all' addresses ($BOSH_EXPORTER_BOSH_PREFER_IP_FAMILY)",
+	).Envar("BOSH_EXPORTER_BOSH_PREFER_IP_FAMILY").Default(filters.IPv4Family).Enum(filters.IPv4Family, filters.IPv6Family, filters.AllFamily)
+
+	boshOnlyUnhealthy = kingpin.Flag(
+		"bosh.only-unhealthy", "Only report Job vitals and process metrics for unhealthy instances ($BOSH_EXPORTER_BOSH_ONLY_UNHEALTHY)",
+	).Envar("BOSH_EXPORTER_BOSH_ONLY_UNHEALTHY").Default("false").Bool()
+
+	boshInstanceInfoMetrics = kingpin.Flag(
+		"bosh.instance-info-metrics", "Report a Job Instance info metric labeled with the agent ID, VM CID and state of each instance ($BOSH_EXPORTER_BOSH_INSTANCE_INFO_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCE_INFO_METRICS").Default("false").Bool()
+
+	boshInstanceGroupMetrics = kingpin.Flag(
+		"bosh.instance-group-metrics", "Report Job vitals and failing processes aggregated by instance group ($BOSH_EXPORTER_BOSH_INSTANCE_GROUP_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCE_GROUP_METRICS").Default("false").Bool()
+
+	boshVitalsVMTypeLabel = kingpin.Flag(
+		"bosh.vitals-vm-type-label", "Add a bosh_job_vm_type label to the Job vitals metrics ($BOSH_EXPORTER_BOSH_VITALS_VM_TYPE_LABEL)",
+	).Envar("BOSH_EXPORTER_BOSH_VITALS_VM_TYPE_LABEL").Default("false").Bool()
+
+	boshTasksLimit = kingpin.Flag(
+		"bosh.tasks-limit", "Maximum number of recent BOSH tasks to inspect for task metrics, 0 disables task metrics ($BOSH_EXPORTER_BOSH_TASKS_LIMIT)",
+	).Envar("BOSH_EXPORTER_BOSH_TASKS_LIMIT").Default("0").Int()
+
+	boshEventsLookback = kingpin.Flag(
+		"bosh.events-lookback", "Maximum age of BOSH events to count for event metrics, 0 disables event metrics ($BOSH_EXPORTER_BOSH_EVENTS_LOOKBACK)",
+	).Envar("BOSH_EXPORTER_BOSH_EVENTS_LOOKBACK").Default("0s").Duration()
+
+	boshConfigMetrics = kingpin.Flag(
+		"bosh.config-metrics", "Report the versions of the latest BOSH cloud and runtime configs ($BOSH_EXPORTER_BOSH_CONFIG_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_CONFIG_METRICS").Default("false").Bool()
+
+	boshReleaseMetrics = kingpin.Flag(
+		"bosh.release-metrics", "Report the releases uploaded to BOSH, and whether any deployment uses them ($BOSH_EXPORTER_BOSH_RELEASE_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_RELEASE_METRICS").Default("false").Bool()
+
+	boshResurrectionMetrics = kingpin.Flag(
+		"bosh.resurrection-metrics", "Report whether BOSH resurrection is enabled director-wide ($BOSH_EXPORTER_BOSH_RESURRECTION_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_RESURRECTION_METRICS").Default("false").Bool()
+
+	boshResurrectionCacheTTL = kingpin.Flag(
+		"bosh.resurrection-cache-ttl", "How long the director-wide BOSH resurrection state is cached before it is read again ($BOSH_EXPORTER_BOSH_RESURRECTION_CACHE_TTL)",
+	).Envar("BOSH_EXPORTER_BOSH_RESURRECTION_CACHE_TTL").Default("5m").Duration()
+
+	boshOrphanedDiskMetrics = kingpin.Flag(
+		"bosh.orphaned-disk-metrics", "Report BOSH Orphaned Disks ($BOSH_EXPORTER_BOSH_ORPHANED_DISK_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_ORPHANED_DISK_METRICS").Default("false").Bool()
+
+	boshVMTypeMetrics = kingpin.Flag(
+		"bosh.vm-type-metrics", "Report the resources requested by the Job VM Types in the Cloud Config ($BOSH_EXPORTER_BOSH_VM_TYPE_METRICS)",
+	).Envar("BOSH_EXPORTER_BOSH_VM_TYPE_METRICS").Default("false").Bool()
+
+	boshErrandRunsLimit = kingpin.Flag(
+		"bosh.errand-runs-limit", "Maximum number of recent BOSH tasks to inspect for errand run metrics, 0 disables errand run metrics ($BOSH_EXPORTER_BOSH_ERRAND_RUNS_LIMIT)",
+	).Envar("BOSH_EXPORTER_BOSH_ERRAND_RUNS_LIMIT").Default("0").Int()
+
+	boshDeprecatedStemcells = kingpin.Flag(
+		"bosh.deprecated-stemcells", "Comma separated stemcells (name/version, version accepts '*' wildcards) to report as deprecated ($BOSH_EXPORTER_BOSH_DEPRECATED_STEMCELLS)",
+	).Envar("BOSH_EXPORTER_BOSH_DEPRECATED_STEMCELLS").Default("").String()
+
+	boshDeprecatedReleases = kingpin.Flag(
+		"bosh.deprecated-releases", "Comma separated releases (name/version, version accepts '*' wildcards) to report as deprecated ($BOSH_EXPORTER_BOSH_DEPRECATED_RELEASES)",
+	).Envar("BOSH_EXPORTER_BOSH_DEPRECATED_RELEASES").Default("").String()
+
+	boshMetricsInclude = kingpin.Flag(
+		"bosh.metrics.include", "Comma separated glob patterns of Job metric names (without the namespace, e.g. job_cpu_*) to report, all if not set ($BOSH_EXPORTER_BOSH_METRICS_INCLUDE)",
+	).Envar("BOSH_EXPORTER_BOSH_METRICS_INCLUDE").Default("").String()
+
+	boshMetricsExclude = kingpin.Flag(
+		"bosh.metrics.exclude", "Comma separated glob patterns of Job metric names (without the namespace) not to report, takes precedence over the include patterns ($BOSH_EXPORTER_BOSH_METRICS_EXCLUDE)",
+	).Envar("BOSH_EXPORTER_BOSH_METRICS_EXCLUDE").Default("").String()
+
+	boshDeploymentTags = kingpin.Flag(
+		"bosh.deployment-tags", "Comma separated deployment manifest tag keys to report as Deployment Info labels ($BOSH_EXPORTER_BOSH_DEPLOYMENT_TAGS)",
+	).Envar("BOSH_EXPORTER_BOSH_DEPLOYMENT_TAGS").Default("").String()
+
+	boshInstanceGroups = kingpin.Flag(
+		"bosh.instance-groups", "Comma separated instance groups (job names) to filter, entries prefixed with ~ are matched as regexps ($BOSH_EXPORTER_BOSH_INSTANCE_GROUPS)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCE_GROUPS").Default("").String()
+
+	boshInstanceGroupsExclude = kingpin.Flag(
+		"bosh.instance-groups-exclude", "Comma separated instance groups (job names) to exclude, entries prefixed with ~ are matched as regexps, takes precedence over the instance groups filter ($BOSH_EXPORTER_BOSH_INSTANCE_GROUPS_EXCLUDE)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCE_GROUPS_EXCLUDE").Default("").String()
+
+	boshInstancesTimeout = kingpin.Flag(
+		"bosh.instances-timeout", "Maximum time to wait for the Instances of a single BOSH deployment to be read, 0 disables the timeout ($BOSH_EXPORTER_BOSH_INSTANCES_TIMEOUT)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCES_TIMEOUT").Default("0s").Duration()
+
+	boshInstancesWarningThreshold = kingpin.Flag(
+		"bosh.instances-warning-threshold", "Log a warning when a deployment returns more instances than this threshold, 0 to disable ($BOSH_EXPORTER_BOSH_INSTANCES_WARNING_THRESHOLD)",
+	).Envar("BOSH_EXPORTER_BOSH_INSTANCES_WARNING_THRESHOLD").Default("0").Int()
+
+	boshMaxInstances = kingpin.Flag(
+		"bosh.max-instances", "Maximum number of instances read from all BOSH deployments in a single scrape, the scrape is aborted when exceeded, 0 disables the limit ($BOSH_EXPORTER_BOSH_MAX_INSTANCES)",
+	).Envar("BOSH_EXPORTER_BOSH_MAX_INSTANCES").Default("0").Int()
+
+	boshScrapeInterval = kingpin.Flag(
+		"bosh.scrape-interval", "Fetch deployments from BOSH in the background every interval and serve the last fetched metrics, 0 fetches on every scrape ($BOSH_EXPORTER_BOSH_SCRAPE_INTERVAL)",
+	).Envar("BOSH_EXPORTER_BOSH_SCRAPE_INTERVAL").Default("0").Duration()
+
+	boshCircuitBreakerThreshold = kingpin.Flag(
+		"bosh.circuit-breaker-threshold", "Number of consecutive failed scrapes of the BOSH Director after which scrapes are skipped for bosh.circuit-breaker-cooldown, 0 to disable ($BOSH_EXPORTER_BOSH_CIRCUIT_BREAKER_THRESHOLD)",
+	).Envar("BOSH_EXPORTER_BOSH_CIRCUIT_BREAKER_THRESHOLD").Default("0").Int()
+
+	boshCircuitBreakerCooldown = kingpin.Flag(
+		"bosh.circuit-breaker-cooldown", "How long scrapes of the BOSH Director are skipped once the circuit breaker opens ($BOSH_EXPORTER_BOSH_CIRCUIT_BREAKER_COOLDOWN)",
+	).Envar("BOSH_EXPORTER_BOSH_CIRCUIT_BREAKER_COOLDOWN").Default("1m").Duration()
+
+	boshExcludeProcesses = kingpin.Flag(
+		"bosh.exclude-processes", "Comma separated regexps of BOSH Job Process names to skip when reading instances ($BOSH_EXPORTER_BOSH_EXCLUDE_PROCESSES)",
+	).Envar("BOSH_EXPORTER_BOSH_EXCLUDE_PROCESSES").Default("").String()
+
+	boshCountExcludedProcesses = kingpin.Flag(
+		"bosh.count-excluded-processes", "Still count the processes skipped by bosh.exclude-processes in the Job processes metrics ($BOSH_EXPORTER_BOSH_COUNT_EXCLUDED_PROCESSES)",
+	).Envar("BOSH_EXPORTER_BOSH_COUNT_EXCLUDED_PROCESSES").Default("false").Bool()
+
+	boshDeploymentsExclude = kingpin.Flag(
+		"bosh.deployments-exclude", "Comma separated deployments to exclude, takes precedence over the deployments filter ($BOSH_EXPORTER_BOSH_DEPLOYMENTS_EXCLUDE)",
+	).Envar("BOSH_EXPORTER_BOSH_DEPLOYMENTS_EXCLUDE").Default("").String()
 
 	filterDeployments = kingpin.Flag(
-		"filter.deployments", "Comma separated deployments to filter ($BOSH_EXPORTER_FILTER_DEPLOYMENTS)",
+		"filter.deployments", "Comma separated deployments to filter, entries prefixed with '~' are matched as regexps ($BOSH_EXPORTER_FILTER_DEPLOYMENTS)",
 	).Envar("BOSH_EXPORTER_FILTER_DEPLOYMENTS").Default("").String()
 
 	filterAZs = kingpin.Flag(
 		"filter.azs", "Comma separated AZs to filter ($BOSH_EXPORTER_FILTER_AZS)",
 	).Envar("BOSH_EXPORTER_FILTER_AZS").Default("").String()
 
+	filterAZsFetch = kingpin.Flag(
+		"filter.azs-fetch", "Apply filter.azs when fetching instances, so Deployment metrics only count the instances in these AZs ($BOSH_EXPORTER_FILTER_AZS_FETCH)",
+	).Envar("BOSH_EXPORTER_FILTER_AZS_FETCH").Default("false").Bool()
+
 	filterCollectors = kingpin.Flag(
 		"filter.collectors", "Comma separated collectors to filter (Deployments,Jobs,ServiceDiscovery) ($BOSH_EXPORTER_FILTER_COLLECTORS)",
 	).Envar("BOSH_EXPORTER_FILTER_COLLECTORS").Default("").String()
@@ -82,14 +308,50 @@ var (
 		"sd.processes_regexp", "Regexp to filter Service Discovery processes names ($BOSH_EXPORTER_SD_PROCESSES_REGEXP)",
 	).Envar("BOSH_EXPORTER_SD_PROCESSES_REGEXP").Default("").String()
 
+	sdGroupBy = kingpin.Flag(
+		"sd.group_by", "Group Service Discovery targets by 'process' or by 'job' ($BOSH_EXPORTER_SD_GROUP_BY)",
+	).Envar("BOSH_EXPORTER_SD_GROUP_BY").Default(collectors.ServiceDiscoveryGroupByProcess).Enum(collectors.ServiceDiscoveryGroupByProcess, collectors.ServiceDiscoveryGroupByJob)
+
+	sdInstanceLabels = kingpin.Flag(
+		"sd.instance_labels", "Comma separated instance fields to attach as Service Discovery target labels (az,deployment,instance_group,index,id) ($BOSH_EXPORTER_SD_INSTANCE_LABELS)",
+	).Envar("BOSH_EXPORTER_SD_INSTANCE_LABELS").Default("").String()
+
+	dumpJSON = kingpin.Flag(
+		"dump-json", "Fetch all deployments once, print them to stdout as JSON and exit ($BOSH_EXPORTER_DUMP_JSON)",
+	).Envar("BOSH_EXPORTER_DUMP_JSON").Default("false").Bool()
+
+	textfileDirectory = kingpin.Flag(
+		"textfile.directory", "Directory to periodically write metrics to as a node_exporter textfile instead of serving them over HTTP ($BOSH_EXPORTER_TEXTFILE_DIRECTORY)",
+	).Envar("BOSH_EXPORTER_TEXTFILE_DIRECTORY").Default("").String()
+
+	textfileInterval = kingpin.Flag(
+		"textfile.interval", "How often to write metrics to the textfile directory ($BOSH_EXPORTER_TEXTFILE_INTERVAL)",
+	).Envar("BOSH_EXPORTER_TEXTFILE_INTERVAL").Default("1m").Duration()
+
+	validate = kingpin.Flag(
+		"validate", "Check the flags, certificates and BOSH Director access, print the results and exit ($BOSH_EXPORTER_VALIDATE)",
+	).Envar("BOSH_EXPORTER_VALIDATE").Default("false").Bool()
+
 	listenAddress = kingpin.Flag(
 		"web.listen-address", "Address to listen on for web interface and telemetry ($BOSH_EXPORTER_WEB_LISTEN_ADDRESS)",
 	).Envar("BOSH_EXPORTER_WEB_LISTEN_ADDRESS").Default(":9190").String()
 
+	webShutdownTimeout = kingpin.Flag(
+		"web.shutdown-timeout", "Maximum time to wait for in-flight scrapes, or the textfile write in progress, to finish on SIGTERM or SIGINT ($BOSH_EXPORTER_WEB_SHUTDOWN_TIMEOUT)",
+	).Envar("BOSH_EXPORTER_WEB_SHUTDOWN_TIMEOUT").Default("30s").Duration()
+
 	metricsPath = kingpin.Flag(
 		"web.telemetry-path", "Path under which to expose Prometheus metrics ($BOSH_EXPORTER_WEB_TELEMETRY_PATH)",
 	).Envar("BOSH_EXPORTER_WEB_TELEMETRY_PATH").Default("/metrics").String()
 
+	readyCacheTTL = kingpin.Flag(
+		"web.ready-cache-ttl", "How long to cache the BOSH Director check of the /ready endpoint ($BOSH_EXPORTER_WEB_READY_CACHE_TTL)",
+	).Envar("BOSH_EXPORTER_WEB_READY_CACHE_TTL").Default("5s").Duration()
+
+	deploymentsEndpoint = kingpin.Flag(
+		"web.deployments-endpoint", "Enable the /deployments endpoint listing the scraped deployments as JSON ($BOSH_EXPORTER_WEB_DEPLOYMENTS_ENDPOINT)",
+	).Envar("BOSH_EXPORTER_WEB_DEPLOYMENTS_ENDPOINT").Default("false").Bool()
+
 	authUsername = kingpin.Flag(
 		"web.auth.username", "Username for web interface basic auth ($BOSH_EXPORTER_WEB_AUTH_USERNAME)",
 	).Envar("BOSH_EXPORTER_WEB_AUTH_USERNAME").String()
@@ -98,6 +360,10 @@ var (
 		"web.auth.password", "Password for web interface basic auth ($BOSH_EXPORTER_WEB_AUTH_PASSWORD)",
 	).Envar("BOSH_EXPORTER_WEB_AUTH_PASSWORD").String()
 
+	authPasswordFile = kingpin.Flag(
+		"web.auth.password-file", "File containing the Password for web interface basic auth, used when web.auth.password is not set ($BOSH_EXPORTER_WEB_AUTH_PASSWORD_FILE)",
+	).Envar("BOSH_EXPORTER_WEB_AUTH_PASSWORD_FILE").ExistingFile()
+
 	tlsCertFile = kingpin.Flag(
 		"web.tls.cert_file", "Path to a file that contains the TLS certificate (PEM format). If the certificate is signed by a certificate authority, the file should be the concatenation of the server's certificate, any intermediates, and the CA's certificate ($BOSH_EXPORTER_WEB_TLS_CERTFILE)",
 	).Envar("BOSH_EXPORTER_WEB_TLS_CERTFILE").ExistingFile()
@@ -105,10 +371,22 @@ var (
 	tlsKeyFile = kingpin.Flag(
 		"web.tls.key_file", "Path to a file that contains the TLS private key (PEM format) ($BOSH_EXPORTER_WEB_TLS_KEYFILE)",
 	).Envar("BOSH_EXPORTER_WEB_TLS_KEYFILE").ExistingFile()
+
+	webConfigFile = kingpin.Flag(
+		"web.config.file", "Path to a Prometheus web configuration file that can enable TLS or basic auth ($BOSH_EXPORTER_WEB_CONFIG_FILE)",
+	).Envar("BOSH_EXPORTER_WEB_CONFIG_FILE").ExistingFile()
+
+	logLevel = kingpin.Flag(
+		"log.level", "Only log messages with the given severity or above. Valid levels: [debug, info, warn, error, fatal] ($BOSH_EXPORTER_LOG_LEVEL)",
+	).Envar("BOSH_EXPORTER_LOG_LEVEL").Default("info").Enum("debug", "info", "warn", "error", "fatal")
+
+	logFormat = kingpin.Flag(
+		"log.format", "Log format, one of 'logfmt' or 'json', or a log target such as 'logger:stdout?json=true' ($BOSH_EXPORTER_LOG_FORMAT)",
+	).Envar("BOSH_EXPORTER_LOG_FORMAT").Default("logfmt").String()
 )
 
 func init() {
-	prometheus.MustRegister(version.NewCollector(*metricsNamespace))
+	prometheus.MustRegister(version.NewCollector("bosh_exporter"))
 }
 
 type basicAuthHandler struct {
@@ -119,7 +397,11 @@ type basicAuthHandler struct {
 
 func (h *basicAuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	username, password, ok := r.BasicAuth()
-	if !ok || username != h.username || password != h.password {
+	// Compare in constant time so that response times do not reveal how much
+	// of the credentials matched.
+	validUsername := subtle.ConstantTimeCompare([]byte(username), []byte(h.username)) == 1
+	validPassword := subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+	if !ok || !validUsername || !validPassword {
 		log.Errorf("Invalid HTTP auth from `%s`", r.RemoteAddr)
 		w.Header().Set("WWW-Authenticate", "Basic realm=\"metrics\"")
 		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
@@ -138,12 +420,20 @@ func (cu boshConfigUpdater) Save() error {
 	return nil
 }
 
-func prometheusHandler() http.Handler {
-	handler := promhttp.Handler()
+// prometheusHandler serves the gathered metrics in the Prometheus text format,
+// or in the OpenMetrics format when the scraper asks for it. It gzips the
+// response whenever the scraper sends Accept-Encoding: gzip, as the output
+// grows with every deployment instance.
+func prometheusHandler(registerer prometheus.Registerer, gatherer prometheus.Gatherer) http.Handler {
+	return authHandler(promhttp.InstrumentMetricHandler(
+		registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{DisableCompression: false, EnableOpenMetrics: true}),
+	))
+}
 
+func authHandler(handler http.Handler) http.Handler {
 	if *authUsername != "" && *authPassword != "" {
 		handler = &basicAuthHandler{
-			handler:  promhttp.Handler().ServeHTTP,
+			handler:  handler.ServeHTTP,
 			username: *authUsername,
 			password: *authPassword,
 		}
@@ -152,27 +442,179 @@ func prometheusHandler() http.Handler {
 	return handler
 }
 
-func readCACert(CACertFile string, logger logger.Logger) (string, error) {
-	if CACertFile != "" {
-		fs := system.NewOsFileSystem(logger)
+// readSecret returns value when it is set, so a flag or its environment
+// variable takes precedence over the secret file. Otherwise it returns the
+// contents of filename, if any, without trailing newlines.
+func readSecret(value string, filename string) (string, error) {
+	if value != "" || filename == "" {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
 
-		CACertFileFullPath, err := fs.ExpandPath(CACertFile)
+// loadSecretFiles replaces the secret flags that are not set with the
+// contents of their secret files.
+func loadSecretFiles() error {
+	for _, secret := range []struct {
+		flag     string
+		value    *string
+		filename *string
+	}{
+		{"--bosh.password-file", boshPassword, boshPasswordFile},
+		{"--bosh.uaa.client-secret-file", boshUAAClientSecret, boshUAAClientSecretFile},
+		{"--web.auth.password-file", authPassword, authPasswordFile},
+	} {
+		value, err := readSecret(*secret.value, *secret.filename)
 		if err != nil {
-			return "", err
+			return fmt.Errorf("Error reading %s: %v", secret.flag, err)
 		}
+		*secret.value = value
+	}
 
-		CACert, err := fs.ReadFileString(CACertFileFullPath)
-		if err != nil {
-			return "", err
+	return nil
+}
+
+// readDirectorsFile reads the directors file, replacing the passwords and UAA
+// client secrets that are not set with the contents of their secret files.
+func readDirectorsFile(filename string) ([]directors.Config, error) {
+	directorConfigs, err := directors.ReadConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range directorConfigs {
+		directorConfig := &directorConfigs[i]
+		for _, secret := range []struct {
+			field    string
+			value    *string
+			filename string
+		}{
+			{"password_file", &directorConfig.Password, directorConfig.PasswordFile},
+			{"uaa_client_secret_file", &directorConfig.UAAClientSecret, directorConfig.UAAClientSecretFile},
+		} {
+			value, err := readSecret(*secret.value, secret.filename)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %s of Director `%s`: %v", secret.field, directorConfig.Name, err)
+			}
+			*secret.value = value
+		}
+	}
+
+	return directorConfigs, nil
+}
+
+// setupProxy validates proxyURL and, if set, makes it the proxy of every
+// BOSH Director and UAA client. The clients read the proxy from HTTPS_PROXY
+// and HTTP_PROXY on their first request, so it must be called before any
+// client is built. NO_PROXY is still honored.
+func setupProxy(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("Error parsing --bosh.proxy: %v", err)
+	}
+
+	switch parsedURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("Flag --bosh.proxy must be an http, https or socks5 URL, got `%s`", parsedURL.Redacted())
+	}
+
+	if parsedURL.Host == "" {
+		return fmt.Errorf("Flag --bosh.proxy must include a host, got `%s`", parsedURL.Redacted())
+	}
+
+	for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY"} {
+		if err := os.Setenv(name, proxyURL); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// setupSSHTunnel logs in to the SSH gateway at host and, if set, makes a
+// local SOCKS5 proxy forwarding through it the proxy of every BOSH Director
+// and UAA client, as setupProxy does. The SSH connection is established
+// right away, so that an unreachable gateway, a rejected key or a gateway
+// presenting another host key than hostKey fails at startup rather than on
+// the first scrape.
+func setupSSHTunnel(host string, user string, privateKeyFile string, hostKey string) error {
+	if host == "" {
+		if privateKeyFile != "" {
+			return errors.New("Flag --bosh.ssh-tunnel.private-key-file requires --bosh.ssh-tunnel.host")
+		}
+		if hostKey != "" {
+			return errors.New("Flag --bosh.ssh-tunnel.host-key requires --bosh.ssh-tunnel.host")
+		}
+		return nil
+	}
+
+	if privateKeyFile == "" {
+		return errors.New("Flag --bosh.ssh-tunnel.host requires --bosh.ssh-tunnel.private-key-file")
+	}
+
+	if hostKey == "" {
+		return errors.New("Flag --bosh.ssh-tunnel.host requires --bosh.ssh-tunnel.host-key")
+	}
 
-		return CACert, nil
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		return fmt.Errorf("Error parsing --bosh.ssh-tunnel.host-key: %v", err)
+	}
+
+	privateKey, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return fmt.Errorf("Error reading --bosh.ssh-tunnel.private-key-file: %v", err)
 	}
 
-	return "", nil
+	socks5Proxy := proxy.NewSocks5Proxy(fixedHostKey{publicKey: publicKey}, log.NewErrorLogger(), 1*time.Minute)
+	if err := socks5Proxy.Start(user, string(privateKey), host); err != nil {
+		return fmt.Errorf("Error establishing the SSH tunnel through `%s`: %v", host, err)
+	}
+
+	proxyAddr, err := socks5Proxy.Addr()
+	if err != nil {
+		return err
+	}
+	log.Infof("Tunneling the BOSH Director traffic through `%s@%s`", user, host)
+
+	return setupProxy(fmt.Sprintf("socks5://%s", proxyAddr))
+}
+
+// fixedHostKey is the host key the SOCKS5 proxy verifies the SSH gateway
+// with, instead of the one it would otherwise read from the gateway itself.
+type fixedHostKey struct {
+	publicKey ssh.PublicKey
 }
 
-func buildBOSHClient() (director.Director, error) {
+func (k fixedHostKey) Get(username, privateKey, serverURL string) (ssh.PublicKey, error) {
+	return k.publicKey, nil
+}
+
+// flagsDirectorConfig returns the BOSH Director set by the --bosh.url flags,
+// used unless --bosh.directors-file is set.
+func flagsDirectorConfig() directors.Config {
+	return directors.Config{
+		URL:             *boshURL,
+		Username:        *boshUsername,
+		Password:        *boshPassword,
+		UAAClientID:     *boshUAAClientID,
+		UAAClientSecret: *boshUAAClientSecret,
+		CACertFile:      *boshCACertFile,
+	}
+}
+
+func buildBOSHClient(config directors.Config, registerer prometheus.Registerer) (director.Director, error) {
 	logLevel, err := logger.Levelify(*boshLogLevel)
 	if err != nil {
 		return nil, err
@@ -180,12 +622,12 @@ func buildBOSHClient() (director.Director, error) {
 
 	logger := logger.NewLogger(logLevel)
 
-	directorConfig, err := director.NewConfigFromURL(*boshURL)
+	directorConfig, err := director.NewConfigFromURL(config.URL)
 	if err != nil {
 		return nil, err
 	}
 
-	boshCACert, err := readCACert(*boshCACertFile, logger)
+	boshCACert, err := certs.ReadCACert(config.CACertFile, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -202,8 +644,8 @@ func buildBOSHClient() (director.Director, error) {
 	}
 
 	if boshInfo.Auth.Type != "uaa" {
-		directorConfig.Client = *boshUsername
-		directorConfig.ClientSecret = *boshPassword
+		directorConfig.Client = config.Username
+		directorConfig.ClientSecret = config.Password
 	} else {
 		uaaURL := boshInfo.Auth.Options["url"]
 		uaaURLStr, ok := uaaURL.(string)
@@ -218,30 +660,47 @@ func buildBOSHClient() (director.Director, error) {
 
 		uaaConfig.CACert = boshCACert
 
-		if *boshUAAClientID != "" && *boshUAAClientSecret != "" {
-			uaaConfig.Client = *boshUAAClientID
-			uaaConfig.ClientSecret = *boshUAAClientSecret
+		if config.UAAClientID != "" && config.UAAClientSecret != "" {
+			uaaConfig.Client = config.UAAClientID
+			uaaConfig.ClientSecret = config.UAAClientSecret
 		} else {
 			uaaConfig.Client = "bosh_cli"
 		}
 
+		tokenRefreshMetric := prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: *metricsNamespace,
+				Subsystem: "uaa",
+				Name:      "token_refresh_total",
+				Help:      "Total number of UAA token refreshes after the BOSH Director rejected the token.",
+				ConstLabels: prometheus.Labels{
+					"environment": *metricsEnvironment,
+					"bosh_name":   boshInfo.Name,
+					"bosh_uuid":   boshInfo.UUID,
+				},
+			},
+		)
+		if err := registerer.Register(tokenRefreshMetric); err != nil {
+			return nil, err
+		}
+
 		uaaFactory := uaa.NewFactory(logger)
 		uaaClient, err := uaaFactory.New(uaaConfig)
 		if err != nil {
 			return nil, err
 		}
 
-		if *boshUAAClientID != "" && *boshUAAClientSecret != "" {
-			directorConfig.TokenFunc = uaa.NewClientTokenSession(uaaClient).TokenFunc
+		if config.UAAClientID != "" && config.UAAClientSecret != "" {
+			directorConfig.TokenFunc = auth.NewTokenSession(uaa.NewClientTokenSession(uaaClient).TokenFunc, tokenRefreshMetric).TokenFunc
 		} else {
 			answers := []uaa.PromptAnswer{
 				uaa.PromptAnswer{
 					Key:   "username",
-					Value: *boshUsername,
+					Value: config.Username,
 				},
 				uaa.PromptAnswer{
 					Key:   "password",
-					Value: *boshPassword,
+					Value: config.Password,
 				},
 			}
 			accessToken, err := uaaClient.OwnerPasswordCredentialsGrant(answers)
@@ -255,7 +714,7 @@ func buildBOSHClient() (director.Director, error) {
 			}
 
 			origToken := uaa.NewRefreshableAccessToken(accessToken.Type(), accessToken.Value(), refreshToken)
-			directorConfig.TokenFunc = uaa.NewAccessTokenSession(uaaClient, origToken, boshConfigUpdater{}, "").TokenFunc
+			directorConfig.TokenFunc = auth.NewTokenSession(uaa.NewAccessTokenSession(uaaClient, origToken, boshConfigUpdater{}, "").TokenFunc, tokenRefreshMetric).TokenFunc
 		}
 	}
 
@@ -268,101 +727,1173 @@ func buildBOSHClient() (director.Director, error) {
 	return boshClient, nil
 }
 
-func main() {
-	log.AddFlags(kingpin.CommandLine)
-	kingpin.Version(version.Print("fbosh_exporter"))
-	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
-
-	log.Infoln("Starting bosh_exporter", version.Info())
-	log.Infoln("Build context", version.BuildContext())
+func buildBOSHDirector(config directors.Config, registerer prometheus.Registerer) (director.Director, director.Info, error) {
+	if config.URL == "" || config.CACertFile == "" {
+		return nil, director.Info{}, errors.New("Flags --bosh.url and --bosh.ca-cert-file are required unless --bosh.deployments-file or --bosh.directors-file is set")
+	}
 
-	boshClient, err := buildBOSHClient()
+	boshClient, err := buildBOSHClient(config, registerer)
 	if err != nil {
-		log.Errorf("Error creating BOSH Client: %s", err.Error())
-		os.Exit(1)
+		return nil, director.Info{}, fmt.Errorf("Error creating BOSH Client: %s", err.Error())
 	}
 
 	boshInfo, err := boshClient.Info()
 	if err != nil {
-		log.Errorf("Error reading BOSH Info: %s", err.Error())
-		os.Exit(1)
+		return nil, director.Info{}, fmt.Errorf("Error reading BOSH Info: %s", err.Error())
 	}
 	log.Infof("Using BOSH Director `%s` (%s)", boshInfo.Name, boshInfo.UUID)
 
+	return boshClient, boshInfo, nil
+}
+
+// setupDirectors sets up every director of the directors file with its own
+// client, fetcher and collectors, registered with a director label, and adds
+// them to boshClients and lastSources. The registry collects them
+// concurrently, so a slow director does not delay the metrics of the others.
+// Directors are set up concurrently too, and one that cannot be set up is
+// skipped and reported as down instead of stopping the exporter.
+func setupDirectors(background context.Context, registerer prometheus.Registerer, directorConfigs []directors.Config, collectorFilters collectorFilters, boshClients map[string]director.Director, lastSources map[string]*deployments.LastSource) []*deployments.Fetcher {
+	setups := make([]directorSetup, len(directorConfigs))
+	setupErrs := make([]error, len(directorConfigs))
+	wg := &sync.WaitGroup{}
+	for i, directorConfig := range directorConfigs {
+		wg.Add(1)
+		go func(i int, directorConfig directors.Config) {
+			defer wg.Done()
+			setups[i], setupErrs[i] = setupDirector(background, registerer, directorConfig, collectorFilters)
+		}(i, directorConfig)
+	}
+	wg.Wait()
+
+	var deploymentsFetchers []*deployments.Fetcher
+	for i, directorConfig := range directorConfigs {
+		if setupErrs[i] != nil {
+			log.Errorf("Error setting up BOSH Director `%s`, skipping it: %v", directorConfig.Name, setupErrs[i])
+			directorRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"director": directorConfig.Name}, registerer)
+			if err := directorRegisterer.Register(collectors.NewDirectorUpMetric(*metricsNamespace, *metricsEnvironment, "", "")); err != nil {
+				log.Errorf("Error reporting BOSH Director `%s` as down: %v", directorConfig.Name, err)
+			}
+			continue
+		}
+
+		boshClients[directorConfig.Name] = setups[i].boshClient
+		deploymentsFetchers = append(deploymentsFetchers, setups[i].deploymentsFetcher)
+		for name, lastSource := range setups[i].lastSources {
+			lastSources[name] = lastSource
+		}
+	}
+
+	return deploymentsFetchers
+}
+
+// directorSetup holds the client and the deployments sources of a director of
+// the directors file once set up.
+type directorSetup struct {
+	boshClient         director.Director
+	deploymentsFetcher *deployments.Fetcher
+	lastSources        map[string]*deployments.LastSource
+}
+
+// setupDirector builds the client, fetcher and collectors of a director of the
+// directors file, registered with a director label. It is safe to call
+// concurrently for different directors.
+func setupDirector(background context.Context, registerer prometheus.Registerer, directorConfig directors.Config, collectorFilters collectorFilters) (directorSetup, error) {
+	directorRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"director": directorConfig.Name}, registerer)
+
+	boshClient, boshInfo, err := buildBOSHDirector(directorConfig, directorRegisterer)
+	if err != nil {
+		return directorSetup{}, err
+	}
+
+	directorFetcher, requestDuration, err := buildDirectorDeploymentsFetcher(directorRegisterer, boshClient, boshInfo)
+	if err != nil {
+		return directorSetup{}, err
+	}
+
+	lastSources := map[string]*deployments.LastSource{}
+	_, err = registerCollectors(background, directorRegisterer, boshClient, boshInfo, lastDeploymentsSource(lastSources, directorConfig.Name, directorFetcher), directorSDFilename(*sdFilename, directorConfig.Name), collectorFilters, buildVMTypesFetcher(boshClient), directorFetcher, requestDuration)
+	if err != nil {
+		return directorSetup{}, err
+	}
+
+	return directorSetup{
+		boshClient:         boshClient,
+		deploymentsFetcher: directorFetcher,
+		lastSources:        lastSources,
+	}, nil
+}
+
+// newDirectorRequestDurationMetric returns the histogram of director call
+// latencies shared by the deployments and tasks fetchers. Its buckets go from
+// 50ms to a minute, as InstanceInfos of large deployments takes tens of seconds.
+func newDirectorRequestDurationMetric(boshInfo director.Info) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: *metricsNamespace,
+			Subsystem: "director",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests to the BOSH Director API by endpoint.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+			ConstLabels: prometheus.Labels{
+				"environment": *metricsEnvironment,
+				"bosh_name":   boshInfo.Name,
+				"bosh_uuid":   boshInfo.UUID,
+			},
+		},
+		[]string{"endpoint"},
+	)
+}
+
+func newDirectorRequestErrorsMetric(boshInfo director.Info) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: *metricsNamespace,
+			Subsystem: "director",
+			Name:      "request_errors_total",
+			Help:      "Total number of failed requests to the BOSH Director API by endpoint and error category.",
+			ConstLabels: prometheus.Labels{
+				"environment": *metricsEnvironment,
+				"bosh_name":   boshInfo.Name,
+				"bosh_uuid":   boshInfo.UUID,
+			},
+		},
+		[]string{"endpoint", "category"},
+	)
+}
+
+// newDeploymentsMetric returns a gauge of the number of deployments in the
+// last scrape.
+func newDeploymentsMetric(boshInfo director.Info, name string, help string) prometheus.Gauge {
+	return prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: *metricsNamespace,
+			Subsystem: "deployments",
+			Name:      name,
+			Help:      help,
+			ConstLabels: prometheus.Labels{
+				"environment": *metricsEnvironment,
+				"bosh_name":   boshInfo.Name,
+				"bosh_uuid":   boshInfo.UUID,
+			},
+		},
+	)
+}
+
+// newDeploymentErrorsMetric returns a counter of the failures of a single
+// deployment.
+func newDeploymentErrorsMetric(boshInfo director.Info, name string, help string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: *metricsNamespace,
+			Subsystem: "deployment",
+			Name:      name,
+			Help:      help,
+			ConstLabels: prometheus.Labels{
+				"environment": *metricsEnvironment,
+				"bosh_name":   boshInfo.Name,
+				"bosh_uuid":   boshInfo.UUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+}
+
+func buildBOSHDeploymentsFetcher(boshClient director.Director, requestDuration prometheus.ObserverVec, requestErrors *prometheus.CounterVec, deploymentsScraped prometheus.Gauge, deploymentsFiltered prometheus.Gauge, deploymentFetchErrors *prometheus.CounterVec, instancesTimeouts *prometheus.CounterVec) (*deployments.Fetcher, error) {
 	var deploymentsFilters []string
 	if *filterDeployments != "" {
 		deploymentsFilters = strings.Split(*filterDeployments, ",")
 	}
-	deploymentsFilter := filters.NewDeploymentsFilter(deploymentsFilters, boshClient)
-	deploymentsFetcher := deployments.NewFetcher(*deploymentsFilter)
 
+	var excludedDeploymentsFilters []string
+	if *boshDeploymentsExclude != "" {
+		excludedDeploymentsFilters = strings.Split(*boshDeploymentsExclude, ",")
+	}
+	deploymentsFilter, err := filters.NewDeploymentsFilter(deploymentsFilters, excludedDeploymentsFilters, boshClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var instanceGroupsFilters []string
+	if *boshInstanceGroups != "" {
+		instanceGroupsFilters = strings.Split(*boshInstanceGroups, ",")
+	}
+
+	var excludedInstanceGroupsFilters []string
+	if *boshInstanceGroupsExclude != "" {
+		excludedInstanceGroupsFilters = strings.Split(*boshInstanceGroupsExclude, ",")
+	}
+	instanceGroupsFilter, err := filters.NewInstanceGroupsFilter(instanceGroupsFilters, excludedInstanceGroupsFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter.azs only hides the instances of other AZs from the Jobs metrics,
+	// unless filter.azs-fetch drops them from the deployments altogether.
 	var azsFilters []string
-	if *filterAZs != "" {
+	if *filterAZsFetch && *filterAZs != "" {
 		azsFilters = strings.Split(*filterAZs, ",")
 	}
 	azsFilter := filters.NewAZsFilter(azsFilters)
 
+	excludeProcessesFilter, err := filters.NewRegexpFilter(splitFlag(*boshExcludeProcesses))
+	if err != nil {
+		return nil, fmt.Errorf("Error processing Exclude Processes Regexps: %v", err)
+	}
+
+	deploymentsFetcher := deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+		DeploymentsFilter:      *deploymentsFilter,
+		InstanceGroupsFilter:   *instanceGroupsFilter,
+		AZsFilter:              *azsFilter,
+		MaxInFlight:            *boshMaxInFlight,
+		ContinueOnError:        *boshContinueOnError,
+		MetadataCacheTTL:       *boshMetadataCacheTTL,
+		FetchTimeout:           *boshFetchTimeout,
+		RetryAttempts:          *boshRetryAttempts,
+		RetryBackoff:           *boshRetryBackoff,
+		IncludeNoVMInstances:   *boshIncludeNoVMInstances,
+		NoVMAsUnhealthy:        *boshNoVMAsUnhealthy,
+		RequestsPerSecond:      *boshRequestsPerSecond,
+		InstancesThreshold:     *boshInstancesWarningThreshold,
+		InstancesTimeout:       *boshInstancesTimeout,
+		MaxInstances:           *boshMaxInstances,
+		ExcludeProcessesFilter: excludeProcessesFilter,
+		CountExcludedProcesses: *boshCountExcludedProcesses,
+		TagKeys:                deploymentTagKeys(),
+		RequestDuration:        requestDuration,
+		RequestErrors:          requestErrors,
+		DeploymentsScraped:     deploymentsScraped,
+		DeploymentsFiltered:    deploymentsFiltered,
+		DeploymentFetchErrors:  deploymentFetchErrors,
+		InstancesTimeouts:      instancesTimeouts,
+	})
+
+	return deploymentsFetcher, nil
+}
+
+// collectorFilters holds the filters shared by the collectors of every BOSH
+// Director.
+type collectorFilters struct {
+	azsFilter                 *filters.AZsFilter
+	collectorsFilter          *filters.CollectorsFilter
+	cidrsFilter               *filters.CidrFilter
+	processesFilter           *filters.RegexpFilter
+	sdLabelsFilter            *filters.LabelsFilter
+	deprecatedStemcellsFilter *filters.DeprecatedFilter
+	deprecatedReleasesFilter  *filters.DeprecatedFilter
+	metricsFilter             *filters.MetricsFilter
+}
+
+func buildCollectorFilters() (collectorFilters, error) {
+	var err error
+	var collectorFilters collectorFilters
+
+	var azsFilters []string
+	if *filterAZs != "" {
+		azsFilters = strings.Split(*filterAZs, ",")
+	}
+	collectorFilters.azsFilter = filters.NewAZsFilter(azsFilters)
+
 	var collectorsFilters []string
 	if *filterCollectors != "" {
 		collectorsFilters = strings.Split(*filterCollectors, ",")
 	}
-	collectorsFilter, err := filters.NewCollectorsFilter(collectorsFilters)
+	collectorFilters.collectorsFilter, err = filters.NewCollectorsFilter(collectorsFilters)
 	if err != nil {
-		log.Error(err)
-		os.Exit(1)
+		return collectorFilters, err
 	}
 
 	var cidrFilters []string
 	if *filterCIDRs != "" {
 		cidrFilters = strings.Split(*filterCIDRs, ",")
 	}
-	cidrsFilter, err := filters.NewCidrFilter(cidrFilters)
+	collectorFilters.cidrsFilter, err = filters.NewCidrFilter(cidrFilters)
 	if err != nil {
-		log.Error(err)
-		os.Exit(1)
+		return collectorFilters, err
 	}
 
 	var processesFilters []string
 	if *sdProcessesRegexp != "" {
 		processesFilters = []string{*sdProcessesRegexp}
 	}
-	processesFilter, err := filters.NewRegexpFilter(processesFilters)
+	collectorFilters.processesFilter, err = filters.NewRegexpFilter(processesFilters)
+	if err != nil {
+		return collectorFilters, fmt.Errorf("Error processing Processes Regexp: %v", err)
+	}
+
+	var sdLabelsFilters []string
+	if *sdInstanceLabels != "" {
+		sdLabelsFilters = strings.Split(*sdInstanceLabels, ",")
+	}
+	collectorFilters.sdLabelsFilter, err = filters.NewLabelsFilter(sdLabelsFilters)
+	if err != nil {
+		return collectorFilters, fmt.Errorf("Error processing Service Discovery Instance Labels: %v", err)
+	}
+
+	var deprecatedStemcellsFilters []string
+	if *boshDeprecatedStemcells != "" {
+		deprecatedStemcellsFilters = strings.Split(*boshDeprecatedStemcells, ",")
+	}
+	collectorFilters.deprecatedStemcellsFilter, err = filters.NewDeprecatedFilter(deprecatedStemcellsFilters)
+	if err != nil {
+		return collectorFilters, fmt.Errorf("Error processing Deprecated Stemcells: %v", err)
+	}
+
+	var deprecatedReleasesFilters []string
+	if *boshDeprecatedReleases != "" {
+		deprecatedReleasesFilters = strings.Split(*boshDeprecatedReleases, ",")
+	}
+	collectorFilters.deprecatedReleasesFilter, err = filters.NewDeprecatedFilter(deprecatedReleasesFilters)
+	if err != nil {
+		return collectorFilters, fmt.Errorf("Error processing Deprecated Releases: %v", err)
+	}
+
+	var metricsIncludeFilters []string
+	if *boshMetricsInclude != "" {
+		metricsIncludeFilters = strings.Split(*boshMetricsInclude, ",")
+	}
+	var metricsExcludeFilters []string
+	if *boshMetricsExclude != "" {
+		metricsExcludeFilters = strings.Split(*boshMetricsExclude, ",")
+	}
+	collectorFilters.metricsFilter, err = filters.NewMetricsFilter(metricsIncludeFilters, metricsExcludeFilters)
+	if err != nil {
+		return collectorFilters, fmt.Errorf("Error processing Metrics filters: %v", err)
+	}
+
+	return collectorFilters, nil
+}
+
+// buildDirectorDeploymentsFetcher registers the director request metrics of
+// a BOSH Director and returns the deployments fetcher observing them.
+func buildDirectorDeploymentsFetcher(registerer prometheus.Registerer, boshClient director.Director, boshInfo director.Info) (*deployments.Fetcher, *prometheus.HistogramVec, error) {
+	requestDuration := newDirectorRequestDurationMetric(boshInfo)
+	if err := registerer.Register(requestDuration); err != nil {
+		return nil, nil, err
+	}
+
+	requestErrors := newDirectorRequestErrorsMetric(boshInfo)
+	if err := registerer.Register(requestErrors); err != nil {
+		return nil, nil, err
+	}
+
+	deploymentsScraped := newDeploymentsMetric(boshInfo, "scraped_total", "Number of BOSH Deployments matched by the deployments filter in the last scrape.")
+	if err := registerer.Register(deploymentsScraped); err != nil {
+		return nil, nil, err
+	}
+
+	deploymentsFiltered := newDeploymentsMetric(boshInfo, "filtered_total", "Number of BOSH Deployments excluded by the deployments filter in the last scrape.")
+	if err := registerer.Register(deploymentsFiltered); err != nil {
+		return nil, nil, err
+	}
+
+	deploymentFetchErrors := newDeploymentErrorsMetric(boshInfo, "fetch_errors_total", "Total number of times an error occured fetching this deployment from BOSH.")
+	if err := registerer.Register(deploymentFetchErrors); err != nil {
+		return nil, nil, err
+	}
+
+	instancesTimeouts := newDeploymentErrorsMetric(boshInfo, "instances_timeouts_total", "Total number of times reading the instances of this deployment from BOSH timed out.")
+	if err := registerer.Register(instancesTimeouts); err != nil {
+		return nil, nil, err
+	}
+
+	deploymentsFetcher, err := buildBOSHDeploymentsFetcher(boshClient, requestDuration, requestErrors, deploymentsScraped, deploymentsFiltered, deploymentFetchErrors, instancesTimeouts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deploymentsFetcher, requestDuration, nil
+}
+
+func buildVMTypesFetcher(boshClient director.Director) *vmtypes.Fetcher {
+	if !*boshVMTypeMetrics {
+		return nil
+	}
+
+	return vmtypes.NewFetcher(boshClient)
+}
+
+// directorSDFilename returns the Service Discovery file of a director listed
+// in --bosh.directors-file, e.g. bosh_target_groups_name.json.
+func directorSDFilename(sdFilename string, directorName string) string {
+	ext := filepath.Ext(sdFilename)
+	return strings.TrimSuffix(sdFilename, ext) + "_" + directorName + ext
+}
+
+// lastDeploymentsSource wraps deploymentsSource to remember its last scraped
+// deployments for the /deployments endpoint, when enabled.
+func lastDeploymentsSource(lastSources map[string]*deployments.LastSource, directorName string, deploymentsSource deployments.DeploymentsSource) deployments.DeploymentsSource {
+	if !*deploymentsEndpoint {
+		return deploymentsSource
+	}
+
+	lastSource := deployments.NewLastSource(deploymentsSource)
+	lastSources[directorName] = lastSource
+
+	return lastSource
+}
+
+func dumpDeployments(deploymentsFetcher deployments.DeploymentsSource) {
+	deploymentsInfo, err := deploymentsFetcher.Deployments()
 	if err != nil {
-		log.Errorf("Error processing Processes Regexp: %v", err)
+		log.Errorf("Error reading deployments: %v", err)
 		os.Exit(1)
 	}
 
-	boshCollector := collectors.NewBoshCollector(
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(deploymentsInfo); err != nil {
+		log.Errorf("Error encoding deployments: %v", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// registerCollectors registers the collectors of a single source of
+// deployments. boshClient is nil when the deployments are read from a file,
+// in which case none of the collectors calling the BOSH Director are allowed.
+// The collectors fetching in the background stop once ctx is done. It returns
+// the circuit breaker the BOSH Director calls go through, if any.
+func registerCollectors(ctx context.Context, registerer prometheus.Registerer, boshClient director.Director, boshInfo director.Info, deploymentsFetcher deployments.DeploymentsSource, sdFilename string, collectorFilters collectorFilters, vmTypesFetcher *vmtypes.Fetcher, releasesFetcher *deployments.Fetcher, requestDuration prometheus.ObserverVec) (*deployments.CircuitBreaker, error) {
+	var circuitBreaker *deployments.CircuitBreaker
+	if boshClient != nil && *boshCircuitBreakerThreshold > 0 {
+		circuitBreaker = deployments.NewCircuitBreaker(deploymentsFetcher, *boshCircuitBreakerThreshold, *boshCircuitBreakerCooldown)
+		deploymentsFetcher = circuitBreaker
+	}
+
+	var boshCollector prometheus.Collector = collectors.NewBoshCollector(
 		*metricsNamespace,
 		*metricsEnvironment,
 		boshInfo.Name,
 		boshInfo.UUID,
-		*sdFilename,
+		sdFilename,
+		*sdGroupBy,
+		collectorFilters.sdLabelsFilter,
 		deploymentsFetcher,
-		collectorsFilter,
-		azsFilter,
-		processesFilter,
-		cidrsFilter,
+		collectorFilters.collectorsFilter,
+		collectorFilters.azsFilter,
+		collectorFilters.processesFilter,
+		collectorFilters.cidrsFilter,
+		*boshPreferIPFamily,
+		*boshOnlyUnhealthy,
+		*boshInstanceInfoMetrics,
+		*boshInstanceGroupMetrics,
+		*boshVitalsVMTypeLabel,
+		collectorFilters.deprecatedStemcellsFilter,
+		collectorFilters.deprecatedReleasesFilter,
+		deploymentTagKeys(),
+		collectorFilters.metricsFilter,
+		vmTypesFetcher,
 	)
-	prometheus.MustRegister(boshCollector)
 
-	http.Handle(*metricsPath, prometheusHandler())
+	if circuitBreaker != nil {
+		boshCollector = collectors.NewCircuitBreakerCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			boshCollector,
+			circuitBreaker,
+		)
+	}
+
+	if *boshScrapeInterval > 0 {
+		cachedCollector := collectors.NewCachedCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			boshCollector,
+			*boshScrapeInterval,
+		)
+		if err := registerer.Register(cachedCollector); err != nil {
+			return nil, err
+		}
+		go cachedCollector.Run(ctx)
+	} else if err := registerer.Register(boshCollector); err != nil {
+		return nil, err
+	}
+
+	if boshClient == nil {
+		for _, directorFlag := range []struct {
+			name    string
+			enabled bool
+		}{
+			{"--bosh.tasks-limit", *boshTasksLimit > 0},
+			{"--bosh.events-lookback", *boshEventsLookback > 0},
+			{"--bosh.config-metrics", *boshConfigMetrics},
+			{"--bosh.release-metrics", *boshReleaseMetrics},
+			{"--bosh.resurrection-metrics", *boshResurrectionMetrics},
+			{"--bosh.circuit-breaker-threshold", *boshCircuitBreakerThreshold > 0},
+			{"--bosh.orphaned-disk-metrics", *boshOrphanedDiskMetrics},
+			{"--bosh.errand-runs-limit", *boshErrandRunsLimit > 0},
+		} {
+			if directorFlag.enabled {
+				return nil, fmt.Errorf("Flag %s cannot be used with --bosh.deployments-file", directorFlag.name)
+			}
+		}
+
+		return nil, nil
+	}
+
+	if err := registerer.Register(collectors.NewDirectorCollector(*metricsNamespace, *metricsEnvironment, boshInfo)); err != nil {
+		return nil, err
+	}
+
+	// The VM types are only fetched by the BOSH collector once it read the
+	// deployments, so they are already skipped with them.
+	var directorCollectors []prometheus.Collector
+
+	if *boshTasksLimit > 0 {
+		directorCollectors = append(directorCollectors, collectors.NewTasksCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			tasks.NewFetcher(boshClient, *boshTasksLimit, requestDuration),
+		))
+	}
+
+	if *boshEventsLookback > 0 {
+		directorCollectors = append(directorCollectors, collectors.NewEventsCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			events.NewFetcher(boshClient, *boshEventsLookback),
+		))
+	}
+
+	if *boshConfigMetrics {
+		directorCollectors = append(directorCollectors, collectors.NewConfigsCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			configs.NewFetcher(boshClient),
+		))
+	}
+
+	if *boshReleaseMetrics {
+		directorCollectors = append(directorCollectors, collectors.NewReleasesCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			releasesFetcher,
+		))
+	}
+
+	if *boshResurrectionMetrics {
+		directorCollectors = append(directorCollectors, collectors.NewResurrectionCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			resurrection.NewFetcher(boshClient, *boshResurrectionCacheTTL),
+		))
+	}
+
+	if *boshOrphanedDiskMetrics {
+		directorCollectors = append(directorCollectors, collectors.NewOrphanedDisksCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			disks.NewFetcher(boshClient),
+		))
+	}
+
+	if *boshErrandRunsLimit > 0 {
+		directorCollectors = append(directorCollectors, collectors.NewErrandsCollector(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshInfo.Name,
+			boshInfo.UUID,
+			errands.NewFetcher(boshClient, *boshErrandRunsLimit),
+		))
+	}
+
+	for _, directorCollector := range directorCollectors {
+		if circuitBreaker != nil {
+			directorCollector = collectors.NewCircuitBreakerGateCollector(directorCollector, circuitBreaker)
+		}
+		if err := registerer.Register(directorCollector); err != nil {
+			return nil, err
+		}
+	}
+
+	return circuitBreaker, nil
+}
+
+func deploymentTagKeys() []string {
+	var tagKeys []string
+	for _, tagKey := range strings.Split(*boshDeploymentTags, ",") {
+		if tagKey = strings.TrimSpace(tagKey); tagKey != "" {
+			tagKeys = append(tagKeys, tagKey)
+		}
+	}
+
+	return tagKeys
+}
+
+// webLogger forwards the key/value pairs logged by the exporter-toolkit web
+// server to the exporter logger.
+type webLogger struct{}
+
+func (webLogger) Log(keyvals ...interface{}) error {
+	logger := log.Base()
+
+	var msg, level string
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, value := fmt.Sprint(keyvals[i]), fmt.Sprint(keyvals[i+1])
+		switch key {
+		case "msg":
+			msg = value
+		case "level":
+			level = value
+		default:
+			logger = logger.With(key, value)
+		}
+	}
+
+	switch level {
+	case "debug":
+		logger.Debug(msg)
+	case "warn":
+		logger.Warn(msg)
+	case "error":
+		logger.Error(msg)
+	default:
+		logger.Info(msg)
+	}
+
+	return nil
+}
+
+// serve runs server on listener, as configured by --web.config.file, or
+// over TLS when --web.tls.cert_file and --web.tls.key_file are set. The web
+// configuration file is read again for every new connection, so certificates
+// and users can be changed without restarting the exporter.
+func serve(listener net.Listener, server *http.Server) error {
+	switch {
+	case *webConfigFile != "":
+		log.Infof("Listening on %s with web config file `%s`", listener.Addr(), *webConfigFile)
+		return web.Serve(listener, server, *webConfigFile, webLogger{})
+	case *tlsCertFile != "" && *tlsKeyFile != "":
+		log.Infoln("Listening TLS on", listener.Addr())
+		return server.ServeTLS(listener, *tlsCertFile, *tlsKeyFile)
+	default:
+		log.Infoln("Listening on", listener.Addr())
+		return server.Serve(listener)
+	}
+}
+
+// inFlightScrapes counts the scrapes being served, to report how many were
+// drained on shutdown.
+type inFlightScrapes struct {
+	count int64
+}
+
+func (s *inFlightScrapes) handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.count, 1)
+		defer atomic.AddInt64(&s.count, -1)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (s *inFlightScrapes) inFlight() int64 {
+	return atomic.LoadInt64(&s.count)
+}
+
+// shutdown stops accepting new requests, cancels the deployments fetches in
+// progress so that the in-flight scrapes return early, stops the background
+// fetches, and waits up to timeout for the scrapes to finish.
+func shutdown(server *http.Server, fetchers []*deployments.Fetcher, stopBackground context.CancelFunc, scrapes *inFlightScrapes, timeout time.Duration) error {
+	inFlight := scrapes.inFlight()
+	log.Infof("Shutting down, draining %d in-flight scrapes", inFlight)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(ctx)
+	}()
+
+	stopFetches(fetchers, stopBackground)
+
+	if err := <-shutdownErr; err != nil {
+		return fmt.Errorf("Timed out after %s with %d scrapes still in flight: %w", timeout, scrapes.inFlight(), err)
+	}
+	log.Infof("Drained %d scrapes", inFlight)
+
+	return nil
+}
+
+// shutdownTextfile stops the background fetches and the deployments fetches in
+// progress, and waits up to timeout for the textfile writer to return once its
+// write in progress is done.
+func shutdownTextfile(writerDone <-chan struct{}, fetchers []*deployments.Fetcher, stopBackground context.CancelFunc, timeout time.Duration) error {
+	log.Info("Shutting down, finishing the textfile write in progress")
+
+	stopFetches(fetchers, stopBackground)
+
+	select {
+	case <-writerDone:
+	case <-time.After(timeout):
+		return fmt.Errorf("Timed out after %s with the textfile write still in progress", timeout)
+	}
+
+	return nil
+}
+
+// stopFetches cancels the background fetches and the deployments fetches in
+// progress.
+func stopFetches(fetchers []*deployments.Fetcher, stopBackground context.CancelFunc) {
+	stopBackground()
+	for _, fetcher := range fetchers {
+		fetcher.Stop()
+	}
+}
+
+// waitForSignal blocks until a SIGTERM or SIGINT signal is received.
+func waitForSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	log.Infof("Received %s signal", <-signals)
+}
+
+func setupLogger(level string, format string) error {
+	if err := log.Base().SetLevel(level); err != nil {
+		return err
+	}
+
+	switch format {
+	case "logfmt":
+		format = "logger:stderr"
+	case "json":
+		format = "logger:stderr?json=true"
+	}
+
+	return log.Base().SetFormat(format)
+}
+
+type validationCheck struct {
+	name  string
+	check func() error
+}
+
+func splitFlag(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// validateConfig runs every check, even after a failure, so all problems are
+// reported at once. It returns false if any check failed.
+func validateConfig() bool {
+	checks := []validationCheck{
+		{"Deployments filter", func() error {
+			_, err := filters.NewDeploymentsFilter(splitFlag(*filterDeployments), splitFlag(*boshDeploymentsExclude), nil)
+			return err
+		}},
+		{"Collectors filter", func() error {
+			_, err := filters.NewCollectorsFilter(splitFlag(*filterCollectors))
+			return err
+		}},
+		{"CIDRs filter", func() error {
+			_, err := filters.NewCidrFilter(splitFlag(*filterCIDRs))
+			return err
+		}},
+		{"Service Discovery processes regexp", func() error {
+			var processesFilters []string
+			if *sdProcessesRegexp != "" {
+				processesFilters = []string{*sdProcessesRegexp}
+			}
+			_, err := filters.NewRegexpFilter(processesFilters)
+			return err
+		}},
+		{"Excluded processes regexps", func() error {
+			_, err := filters.NewRegexpFilter(splitFlag(*boshExcludeProcesses))
+			return err
+		}},
+		{"Service Discovery instance labels", func() error {
+			_, err := filters.NewLabelsFilter(splitFlag(*sdInstanceLabels))
+			return err
+		}},
+		{"Deprecated stemcells", func() error {
+			_, err := filters.NewDeprecatedFilter(splitFlag(*boshDeprecatedStemcells))
+			return err
+		}},
+		{"Deprecated releases", func() error {
+			_, err := filters.NewDeprecatedFilter(splitFlag(*boshDeprecatedReleases))
+			return err
+		}},
+		{"Metrics filters", func() error {
+			_, err := filters.NewMetricsFilter(splitFlag(*boshMetricsInclude), splitFlag(*boshMetricsExclude))
+			return err
+		}},
+		{"TLS certificate", validateTLSCert},
+		{"Web config file", validateWebConfig},
+	}
+
+	switch {
+	case *boshDeploymentsFile != "":
+		checks = append(checks, validationCheck{"Deployments file", func() error {
+			_, err := deployments.NewFileFetcher(*boshDeploymentsFile).Deployments()
+			return err
+		}})
+	case *boshDirectorsFile != "":
+		directorConfigs, err := readDirectorsFile(*boshDirectorsFile)
+		checks = append(checks, validationCheck{"Directors file", func() error {
+			return err
+		}})
+		for _, directorConfig := range directorConfigs {
+			directorConfig := directorConfig
+			checks = append(checks,
+				validationCheck{fmt.Sprintf("BOSH CA certificate of `%s`", directorConfig.Name), func() error {
+					return validateBOSHCACert(directorConfig)
+				}},
+				validationCheck{fmt.Sprintf("BOSH Director `%s`", directorConfig.Name), func() error {
+					return validateBOSHDirector(directorConfig)
+				}},
+			)
+		}
+	default:
+		directorConfig := flagsDirectorConfig()
+		checks = append(checks,
+			validationCheck{"BOSH CA certificate", func() error {
+				return validateBOSHCACert(directorConfig)
+			}},
+			validationCheck{"BOSH Director", func() error {
+				return validateBOSHDirector(directorConfig)
+			}},
+		)
+	}
+
+	valid := true
+	for _, check := range checks {
+		if err := check.check(); err != nil {
+			fmt.Printf("[FAIL] %s: %v\n", check.name, err)
+			valid = false
+			continue
+		}
+		fmt.Printf("[OK]   %s\n", check.name)
+	}
+
+	return valid
+}
+
+func validateTLSCert() error {
+	if *tlsCertFile == "" && *tlsKeyFile == "" {
+		return nil
+	}
+
+	if *tlsCertFile == "" || *tlsKeyFile == "" {
+		return errors.New("Flags --web.tls.cert_file and --web.tls.key_file must be set together")
+	}
+
+	_, err := tls.LoadX509KeyPair(*tlsCertFile, *tlsKeyFile)
+	return err
+}
+
+func validateWebConfig() error {
+	if *webConfigFile == "" {
+		return nil
+	}
+
+	if *tlsCertFile != "" || *tlsKeyFile != "" {
+		return errors.New("Flag --web.config.file cannot be used with --web.tls.cert_file or --web.tls.key_file")
+	}
+
+	return web.Validate(*webConfigFile)
+}
+
+func validateBOSHCACert(config directors.Config) error {
+	if config.CACertFile == "" {
+		return errors.New("Flag --bosh.ca-cert-file is required unless --bosh.deployments-file or --bosh.directors-file is set")
+	}
+
+	logLevel, err := logger.Levelify(*boshLogLevel)
+	if err != nil {
+		return err
+	}
+
+	boshCACert, err := certs.ReadCACert(config.CACertFile, logger.NewLogger(logLevel))
+	if err != nil {
+		return err
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM([]byte(boshCACert)) {
+		return fmt.Errorf("No PEM certificates found in `%s`", config.CACertFile)
+	}
+
+	return nil
+}
+
+func validateBOSHDirector(config directors.Config) error {
+	_, boshInfo, err := buildBOSHDirector(config, prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+
+	if boshInfo.User == "" {
+		return errors.New("Not authenticated to the BOSH Director")
+	}
+
+	return nil
+}
+
+func main() {
+	kingpin.Version(version.Print("fbosh_exporter"))
+	kingpin.HelpFlag.Short('h')
+	kingpin.Parse()
+
+	if err := setupLogger(*logLevel, *logFormat); err != nil {
+		log.Errorf("Error setting up logger: %v", err)
+		os.Exit(1)
+	}
+
+	if err := loadSecretFiles(); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	if err := setupProxy(*boshProxy); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	if *boshSSHTunnelHost != "" && *boshProxy != "" {
+		log.Error("Flag --bosh.ssh-tunnel.host cannot be used with --bosh.proxy")
+		os.Exit(1)
+	}
+
+	if err := setupSSHTunnel(*boshSSHTunnelHost, *boshSSHTunnelUser, *boshSSHTunnelPrivateKeyFile, *boshSSHTunnelHostKey); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	if *validate {
+		if !validateConfig() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	log.Infoln("Starting bosh_exporter", version.Info())
+	log.Infoln("Build context", version.BuildContext())
+
+	// node_exporter already exposes its own go_* and process_* metrics, so the
+	// textfile only contains the build info and the collectors registered below.
+	var registerer prometheus.Registerer = prometheus.DefaultRegisterer
+	var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+	if *textfileDirectory != "" {
+		if *textfileInterval <= 0 {
+			log.Error("Flag --textfile.interval must be greater than 0")
+			os.Exit(1)
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(version.NewCollector("bosh_exporter"))
+		registerer = registry
+		gatherer = registry
+	}
+
+	collectorFilters, err := buildCollectorFilters()
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	// background is cancelled on shutdown, stopping the collectors that fetch
+	// from BOSH in the background.
+	background, stopBackground := context.WithCancel(context.Background())
+
+	var boshName, boshUUID string
+	var boshDeploymentsFetcher *deployments.Fetcher
+	var boshCircuitBreaker *deployments.CircuitBreaker
+	var vmTypesFetcher *vmtypes.Fetcher
+	var deploymentsFetchers []*deployments.Fetcher
+	boshClients := map[string]director.Director{}
+	lastSources := map[string]*deployments.LastSource{}
+	switch {
+	case *boshDirectorsFile != "":
+		if *boshDeploymentsFile != "" {
+			log.Error("Flag --bosh.directors-file cannot be used with --bosh.deployments-file")
+			os.Exit(1)
+		}
+		if *dumpJSON {
+			log.Error("Flag --dump-json cannot be used with --bosh.directors-file")
+			os.Exit(1)
+		}
+
+		directorConfigs, err := readDirectorsFile(*boshDirectorsFile)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+
+		deploymentsFetchers = setupDirectors(background, registerer, directorConfigs, collectorFilters, boshClients, lastSources)
+		if len(boshClients) == 0 {
+			log.Error("None of the BOSH Directors could be set up")
+			os.Exit(1)
+		}
+	case *boshDeploymentsFile != "":
+		log.Infof("Using deployments file `%s`", *boshDeploymentsFile)
+		deploymentsFetcher := deployments.NewFileFetcher(*boshDeploymentsFile)
+		if *dumpJSON {
+			dumpDeployments(deploymentsFetcher)
+		}
+
+		if *boshVMTypeMetrics {
+			log.Error("Flag --bosh.vm-type-metrics cannot be used with --bosh.deployments-file")
+			os.Exit(1)
+		}
+
+		if _, err := registerCollectors(background, registerer, nil, director.Info{}, lastDeploymentsSource(lastSources, "", deploymentsFetcher), *sdFilename, collectorFilters, nil, nil, nil); err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+	default:
+		boshClient, boshInfo, err := buildBOSHDirector(flagsDirectorConfig(), registerer)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+
+		var requestDuration *prometheus.HistogramVec
+		boshDeploymentsFetcher, requestDuration, err = buildDirectorDeploymentsFetcher(registerer, boshClient, boshInfo)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		if *dumpJSON {
+			dumpDeployments(boshDeploymentsFetcher)
+		}
+
+		vmTypesFetcher = buildVMTypesFetcher(boshClient)
+		boshCircuitBreaker, err = registerCollectors(background, registerer, boshClient, boshInfo, lastDeploymentsSource(lastSources, "", boshDeploymentsFetcher), *sdFilename, collectorFilters, vmTypesFetcher, boshDeploymentsFetcher, requestDuration)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		boshClients[""] = boshClient
+		deploymentsFetchers = append(deploymentsFetchers, boshDeploymentsFetcher)
+		boshName = boshInfo.Name
+		boshUUID = boshInfo.UUID
+	}
+
+	if *textfileDirectory != "" {
+		writer := textfile.NewWriter(
+			*metricsNamespace,
+			*metricsEnvironment,
+			boshName,
+			boshUUID,
+			*textfileDirectory,
+			gatherer,
+			*textfileInterval,
+		)
+
+		writerDone := make(chan struct{})
+		go func() {
+			defer close(writerDone)
+			writer.Run(background)
+		}()
+
+		// The textfile replaces the HTTP server, so there are no scrapes to
+		// drain, only the textfile write in progress.
+		waitForSignal()
+		if err := shutdownTextfile(writerDone, deploymentsFetchers, stopBackground, *webShutdownTimeout); err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	scrapes := &inFlightScrapes{}
+	http.Handle(*metricsPath, scrapes.handler(prometheusHandler(registerer, gatherer)))
+	http.Handle("/ready", readiness.NewDirectorsHandler(boshClients, *readyCacheTTL))
+	if *deploymentsEndpoint {
+		http.Handle("/deployments", authHandler(inventory.NewHandler(lastSources)))
+	}
+
+	// Probes only cover a single deployment, so they must not overwrite the
+	// service discovery file written from all deployments.
+	var probeCollectorsFilters []string
+	for _, collectorName := range []string{filters.DeploymentsCollector, filters.JobsCollector} {
+		if collectorFilters.collectorsFilter.Enabled(collectorName) {
+			probeCollectorsFilters = append(probeCollectorsFilters, collectorName)
+		}
+	}
+	if boshDeploymentsFetcher != nil && len(probeCollectorsFilters) > 0 {
+		probeCollectorsFilter, err := filters.NewCollectorsFilter(probeCollectorsFilters)
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+
+		http.Handle("/probe", scrapes.handler(authHandler(probe.NewHandler(boshDeploymentsFetcher, boshCircuitBreaker, func(deploymentsSource deployments.DeploymentsSource) prometheus.Collector {
+			return collectors.NewBoshCollector(
+				*metricsNamespace,
+				*metricsEnvironment,
+				boshName,
+				boshUUID,
+				*sdFilename,
+				*sdGroupBy,
+				collectorFilters.sdLabelsFilter,
+				deploymentsSource,
+				probeCollectorsFilter,
+				collectorFilters.azsFilter,
+				collectorFilters.processesFilter,
+				collectorFilters.cidrsFilter,
+				*boshPreferIPFamily,
+				*boshOnlyUnhealthy,
+				*boshInstanceInfoMetrics,
+				*boshInstanceGroupMetrics,
+				*boshVitalsVMTypeLabel,
+				collectorFilters.deprecatedStemcellsFilter,
+				collectorFilters.deprecatedReleasesFilter,
+				deploymentTagKeys(),
+				collectorFilters.metricsFilter,
+				vmTypesFetcher,
+			)
+		}))))
+	}
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
              <head><title>BOSH Exporter</title></head>
              <body>
              <h1>BOSH Exporter</h1>
              <p><a href='` + *metricsPath + `'>Metrics</a></p>
+             <p><a href='/ready'>Ready</a></p>
              </body>
              </html>`))
 	})
 
-	if *tlsCertFile != "" && *tlsKeyFile != "" {
-		log.Infoln("Listening TLS on", *listenAddress)
-		log.Fatal(http.ListenAndServeTLS(*listenAddress, *tlsCertFile, *tlsKeyFile, nil))
-	} else {
-		log.Infoln("Listening on", *listenAddress)
-		log.Fatal(http.ListenAndServe(*listenAddress, nil))
+	if err := validateWebConfig(); err != nil {
+		log.Errorf("Error loading web config file: %v", err)
+		os.Exit(1)
+	}
+
+	listener, err := net.Listen("tcp", *listenAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{Handler: http.DefaultServeMux}
+	go func() {
+		if err := serve(listener, server); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	waitForSignal()
+	if err := shutdown(server, deploymentsFetchers, stopBackground, scrapes, *webShutdownTimeout); err != nil {
+		log.Error(err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,1054 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/directors"
+)
+
+var _ = Describe("prometheusHandler", func() {
+	var (
+		accept         string
+		acceptEncoding string
+		recorder       *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		accept = ""
+		acceptEncoding = ""
+	})
+
+	JustBeforeEach(func() {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "test_exporter",
+			Name:      "test_gauge",
+			Help:      "Test Gauge.",
+		})
+		gauge.Set(float64(1))
+
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "test_exporter",
+			Name:      "test_counter_total",
+			Help:      "Test Counter.",
+		})
+		counter.Inc()
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(gauge, counter)
+
+		request := httptest.NewRequest("GET", "/metrics", nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		if acceptEncoding != "" {
+			request.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+
+		recorder = httptest.NewRecorder()
+		prometheusHandler(registry, registry).ServeHTTP(recorder, request)
+	})
+
+	It("returns uncompressed metrics", func() {
+		Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(recorder.Body.String()).To(ContainSubstring("test_exporter_test_gauge 1\n"))
+	})
+
+	It("returns metrics in the Prometheus text format", func() {
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+		Expect(recorder.Body.String()).To(ContainSubstring("# TYPE test_exporter_test_counter_total counter\n"))
+		Expect(recorder.Body.String()).ToNot(HaveSuffix("# EOF\n"))
+	})
+
+	Context("when OpenMetrics is accepted", func() {
+		BeforeEach(func() {
+			accept = "application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+		})
+
+		It("returns metrics in the OpenMetrics format", func() {
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("application/openmetrics-text; version=0.0.1"))
+			Expect(recorder.Body.String()).To(ContainSubstring("test_exporter_test_gauge 1.0\n"))
+			Expect(recorder.Body.String()).To(HaveSuffix("# EOF\n"))
+		})
+
+		It("keeps the name of counters", func() {
+			Expect(recorder.Body.String()).To(ContainSubstring("# TYPE test_exporter_test_counter counter\n"))
+			Expect(recorder.Body.String()).To(ContainSubstring("test_exporter_test_counter_total 1.0\n"))
+		})
+	})
+
+	Context("when gzip is accepted", func() {
+		BeforeEach(func() {
+			acceptEncoding = "gzip"
+		})
+
+		It("returns gzip compressed metrics", func() {
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+			reader, err := gzip.NewReader(recorder.Body)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("test_exporter_test_gauge 1\n"))
+		})
+	})
+})
+
+var _ = Describe("directorSDFilename", func() {
+	It("appends the director name before the extension", func() {
+		Expect(directorSDFilename("/tmp/bosh_target_groups.json", "fake-director")).To(Equal("/tmp/bosh_target_groups_fake-director.json"))
+	})
+
+	Context("when the filename has no extension", func() {
+		It("appends the director name", func() {
+			Expect(directorSDFilename("/tmp/bosh_target_groups", "fake-director")).To(Equal("/tmp/bosh_target_groups_fake-director"))
+		})
+	})
+})
+
+var _ = Describe("readDirectorsFile", func() {
+	var (
+		directory       string
+		passwordFile    string
+		directorConfigs []directors.Config
+		err             error
+	)
+
+	BeforeEach(func() {
+		directory = GinkgoT().TempDir()
+		passwordFile = filepath.Join(directory, "password")
+		Expect(os.WriteFile(passwordFile, []byte("fake-file-password\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(directory, "client-secret"), []byte("fake-file-secret\n"), 0600)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		filename := filepath.Join(directory, "directors.yml")
+		Expect(os.WriteFile(filename, []byte(fmt.Sprintf(`
+directors:
+- name: fake-director-1
+  url: https://10.0.0.1:25555
+  username: admin
+  password_file: %s
+  ca_cert_file: /fake/ca-1.crt
+- name: fake-director-2
+  url: https://10.0.0.2:25555
+  uaa_client_id: fake-client
+  uaa_client_secret: fake-secret
+  uaa_client_secret_file: %s
+  ca_cert_file: /fake/ca-2.crt
+`, passwordFile, filepath.Join(directory, "client-secret"))), 0600)).To(Succeed())
+
+		directorConfigs, err = readDirectorsFile(filename)
+	})
+
+	It("reads the secrets that are not set from their files", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(directorConfigs).To(HaveLen(2))
+		Expect(directorConfigs[0].Password).To(Equal("fake-file-password"))
+		Expect(directorConfigs[1].UAAClientSecret).To(Equal("fake-secret"))
+	})
+
+	Context("when a secret file cannot be read", func() {
+		BeforeEach(func() {
+			passwordFile = filepath.Join(directory, "missing")
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error reading password_file of Director `fake-director-1`"))
+		})
+	})
+})
+
+var _ = Describe("setupDirectors", func() {
+	var (
+		registry            *prometheus.Registry
+		boshClients         map[string]director.Director
+		lastSources         map[string]*deployments.LastSource
+		deploymentsFetchers []*deployments.Fetcher
+	)
+
+	BeforeEach(func() {
+		// The directors are set up with the defaults of the flags.
+		Expect(kingpin.CommandLine.Parse([]string{"--metrics.environment=fake-environment"})).Error().ToNot(HaveOccurred())
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"name":"fake-bosh-name","uuid":"fake-bosh-uuid","user":"admin","user_authentication":{"type":"basic","options":{}}}`)
+		}))
+		DeferCleanup(server.Close)
+
+		caCertFile := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)).To(Succeed())
+
+		collectorFilters, err := buildCollectorFilters()
+		Expect(err).ToNot(HaveOccurred())
+
+		registry = prometheus.NewRegistry()
+		boshClients = map[string]director.Director{}
+		lastSources = map[string]*deployments.LastSource{}
+		deploymentsFetchers = setupDirectors(context.Background(), registry, []directors.Config{
+			{
+				Name:       "fake-director-up",
+				URL:        server.URL,
+				Username:   "admin",
+				Password:   "fake-password",
+				CACertFile: caCertFile,
+			},
+			{
+				Name:       "fake-director-down",
+				URL:        server.URL,
+				Username:   "admin",
+				Password:   "fake-password",
+				CACertFile: "/nonexistent/ca.crt",
+			},
+		}, collectorFilters, boshClients, lastSources)
+	})
+
+	It("sets up the directors that can be reached", func() {
+		Expect(boshClients).To(HaveLen(1))
+		Expect(boshClients).To(HaveKey("fake-director-up"))
+		Expect(deploymentsFetchers).To(HaveLen(1))
+	})
+
+	It("reports the directors that cannot be set up as down", func() {
+		metricFamilies, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+
+		var directorUp []string
+		for _, metricFamily := range metricFamilies {
+			if metricFamily.GetName() != "bosh_director_up" {
+				continue
+			}
+			for _, metric := range metricFamily.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "director" {
+						directorUp = append(directorUp, fmt.Sprintf("%s=%g", label.GetValue(), metric.GetGauge().GetValue()))
+					}
+				}
+			}
+		}
+		Expect(directorUp).To(Equal([]string{"fake-director-down=0"}))
+	})
+})
+
+var _ = Describe("readSecret", func() {
+	var (
+		value    string
+		filename string
+		secret   string
+		err      error
+	)
+
+	BeforeEach(func() {
+		value = ""
+
+		secretFile, err := os.CreateTemp("", "bosh_exporter_secret")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = secretFile.WriteString("fake-file-secret\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secretFile.Close()).To(Succeed())
+		filename = secretFile.Name()
+		DeferCleanup(os.Remove, filename)
+	})
+
+	JustBeforeEach(func() {
+		secret, err = readSecret(value, filename)
+	})
+
+	It("returns the contents of the file without trailing newlines", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret).To(Equal("fake-file-secret"))
+	})
+
+	Context("when the value is set", func() {
+		BeforeEach(func() {
+			value = "fake-flag-secret"
+		})
+
+		It("returns the value", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secret).To(Equal("fake-flag-secret"))
+		})
+	})
+
+	Context("when there is no file", func() {
+		BeforeEach(func() {
+			filename = ""
+		})
+
+		It("returns an empty secret", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secret).To(BeEmpty())
+		})
+	})
+
+	Context("when the file cannot be read", func() {
+		BeforeEach(func() {
+			filename = "/nonexistent/bosh_exporter_secret"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("registerCollectors", func() {
+	var (
+		boshClient      *directorfakes.FakeDirector
+		deploymentsFile string
+		registry        *prometheus.Registry
+		circuitBreaker  *deployments.CircuitBreaker
+		err             error
+	)
+
+	BeforeEach(func() {
+		Expect(kingpin.CommandLine.Parse([]string{
+			"--metrics.environment=fake-environment",
+			"--metrics.namespace=companyname_bosh",
+			"--bosh.tasks-limit=10",
+			"--bosh.events-lookback=1h",
+			"--bosh.config-metrics",
+			"--bosh.resurrection-metrics",
+			"--bosh.orphaned-disk-metrics",
+			"--bosh.errand-runs-limit=10",
+			"--bosh.circuit-breaker-threshold=3",
+			"--bosh.instance-info-metrics",
+			"--bosh.instance-group-metrics",
+		})).Error().ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			Expect(kingpin.CommandLine.Parse([]string{"--metrics.environment=fake-environment"})).Error().ToNot(HaveOccurred())
+		})
+
+		file, fileErr := os.CreateTemp("", "bosh_exporter_deployments")
+		Expect(fileErr).ToNot(HaveOccurred())
+		_, fileErr = file.WriteString(`[{"name":"fake-deployment-name","instances":[{"name":"fake-job-name","id":"fake-job-id","index":"0","ips":["1.2.3.4"],"healthy":true,"processes":[{"name":"fake-process-name","healthy":true}]}],"releases":[{"name":"fake-release-name","version":"1.2.3"}],"stemcells":[{"name":"fake-stemcell-name","version":"4.5.6","os_name":"fake-stemcell-os-name"}]}]`)
+		Expect(fileErr).ToNot(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+		DeferCleanup(os.Remove, file.Name())
+		deploymentsFile = file.Name()
+	})
+
+	JustBeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+		boshInfo := director.Info{Name: "fake-bosh-name", UUID: "fake-bosh-uuid"}
+
+		registry = prometheus.NewRegistry()
+		_, requestDuration, buildErr := buildDirectorDeploymentsFetcher(registry, boshClient, boshInfo)
+		Expect(buildErr).ToNot(HaveOccurred())
+
+		collectorFilters, buildErr := buildCollectorFilters()
+		Expect(buildErr).ToNot(HaveOccurred())
+
+		circuitBreaker, err = registerCollectors(context.Background(), registry, boshClient, boshInfo, deployments.NewFileFetcher(deploymentsFile), filepath.Join(GinkgoT().TempDir(), "bosh_target_groups.json"), collectorFilters, nil, nil, requestDuration)
+	})
+
+	It("does not return an error", func() {
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns the circuit breaker", func() {
+		Expect(circuitBreaker).ToNot(BeNil())
+	})
+
+	Context("when the circuit breaker opens", func() {
+		BeforeEach(func() {
+			deploymentsFile = "/nonexistent/deployments.json"
+		})
+
+		It("skips the collectors calling the director", func() {
+			for i := 0; i < 3; i++ {
+				registry.Gather()
+			}
+			Expect(circuitBreaker.Closed()).To(BeFalse())
+
+			tasksCalls := boshClient.RecentTasksCallCount()
+			eventsCalls := boshClient.EventsCallCount()
+			registry.Gather()
+			Expect(boshClient.RecentTasksCallCount()).To(Equal(tasksCalls))
+			Expect(boshClient.EventsCallCount()).To(Equal(eventsCalls))
+		})
+	})
+
+	Context("when the metrics namespace is set", func() {
+		It("prefixes every metric family with the namespace", func() {
+			metricFamilies, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metricFamilies).ToNot(BeEmpty())
+
+			for _, metricFamily := range metricFamilies {
+				Expect(metricFamily.GetName()).To(HavePrefix("companyname_bosh_"))
+			}
+		})
+	})
+})
+
+var _ = Describe("setupProxy", func() {
+	var (
+		proxyURL string
+		err      error
+	)
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("HTTPS_PROXY", "http://fake-env-proxy:3128")
+		GinkgoT().Setenv("HTTP_PROXY", "http://fake-env-proxy:3128")
+		proxyURL = "http://fake-proxy:8080"
+	})
+
+	JustBeforeEach(func() {
+		err = setupProxy(proxyURL)
+	})
+
+	It("overrides the proxy environment variables", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Getenv("HTTPS_PROXY")).To(Equal("http://fake-proxy:8080"))
+		Expect(os.Getenv("HTTP_PROXY")).To(Equal("http://fake-proxy:8080"))
+	})
+
+	Context("when the proxy is not set", func() {
+		BeforeEach(func() {
+			proxyURL = ""
+		})
+
+		It("keeps the proxy environment variables", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Getenv("HTTPS_PROXY")).To(Equal("http://fake-env-proxy:3128"))
+			Expect(os.Getenv("HTTP_PROXY")).To(Equal("http://fake-env-proxy:3128"))
+		})
+	})
+
+	Context("when the proxy has an unsupported scheme", func() {
+		BeforeEach(func() {
+			proxyURL = "ftp://fake-proxy:8080"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Flag --bosh.proxy must be an http, https or socks5 URL, got `ftp://fake-proxy:8080`"))
+			Expect(os.Getenv("HTTPS_PROXY")).To(Equal("http://fake-env-proxy:3128"))
+		})
+	})
+
+	Context("when the proxy has no host", func() {
+		BeforeEach(func() {
+			proxyURL = "http://"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Flag --bosh.proxy must include a host, got `http:`"))
+		})
+	})
+
+	Context("when the proxy cannot be parsed", func() {
+		BeforeEach(func() {
+			proxyURL = "http://fake-proxy:port"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+// startSSHGateway serves SSH on a local port, accepting only authorizedKey
+// for the jumpbox user and forwarding direct-tcpip channels to their
+// destination. It returns the address and the host key of the gateway.
+func startSSHGateway(authorizedKey ssh.PublicKey) (string, ssh.PublicKey) {
+	hostKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "jumpbox" && string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key for %q", conn.User())
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	DeferCleanup(listener.Close)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				_, channels, requests, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(requests)
+
+				for newChannel := range channels {
+					var destination struct {
+						Host       string
+						Port       uint32
+						OriginHost string
+						OriginPort uint32
+					}
+					if newChannel.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChannel.ExtraData(), &destination) != nil {
+						newChannel.Reject(ssh.UnknownChannelType, "unsupported channel")
+						continue
+					}
+
+					target, err := net.Dial("tcp", net.JoinHostPort(destination.Host, fmt.Sprint(destination.Port)))
+					if err != nil {
+						newChannel.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+
+					channel, channelRequests, err := newChannel.Accept()
+					if err != nil {
+						target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(channelRequests)
+
+					go func() {
+						defer channel.Close()
+						defer target.Close()
+						go io.Copy(target, channel)
+						io.Copy(channel, target)
+					}()
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), hostSigner.PublicKey()
+}
+
+var _ = Describe("setupSSHTunnel", func() {
+	var (
+		host           string
+		privateKeyFile string
+		hostKey        string
+		err            error
+	)
+
+	generateHostKey := func() string {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		publicKey, err := ssh.NewPublicKey(&key.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(ssh.MarshalAuthorizedKey(publicKey))
+	}
+
+	writePrivateKey := func() ssh.PublicKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		privateKeyFile = filepath.Join(GinkgoT().TempDir(), "id_ecdsa")
+		Expect(os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)).To(Succeed())
+
+		publicKey, err := ssh.NewPublicKey(&key.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		return publicKey
+	}
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("HTTPS_PROXY", "")
+		GinkgoT().Setenv("HTTP_PROXY", "")
+
+		var gatewayHostKey ssh.PublicKey
+		host, gatewayHostKey = startSSHGateway(writePrivateKey())
+		hostKey = string(ssh.MarshalAuthorizedKey(gatewayHostKey))
+	})
+
+	JustBeforeEach(func() {
+		err = setupSSHTunnel(host, "jumpbox", privateKeyFile, hostKey)
+	})
+
+	It("tunnels the requests through the SSH gateway", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Getenv("HTTPS_PROXY")).To(HavePrefix("socks5://127.0.0.1:"))
+		Expect(os.Getenv("HTTP_PROXY")).To(Equal(os.Getenv("HTTPS_PROXY")))
+
+		director := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "fake-director")
+		}))
+		DeferCleanup(director.Close)
+
+		proxyURL, err := url.Parse(os.Getenv("HTTPS_PROXY"))
+		Expect(err).ToNot(HaveOccurred())
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+		var response *http.Response
+		Eventually(func() error {
+			response, err = client.Get(director.URL)
+			return err
+		}).Should(Succeed())
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("fake-director"))
+	})
+
+	Context("when the SSH tunnel is not set", func() {
+		BeforeEach(func() {
+			host = ""
+			privateKeyFile = ""
+			hostKey = ""
+		})
+
+		It("does not set a proxy", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Getenv("HTTPS_PROXY")).To(BeEmpty())
+		})
+	})
+
+	Context("when only the private key is set", func() {
+		BeforeEach(func() {
+			host = ""
+			hostKey = ""
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("Flag --bosh.ssh-tunnel.private-key-file requires --bosh.ssh-tunnel.host"))
+		})
+	})
+
+	Context("when the private key is not set", func() {
+		BeforeEach(func() {
+			privateKeyFile = ""
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("Flag --bosh.ssh-tunnel.host requires --bosh.ssh-tunnel.private-key-file"))
+		})
+	})
+
+	Context("when only the host key is set", func() {
+		BeforeEach(func() {
+			host = ""
+			privateKeyFile = ""
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("Flag --bosh.ssh-tunnel.host-key requires --bosh.ssh-tunnel.host"))
+		})
+	})
+
+	Context("when the host key is not set", func() {
+		BeforeEach(func() {
+			hostKey = ""
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("Flag --bosh.ssh-tunnel.host requires --bosh.ssh-tunnel.host-key"))
+		})
+	})
+
+	Context("when the host key cannot be parsed", func() {
+		BeforeEach(func() {
+			hostKey = "fake-host-key"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(HavePrefix("Error parsing --bosh.ssh-tunnel.host-key")))
+		})
+	})
+
+	Context("when the SSH gateway presents another host key", func() {
+		BeforeEach(func() {
+			hostKey = generateHostKey()
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(HavePrefix(fmt.Sprintf("Error establishing the SSH tunnel through `%s`", host))))
+			Expect(err).To(MatchError(ContainSubstring("host key mismatch")))
+			Expect(os.Getenv("HTTPS_PROXY")).To(BeEmpty())
+		})
+	})
+
+	Context("when the private key file does not exist", func() {
+		BeforeEach(func() {
+			privateKeyFile = filepath.Join(GinkgoT().TempDir(), "missing")
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(HavePrefix("Error reading --bosh.ssh-tunnel.private-key-file")))
+		})
+	})
+
+	Context("when the SSH gateway rejects the private key", func() {
+		BeforeEach(func() {
+			writePrivateKey()
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(HavePrefix(fmt.Sprintf("Error establishing the SSH tunnel through `%s`", host))))
+			Expect(os.Getenv("HTTPS_PROXY")).To(BeEmpty())
+		})
+	})
+
+	Context("when the SSH gateway is unreachable", func() {
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			host = listener.Addr().String()
+			listener.Close()
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError(HavePrefix(fmt.Sprintf("Error establishing the SSH tunnel through `%s`", host))))
+			Expect(os.Getenv("HTTPS_PROXY")).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("authHandler", func() {
+	var (
+		username string
+		password string
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		username = ""
+		password = ""
+
+		originalUsername, originalPassword := *authUsername, *authPassword
+		*authUsername = "fake-username"
+		*authPassword = "fake-password"
+		DeferCleanup(func() {
+			*authUsername, *authPassword = originalUsername, originalPassword
+		})
+	})
+
+	JustBeforeEach(func() {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "fake-metrics")
+		})
+
+		request := httptest.NewRequest("GET", "/metrics", nil)
+		if username != "" || password != "" {
+			request.SetBasicAuth(username, password)
+		}
+
+		recorder = httptest.NewRecorder()
+		authHandler(handler).ServeHTTP(recorder, request)
+	})
+
+	Context("when the credentials are missing", func() {
+		It("returns unauthorized", func() {
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal("Basic realm=\"metrics\""))
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("fake-metrics"))
+		})
+	})
+
+	Context("when the password is wrong", func() {
+		BeforeEach(func() {
+			username = "fake-username"
+			password = "fake-wrong-password"
+		})
+
+		It("returns unauthorized", func() {
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("when the username is wrong", func() {
+		BeforeEach(func() {
+			username = "fake-wrong-username"
+			password = "fake-password"
+		})
+
+		It("returns unauthorized", func() {
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("when the credentials are valid", func() {
+		BeforeEach(func() {
+			username = "fake-username"
+			password = "fake-password"
+		})
+
+		It("serves the request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("fake-metrics"))
+		})
+	})
+
+	Context("when basic auth is not configured", func() {
+		BeforeEach(func() {
+			*authUsername = ""
+			*authPassword = ""
+		})
+
+		It("serves the request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("fake-metrics"))
+		})
+	})
+})
+
+var _ = Describe("serve", func() {
+	var (
+		webConfigFilename string
+		serverURL         string
+	)
+
+	writeWebConfig := func(username string, password string) {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(webConfigFilename, []byte(fmt.Sprintf("basic_auth_users:\n  %s: %s\n", username, hashedPassword)), 0o600)).To(Succeed())
+	}
+
+	get := func(username string, password string) int {
+		request, err := http.NewRequest("GET", serverURL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		request.SetBasicAuth(username, password)
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	BeforeEach(func() {
+		webConfigFilename = filepath.Join(GinkgoT().TempDir(), "web-config.yml")
+		writeWebConfig("fake-username", "fake-password")
+
+		originalWebConfigFile := *webConfigFile
+		*webConfigFile = webConfigFilename
+		DeferCleanup(func() {
+			*webConfigFile = originalWebConfigFile
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(listener.Close)
+		serverURL = fmt.Sprintf("http://%s/metrics", listener.Addr())
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "fake-metrics")
+		})
+		go serve(listener, &http.Server{Handler: handler})
+	})
+
+	It("serves the requests with valid credentials", func() {
+		Expect(get("fake-username", "fake-password")).To(Equal(http.StatusOK))
+	})
+
+	It("rejects the requests with invalid credentials", func() {
+		Expect(get("fake-username", "fake-wrong-password")).To(Equal(http.StatusUnauthorized))
+	})
+
+	Context("when the web config file changes", func() {
+		It("uses the new users without restarting", func() {
+			Expect(get("fake-username", "fake-password")).To(Equal(http.StatusOK))
+
+			writeWebConfig("fake-username", "fake-new-password")
+
+			Expect(get("fake-username", "fake-password")).To(Equal(http.StatusUnauthorized))
+			Expect(get("fake-username", "fake-new-password")).To(Equal(http.StatusOK))
+		})
+	})
+})
+
+var _ = Describe("validateWebConfig", func() {
+	BeforeEach(func() {
+		originalWebConfigFile, originalTLSCertFile := *webConfigFile, *tlsCertFile
+		DeferCleanup(func() {
+			*webConfigFile, *tlsCertFile = originalWebConfigFile, originalTLSCertFile
+		})
+	})
+
+	It("accepts an unset web config file", func() {
+		*webConfigFile = ""
+		Expect(validateWebConfig()).To(Succeed())
+	})
+
+	Context("when the TLS flags are also set", func() {
+		It("returns an error", func() {
+			*webConfigFile = "fake-web-config.yml"
+			*tlsCertFile = "fake-cert.pem"
+			Expect(validateWebConfig()).To(MatchError("Flag --web.config.file cannot be used with --web.tls.cert_file or --web.tls.key_file"))
+		})
+	})
+
+	Context("when the web config file is invalid", func() {
+		It("returns an error", func() {
+			webConfigFilename := filepath.Join(GinkgoT().TempDir(), "web-config.yml")
+			Expect(os.WriteFile(webConfigFilename, []byte("tls_server_config:\n  cert_file: fake-cert.pem\n"), 0o600)).To(Succeed())
+
+			*webConfigFile = webConfigFilename
+			*tlsCertFile = ""
+			Expect(validateWebConfig()).ToNot(Succeed())
+		})
+	})
+})
+
+var _ = Describe("shutdown", func() {
+	var (
+		scrapes   *inFlightScrapes
+		server    *http.Server
+		serverURL string
+		release   chan struct{}
+		started   chan struct{}
+	)
+
+	BeforeEach(func() {
+		scrapes = &inFlightScrapes{}
+		release = make(chan struct{})
+		started = make(chan struct{}, 1)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		serverURL = fmt.Sprintf("http://%s/metrics", listener.Addr())
+
+		server = &http.Server{Handler: scrapes.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			io.WriteString(w, "fake-metrics")
+		}))}
+		go server.Serve(listener)
+		DeferCleanup(server.Close)
+	})
+
+	startScrape := func() chan int {
+		statusCode := make(chan int, 1)
+		go func() {
+			response, err := http.Get(serverURL)
+			if err != nil {
+				statusCode <- 0
+				return
+			}
+			defer response.Body.Close()
+			statusCode <- response.StatusCode
+		}()
+		Eventually(started).Should(Receive())
+		Expect(scrapes.inFlight()).To(Equal(int64(1)))
+
+		return statusCode
+	}
+
+	It("waits for the in-flight scrapes to finish", func() {
+		statusCode := startScrape()
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+		}()
+		Expect(shutdown(server, nil, func() {}, scrapes, 5*time.Second)).To(Succeed())
+		Eventually(statusCode).Should(Receive(Equal(http.StatusOK)))
+		Expect(scrapes.inFlight()).To(BeZero())
+	})
+
+	It("stops accepting new scrapes", func() {
+		close(release)
+		Expect(shutdown(server, nil, func() {}, scrapes, 5*time.Second)).To(Succeed())
+
+		_, err := http.Get(serverURL)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("stops the deployments fetchers", func() {
+		close(release)
+		fetcher, _, err := buildDirectorDeploymentsFetcher(prometheus.NewRegistry(), &directorfakes.FakeDirector{}, director.Info{Name: "fake-director-name"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdown(server, []*deployments.Fetcher{fetcher}, func() {}, scrapes, 5*time.Second)).To(Succeed())
+
+		_, err = fetcher.Deployments()
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+
+	It("stops the background fetches", func() {
+		close(release)
+		background, stopBackground := context.WithCancel(context.Background())
+		Expect(shutdown(server, nil, stopBackground, scrapes, 5*time.Second)).To(Succeed())
+
+		Expect(background.Err()).To(MatchError(context.Canceled))
+	})
+
+	It("returns an error when the scrapes do not finish within the timeout", func() {
+		startScrape()
+		DeferCleanup(func() {
+			close(release)
+		})
+
+		err := shutdown(server, nil, func() {}, scrapes, 50*time.Millisecond)
+		Expect(err).To(MatchError(ContainSubstring("with 1 scrapes still in flight")))
+	})
+})
+
+var _ = Describe("shutdownTextfile", func() {
+	var (
+		writerDone chan struct{}
+	)
+
+	BeforeEach(func() {
+		writerDone = make(chan struct{})
+	})
+
+	It("waits for the textfile writer to return", func() {
+		background, stopBackground := context.WithCancel(context.Background())
+		go func() {
+			<-background.Done()
+			close(writerDone)
+		}()
+
+		Expect(shutdownTextfile(writerDone, nil, stopBackground, 5*time.Second)).To(Succeed())
+		Expect(writerDone).To(BeClosed())
+	})
+
+	It("stops the deployments fetchers", func() {
+		close(writerDone)
+		fetcher, _, err := buildDirectorDeploymentsFetcher(prometheus.NewRegistry(), &directorfakes.FakeDirector{}, director.Info{Name: "fake-director-name"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdownTextfile(writerDone, []*deployments.Fetcher{fetcher}, func() {}, 5*time.Second)).To(Succeed())
+
+		_, err = fetcher.Deployments()
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+
+	It("returns an error when the textfile write does not finish within the timeout", func() {
+		err := shutdownTextfile(writerDone, nil, func() {}, 50*time.Millisecond)
+		Expect(err).To(MatchError(ContainSubstring("with the textfile write still in progress")))
+	})
+})
@@ -0,0 +1,78 @@
+package certs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/crypto"
+	"github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/bosh-utils/system"
+	"github.com/prometheus/common/log"
+)
+
+var caCertExtensions = map[string]bool{
+	".pem": true,
+	".crt": true,
+}
+
+// ReadCACert returns the PEM contents of caCertPath. When caCertPath is a
+// directory, every `.pem` and `.crt` file in it is read and the ones holding
+// valid certificates are concatenated into a single bundle; files that fail to
+// parse are skipped with a warning so a single bad entry does not break a CA
+// rotation.
+func ReadCACert(caCertPath string, logger logger.Logger) (string, error) {
+	if caCertPath == "" {
+		return "", nil
+	}
+
+	fs := system.NewOsFileSystem(logger)
+
+	caCertFullPath, err := fs.ExpandPath(caCertPath)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(caCertFullPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return fs.ReadFileString(caCertFullPath)
+	}
+
+	entries, err := os.ReadDir(caCertFullPath)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	caCerts := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !caCertExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+
+		caCertFile := filepath.Join(caCertFullPath, entry.Name())
+		caCert, err := fs.ReadFileString(caCertFile)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := crypto.CertPoolFromPEM([]byte(caCert)); err != nil {
+			log.Warnf("Skipping CA Certificate file `%s`: %v", caCertFile, err)
+			continue
+		}
+
+		caCerts = append(caCerts, strings.TrimSpace(caCert))
+	}
+
+	if len(caCerts) == 0 {
+		return "", fmt.Errorf("No valid CA Certificates found in directory `%s`", caCertFullPath)
+	}
+
+	return strings.Join(caCerts, "\n") + "\n", nil
+}
@@ -0,0 +1,159 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-utils/crypto"
+	"github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/certs"
+)
+
+func init() {
+	_ = log.Base().SetLevel("fatal")
+}
+
+func generateCACert(commonName string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+var _ = Describe("ReadCACert", func() {
+	var (
+		err        error
+		tmpDir     string
+		caCertPath string
+		caCert     string
+		validCert  string
+		otherCert  string
+	)
+
+	BeforeEach(func() {
+		tmpDir, err = os.MkdirTemp("", "ca_certs")
+		Expect(err).ToNot(HaveOccurred())
+
+		validCert = generateCACert("fake-ca")
+		otherCert = generateCACert("fake-rotated-ca")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		caCert, err = ReadCACert(caCertPath, logger.NewLogger(logger.LevelNone))
+	})
+
+	writeFile := func(name string, content string) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	Context("when the path is empty", func() {
+		BeforeEach(func() {
+			caCertPath = ""
+		})
+
+		It("returns an empty CA Certificate", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(caCert).To(BeEmpty())
+		})
+	})
+
+	Context("when the path is a file", func() {
+		BeforeEach(func() {
+			caCertPath = writeFile("ca.pem", validCert)
+		})
+
+		It("returns the file contents", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(caCert).To(Equal(validCert))
+		})
+	})
+
+	Context("when the path does not exist", func() {
+		BeforeEach(func() {
+			caCertPath = filepath.Join(tmpDir, "missing.pem")
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the path is a directory", func() {
+		BeforeEach(func() {
+			writeFile("a.pem", validCert)
+			writeFile("b.crt", otherCert)
+			writeFile("c.txt", "not a certificate")
+			caCertPath = tmpDir
+		})
+
+		It("returns a bundle of every .pem and .crt file", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.Count(caCert, "BEGIN CERTIFICATE")).To(Equal(2))
+			Expect(caCert).To(ContainSubstring(strings.TrimSpace(validCert)))
+			Expect(caCert).To(ContainSubstring(strings.TrimSpace(otherCert)))
+		})
+
+		It("returns a bundle that can be parsed", func() {
+			_, err := crypto.CertPoolFromPEM([]byte(caCert))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("and some entries are not valid PEM certificates", func() {
+			BeforeEach(func() {
+				writeFile("d.pem", "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n")
+				writeFile("e.crt", "not a certificate")
+			})
+
+			It("skips the invalid entries", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(strings.Count(caCert, "BEGIN CERTIFICATE")).To(Equal(2))
+				_, err := crypto.CertPoolFromPEM([]byte(caCert))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("when the directory has no valid PEM certificates", func() {
+		BeforeEach(func() {
+			writeFile("a.pem", "not a certificate")
+			caCertPath = tmpDir
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No valid CA Certificates found in directory"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package collectors
 
 import (
+	"errors"
 	"sync"
 	"time"
 
@@ -9,16 +10,23 @@ import (
 
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
 	"github.com/bosh-prometheus/bosh_exporter/filters"
+	"github.com/bosh-prometheus/bosh_exporter/vmtypes"
 )
 
 type BoshCollector struct {
-	enabledCollectors                   []Collector
-	deploymentsFetcher                  *deployments.Fetcher
-	totalBoshScrapesMetric              prometheus.Counter
-	totalBoshScrapeErrorsMetric         prometheus.Counter
-	lastBoshScrapeErrorMetric           prometheus.Gauge
-	lastBoshScrapeTimestampMetric       prometheus.Gauge
-	lastBoshScrapeDurationSecondsMetric prometheus.Gauge
+	enabledCollectors                       []Collector
+	deploymentsFetcher                      deployments.DeploymentsSource
+	totalBoshScrapesMetric                  prometheus.Counter
+	totalBoshScrapeErrorsMetric             prometheus.Counter
+	lastBoshScrapeErrorMetric               prometheus.Gauge
+	lastBoshScrapeTimestampMetric           prometheus.Gauge
+	lastBoshScrapeDurationSecondsMetric     prometheus.Gauge
+	lastBoshSuccessfulScrapeTimestampMetric prometheus.Gauge
+	boshScrapeDurationSecondsMetric         prometheus.Gauge
+	deploymentFetchDurationSecondsMetric    *prometheus.GaugeVec
+	totalMetadataCacheHitsMetric            prometheus.Counter
+	totalMetadataCacheMissesMetric          prometheus.Counter
+	scrapeTruncatedMetric                   prometheus.Gauge
 }
 
 func NewBoshCollector(
@@ -27,24 +35,41 @@ func NewBoshCollector(
 	boshName string,
 	boshUUID string,
 	serviceDiscoveryFilename string,
-	deploymentsFetcher *deployments.Fetcher,
+	serviceDiscoveryGroupBy string,
+	serviceDiscoveryLabelsFilter *filters.LabelsFilter,
+	deploymentsFetcher deployments.DeploymentsSource,
 	collectorsFilter *filters.CollectorsFilter,
 	azsFilter *filters.AZsFilter,
 	processesFilter *filters.RegexpFilter,
 	cidrsFilter *filters.CidrFilter,
+	ipFamily string,
+	onlyUnhealthy bool,
+	instanceInfoMetrics bool,
+	instanceGroupMetrics bool,
+	vitalsVMTypeLabel bool,
+	deprecatedStemcellsFilter *filters.DeprecatedFilter,
+	deprecatedReleasesFilter *filters.DeprecatedFilter,
+	deploymentTagKeys []string,
+	metricsFilter *filters.MetricsFilter,
+	vmTypesFetcher *vmtypes.Fetcher,
 ) *BoshCollector {
 	enabledCollectors := []Collector{}
 
 	if collectorsFilter.Enabled(filters.DeploymentsCollector) {
-		deploymentsCollector := NewDeploymentsCollector(namespace, environment, boshName, boshUUID)
+		deploymentsCollector := NewDeploymentsCollector(namespace, environment, boshName, boshUUID, deprecatedStemcellsFilter, deprecatedReleasesFilter, deploymentTagKeys)
 		enabledCollectors = append(enabledCollectors, deploymentsCollector)
 	}
 
 	if collectorsFilter.Enabled(filters.JobsCollector) {
-		jobsCollector := NewJobsCollector(namespace, environment, boshName, boshUUID, azsFilter, cidrsFilter)
+		jobsCollector := NewJobsCollector(namespace, environment, boshName, boshUUID, azsFilter, cidrsFilter, onlyUnhealthy, instanceInfoMetrics, instanceGroupMetrics, vitalsVMTypeLabel, metricsFilter)
 		enabledCollectors = append(enabledCollectors, jobsCollector)
 	}
 
+	if vmTypesFetcher != nil && collectorsFilter.Enabled(filters.JobsCollector) {
+		vmTypesCollector := NewVMTypesCollector(namespace, environment, boshName, boshUUID, vmTypesFetcher, azsFilter)
+		enabledCollectors = append(enabledCollectors, vmTypesCollector)
+	}
+
 	if collectorsFilter.Enabled(filters.ServiceDiscoveryCollector) {
 		serviceDiscoveryCollector := NewServiceDiscoveryCollector(
 			namespace,
@@ -52,9 +77,12 @@ func NewBoshCollector(
 			boshName,
 			boshUUID,
 			serviceDiscoveryFilename,
+			serviceDiscoveryGroupBy,
+			serviceDiscoveryLabelsFilter,
 			azsFilter,
 			processesFilter,
 			cidrsFilter,
+			ipFamily,
 		)
 		enabledCollectors = append(enabledCollectors, serviceDiscoveryCollector)
 	}
@@ -129,14 +157,105 @@ func NewBoshCollector(
 		},
 	)
 
+	lastBoshSuccessfulScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_scrape_timestamp_seconds",
+			Help:      "Number of seconds since 1970 since the last successful fetch of all deployments from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	boshScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "scrape_duration_seconds",
+			Help:      "Duration of the last fetch of all deployments from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	deploymentFetchDurationSecondsMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "fetch_duration_seconds",
+			Help:      "Duration of the last fetch of this deployment from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	totalMetadataCacheHitsMetric := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "metadata_cache_hits_total",
+			Help:      "Total number of times deployment releases and stemcells were read from the cache.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	totalMetadataCacheMissesMetric := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "metadata_cache_misses_total",
+			Help:      "Total number of times deployment releases and stemcells were not found in the cache.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	scrapeTruncatedMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "scrape_truncated",
+			Help:      "Whether the last scrape from BOSH was aborted because the deployments exceeded the maximum number of instances (1 for aborted, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
 	return &BoshCollector{
-		enabledCollectors:                   enabledCollectors,
-		deploymentsFetcher:                  deploymentsFetcher,
-		totalBoshScrapesMetric:              totalBoshScrapesMetric,
-		totalBoshScrapeErrorsMetric:         totalBoshScrapeErrorsMetric,
-		lastBoshScrapeErrorMetric:           lastBoshScrapeErrorMetric,
-		lastBoshScrapeTimestampMetric:       lastBoshScrapeTimestampMetric,
-		lastBoshScrapeDurationSecondsMetric: lastBoshScrapeDurationSecondsMetric,
+		enabledCollectors:                       enabledCollectors,
+		deploymentsFetcher:                      deploymentsFetcher,
+		totalBoshScrapesMetric:                  totalBoshScrapesMetric,
+		totalBoshScrapeErrorsMetric:             totalBoshScrapeErrorsMetric,
+		lastBoshScrapeErrorMetric:               lastBoshScrapeErrorMetric,
+		lastBoshScrapeTimestampMetric:           lastBoshScrapeTimestampMetric,
+		lastBoshScrapeDurationSecondsMetric:     lastBoshScrapeDurationSecondsMetric,
+		lastBoshSuccessfulScrapeTimestampMetric: lastBoshSuccessfulScrapeTimestampMetric,
+		boshScrapeDurationSecondsMetric:         boshScrapeDurationSecondsMetric,
+		deploymentFetchDurationSecondsMetric:    deploymentFetchDurationSecondsMetric,
+		totalMetadataCacheHitsMetric:            totalMetadataCacheHitsMetric,
+		totalMetadataCacheMissesMetric:          totalMetadataCacheMissesMetric,
+		scrapeTruncatedMetric:                   scrapeTruncatedMetric,
 	}
 }
 
@@ -157,23 +276,41 @@ func (c *BoshCollector) Describe(ch chan<- *prometheus.Desc) {
 	c.lastBoshScrapeErrorMetric.Describe(ch)
 	c.lastBoshScrapeTimestampMetric.Describe(ch)
 	c.lastBoshScrapeDurationSecondsMetric.Describe(ch)
+	c.lastBoshSuccessfulScrapeTimestampMetric.Describe(ch)
+	c.boshScrapeDurationSecondsMetric.Describe(ch)
+	c.deploymentFetchDurationSecondsMetric.Describe(ch)
+	c.totalMetadataCacheHitsMetric.Describe(ch)
+	c.totalMetadataCacheMissesMetric.Describe(ch)
+	c.scrapeTruncatedMetric.Describe(ch)
 }
 
 func (c *BoshCollector) Collect(ch chan<- prometheus.Metric) {
 	var begun = time.Now()
 
 	scrapeError := 0
+	scrapeTruncated := 0
 	c.totalBoshScrapesMetric.Inc()
 	deployments, err := c.deploymentsFetcher.Deployments()
+	c.boshScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
 	if err != nil {
 		log.Error(err)
 		scrapeError = 1
 		c.totalBoshScrapeErrorsMetric.Inc()
+
+		if isMaxInstances(err) {
+			scrapeTruncated = 1
+		}
 	} else {
+		c.lastBoshSuccessfulScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	}
+
+	if err == nil || len(deployments) > 0 {
 		if err := c.executeCollectors(deployments, ch); err != nil {
 			log.Error(err)
-			scrapeError = 1
-			c.totalBoshScrapeErrorsMetric.Inc()
+			if scrapeError == 0 {
+				scrapeError = 1
+				c.totalBoshScrapeErrorsMetric.Inc()
+			}
 		}
 	}
 
@@ -189,13 +326,43 @@ func (c *BoshCollector) Collect(ch chan<- prometheus.Metric) {
 
 	c.lastBoshScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
 	c.lastBoshScrapeDurationSecondsMetric.Collect(ch)
+
+	c.lastBoshSuccessfulScrapeTimestampMetric.Collect(ch)
+
+	c.boshScrapeDurationSecondsMetric.Collect(ch)
+
+	c.deploymentFetchDurationSecondsMetric.Reset()
+	for _, deployment := range deployments {
+		c.deploymentFetchDurationSecondsMetric.WithLabelValues(deployment.Name).Set(deployment.FetchDuration.Seconds())
+	}
+	c.deploymentFetchDurationSecondsMetric.Collect(ch)
+
+	for _, deployment := range deployments {
+		if deployment.MetadataCacheHit == nil {
+			continue
+		}
+		if *deployment.MetadataCacheHit {
+			c.totalMetadataCacheHitsMetric.Inc()
+		} else {
+			c.totalMetadataCacheMissesMetric.Inc()
+		}
+	}
+	c.totalMetadataCacheHitsMetric.Collect(ch)
+	c.totalMetadataCacheMissesMetric.Collect(ch)
+
+	c.scrapeTruncatedMetric.Set(float64(scrapeTruncated))
+	c.scrapeTruncatedMetric.Collect(ch)
+}
+
+func isMaxInstances(err error) bool {
+	var maxInstancesError *deployments.MaxInstancesError
+	return errors.As(err, &maxInstancesError)
 }
 
 func (c *BoshCollector) executeCollectors(deployments []deployments.DeploymentInfo, ch chan<- prometheus.Metric) error {
 	var wg = &sync.WaitGroup{}
 
-	doneChannel := make(chan bool, 1)
-	errChannel := make(chan error, 1)
+	errChannel := make(chan error, len(c.enabledCollectors))
 
 	for _, collector := range c.enabledCollectors {
 		wg.Add(1)
@@ -207,16 +374,8 @@ func (c *BoshCollector) executeCollectors(deployments []deployments.DeploymentIn
 		}(collector)
 	}
 
-	go func() {
-		wg.Wait()
-		close(doneChannel)
-	}()
-
-	select {
-	case <-doneChannel:
-	case err := <-errChannel:
-		return err
-	}
+	wg.Wait()
+	close(errChannel)
 
-	return nil
+	return <-errChannel
 }
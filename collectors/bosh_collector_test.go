@@ -3,6 +3,8 @@ package collectors_test
 import (
 	"errors"
 	"os"
+	"runtime"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
 	"github.com/bosh-prometheus/bosh_exporter/filters"
+	"github.com/bosh-prometheus/bosh_exporter/vmtypes"
 
 	. "github.com/bosh-prometheus/bosh_exporter/collectors"
 	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
@@ -33,21 +36,33 @@ var _ = Describe("BoshCollector", func() {
 		tmpfile                  *os.File
 		serviceDiscoveryFilename string
 
-		boshDeployments    []string
-		boshClient         *directorfakes.FakeDirector
-		deploymentsFilter  *filters.DeploymentsFilter
-		deploymentsFetcher *deployments.Fetcher
-		collectorsFilter   *filters.CollectorsFilter
-		azsFilter          *filters.AZsFilter
-		processesFilter    *filters.RegexpFilter
-		cidrsFilter        *filters.CidrFilter
-		boshCollector      *BoshCollector
-
-		totalBoshScrapesMetric              prometheus.Counter
-		totalBoshScrapeErrorsMetric         prometheus.Counter
-		lastBoshScrapeErrorMetric           prometheus.Gauge
-		lastBoshScrapeTimestampMetric       prometheus.Gauge
-		lastBoshScrapeDurationSecondsMetric prometheus.Gauge
+		boshDeployments      []string
+		boshClient           *directorfakes.FakeDirector
+		deploymentsFilter    *filters.DeploymentsFilter
+		instanceGroupsFilter *filters.InstanceGroupsFilter
+		deploymentsFetcher   *deployments.Fetcher
+		collectorsFilter     *filters.CollectorsFilter
+		azsFilter            *filters.AZsFilter
+		processesFilter      *filters.RegexpFilter
+		cidrsFilter          *filters.CidrFilter
+		onlyUnhealthy        bool
+		deprecatedFilter     *filters.DeprecatedFilter
+		metricsFilter        *filters.MetricsFilter
+		labelsFilter         *filters.LabelsFilter
+		vmTypesFetcher       *vmtypes.Fetcher
+		boshCollector        *BoshCollector
+
+		totalBoshScrapesMetric                  prometheus.Counter
+		totalBoshScrapeErrorsMetric             prometheus.Counter
+		lastBoshScrapeErrorMetric               prometheus.Gauge
+		lastBoshScrapeTimestampMetric           prometheus.Gauge
+		lastBoshScrapeDurationSecondsMetric     prometheus.Gauge
+		lastBoshSuccessfulScrapeTimestampMetric prometheus.Gauge
+		boshScrapeDurationSecondsMetric         prometheus.Gauge
+		deploymentFetchDurationSeconds          *prometheus.GaugeVec
+		totalMetadataCacheHitsMetric            prometheus.Counter
+		totalMetadataCacheMissesMetric          prometheus.Counter
+		scrapeTruncatedMetric                   prometheus.Gauge
 	)
 
 	BeforeEach(func() {
@@ -61,15 +76,31 @@ var _ = Describe("BoshCollector", func() {
 
 		boshDeployments = []string{}
 		boshClient = &directorfakes.FakeDirector{}
-		deploymentsFilter = filters.NewDeploymentsFilter(boshDeployments, boshClient)
-		deploymentsFetcher = deployments.NewFetcher(*deploymentsFilter)
+		deploymentsFilter, err = filters.NewDeploymentsFilter(boshDeployments, []string{}, boshClient)
+		Expect(err).ToNot(HaveOccurred())
+		instanceGroupsFilter, err = filters.NewInstanceGroupsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		deploymentsFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+			DeploymentsFilter:    *deploymentsFilter,
+			InstanceGroupsFilter: *instanceGroupsFilter,
+			AZsFilter:            *filters.NewAZsFilter([]string{}),
+			RetryAttempts:        1,
+		})
 		collectorsFilter, err = filters.NewCollectorsFilter([]string{})
 		Expect(err).ToNot(HaveOccurred())
 		azsFilter = filters.NewAZsFilter([]string{})
 		cidrsFilter, err = filters.NewCidrFilter([]string{})
 		Expect(err).ToNot(HaveOccurred())
 		processesFilter, err = filters.NewRegexpFilter([]string{})
+		onlyUnhealthy = false
+		Expect(err).ToNot(HaveOccurred())
+		deprecatedFilter, err = filters.NewDeprecatedFilter([]string{})
+		Expect(err).ToNot(HaveOccurred())
+		metricsFilter, err = filters.NewMetricsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		labelsFilter, err = filters.NewLabelsFilter([]string{})
 		Expect(err).ToNot(HaveOccurred())
+		vmTypesFetcher = nil
 
 		totalBoshScrapesMetric = prometheus.NewCounter(
 			prometheus.CounterOpts{
@@ -144,10 +175,95 @@ var _ = Describe("BoshCollector", func() {
 				},
 			},
 		)
+
+		lastBoshSuccessfulScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_scrape_timestamp_seconds",
+				Help:      "Number of seconds since 1970 since the last successful fetch of all deployments from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		boshScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "scrape_duration_seconds",
+				Help:      "Duration of the last fetch of all deployments from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		deploymentFetchDurationSeconds = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "fetch_duration_seconds",
+				Help:      "Duration of the last fetch of this deployment from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		totalMetadataCacheHitsMetric = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "metadata_cache_hits_total",
+				Help:      "Total number of times deployment releases and stemcells were read from the cache.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		totalMetadataCacheMissesMetric = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "metadata_cache_misses_total",
+				Help:      "Total number of times deployment releases and stemcells were not found in the cache.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		scrapeTruncatedMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "scrape_truncated",
+				Help:      "Whether the last scrape from BOSH was aborted because the deployments exceeded the maximum number of instances (1 for aborted, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
 	})
 
 	AfterEach(func() {
-		err = os.Remove(serviceDiscoveryFilename)
+		err = os.Remove(tmpfile.Name())
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -158,11 +274,23 @@ var _ = Describe("BoshCollector", func() {
 			boshName,
 			boshUUID,
 			serviceDiscoveryFilename,
+			ServiceDiscoveryGroupByProcess,
+			labelsFilter,
 			deploymentsFetcher,
 			collectorsFilter,
 			azsFilter,
 			processesFilter,
 			cidrsFilter,
+			filters.IPv4Family,
+			onlyUnhealthy,
+			false,
+			false,
+			false,
+			deprecatedFilter,
+			deprecatedFilter,
+			nil,
+			metricsFilter,
+			vmTypesFetcher,
 		)
 	})
 
@@ -198,6 +326,30 @@ var _ = Describe("BoshCollector", func() {
 		It("returns a last_scrape_duration_seconds metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(lastBoshScrapeDurationSecondsMetric.Desc())))
 		})
+
+		It("returns a last_scrape_timestamp_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastBoshSuccessfulScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(boshScrapeDurationSecondsMetric.Desc())))
+		})
+
+		It("returns a deployment_fetch_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentFetchDurationSeconds.WithLabelValues("fake-deployment-name").Desc())))
+		})
+
+		It("returns a metadata_cache_hits_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(totalMetadataCacheHitsMetric.Desc())))
+		})
+
+		It("returns a metadata_cache_misses_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(totalMetadataCacheMissesMetric.Desc())))
+		})
+
+		It("returns a scrape_truncated metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(scrapeTruncatedMetric.Desc())))
+		})
 	})
 
 	Describe("Collect", func() {
@@ -225,6 +377,80 @@ var _ = Describe("BoshCollector", func() {
 			Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
 		})
 
+		It("returns a metadata_cache_hits_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(totalMetadataCacheHitsMetric)))
+		})
+
+		It("returns a metadata_cache_misses_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(totalMetadataCacheMissesMetric)))
+		})
+
+		It("returns a scrape_truncated metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(scrapeTruncatedMetric)))
+		})
+
+		Context("when the deployments exceed the maximum number of instances", func() {
+			BeforeEach(func() {
+				deploymentsFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+					DeploymentsFilter:    *deploymentsFilter,
+					InstanceGroupsFilter: *instanceGroupsFilter,
+					AZsFilter:            *filters.NewAZsFilter([]string{}),
+					RetryAttempts:        1,
+					MaxInstances:         1,
+				})
+				boshClient.DeploymentsReturns([]director.Deployment{
+					&directorfakes.FakeDeployment{
+						NameStub: func() string { return "fake-deployment-name" },
+						InstanceInfosStub: func() ([]director.VMInfo, error) {
+							return []director.VMInfo{
+								{JobName: "fake-job-name", VMID: "fake-vm-id-1"},
+								{JobName: "fake-job-name", VMID: "fake-vm-id-2"},
+							}, nil
+						},
+					},
+				}, nil)
+
+				totalBoshScrapeErrorsMetric.Inc()
+				lastBoshScrapeErrorMetric.Set(float64(1))
+				scrapeTruncatedMetric.Set(float64(1))
+			})
+
+			It("returns a scrape_truncated metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(scrapeTruncatedMetric)))
+			})
+
+			It("returns a last_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
+			})
+		})
+
+		Context("when the metadata cache is enabled", func() {
+			BeforeEach(func() {
+				deploymentsFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+					DeploymentsFilter:    *deploymentsFilter,
+					InstanceGroupsFilter: *instanceGroupsFilter,
+					AZsFilter:            *filters.NewAZsFilter([]string{}),
+					MetadataCacheTTL:     time.Hour,
+					RetryAttempts:        1,
+				})
+				boshClient.DeploymentsReturns([]director.Deployment{
+					&directorfakes.FakeDeployment{
+						NameStub: func() string { return "fake-deployment-name" },
+					},
+				}, nil)
+
+				totalMetadataCacheMissesMetric.Inc()
+			})
+
+			It("returns a metadata_cache_hits_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(totalMetadataCacheHitsMetric)))
+			})
+
+			It("returns a metadata_cache_misses_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(totalMetadataCacheMissesMetric)))
+			})
+		})
+
 		Context("when it fails to get the deployment", func() {
 			BeforeEach(func() {
 				boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("no deployments"))
@@ -240,6 +466,98 @@ var _ = Describe("BoshCollector", func() {
 			It("returns a last_scrape_error metric", func() {
 				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
 			})
+
+			It("does not set the last_scrape_timestamp_seconds metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshSuccessfulScrapeTimestampMetric)))
+			})
+		})
+
+		Context("when it fails to get some deployments and continue on error is enabled", func() {
+			BeforeEach(func() {
+				deploymentsFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+					DeploymentsFilter:    *deploymentsFilter,
+					InstanceGroupsFilter: *instanceGroupsFilter,
+					AZsFilter:            *filters.NewAZsFilter([]string{}),
+					ContinueOnError:      true,
+					RetryAttempts:        1,
+				})
+				boshClient.DeploymentsReturns([]director.Deployment{
+					&directorfakes.FakeDeployment{
+						NameStub: func() string { return "fake-deployment-name" },
+					},
+					&directorfakes.FakeDeployment{
+						NameStub:          func() string { return "fake-failing-deployment-name" },
+						InstanceInfosStub: func() ([]director.VMInfo, error) { return nil, errors.New("no instances") },
+					},
+				}, nil)
+
+				totalBoshScrapeErrorsMetric.Inc()
+				lastBoshScrapeErrorMetric.Set(float64(1))
+			})
+
+			It("returns a scrape_errors_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(totalBoshScrapeErrorsMetric)))
+			})
+
+			It("returns a last_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
+			})
+
+			It("writes the fetched deployments to the service discovery file", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
+				Expect(os.ReadFile(serviceDiscoveryFilename)).To(Equal([]byte("[]")))
+			})
+		})
+
+		Context("when a collector fails", func() {
+			BeforeEach(func() {
+				serviceDiscoveryFilename = "/non-existent-directory/bosh_target_groups.json"
+
+				totalBoshScrapeErrorsMetric.Inc()
+				lastBoshScrapeErrorMetric.Set(float64(1))
+			})
+
+			It("returns a scrape_errors_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(totalBoshScrapeErrorsMetric)))
+			})
+
+			It("returns a last_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
+			})
+		})
+	})
+
+	Describe("Collect when several collectors fail", func() {
+		var (
+			metrics    chan prometheus.Metric
+			goroutines int
+		)
+
+		BeforeEach(func() {
+			serviceDiscoveryFilename = "/non-existent-directory/bosh_target_groups.json"
+			boshClient.ListConfigsReturns(nil, errors.New("no configs"))
+			vmTypesFetcher = vmtypes.NewFetcher(boshClient)
+
+			metrics = make(chan prometheus.Metric, 1000)
+			goroutines = runtime.NumGoroutine()
+		})
+
+		It("returns without leaving any collector blocked", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				boshCollector.Collect(metrics)
+			}()
+
+			Eventually(done, 5*time.Second).Should(BeClosed())
+			Eventually(runtime.NumGoroutine, 5*time.Second).Should(BeNumerically("<=", goroutines))
+		})
+
+		It("returns a last_scrape_error metric", func() {
+			lastBoshScrapeErrorMetric.Set(float64(1))
+
+			boshCollector.Collect(metrics)
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastBoshScrapeErrorMetric)))
 		})
 	})
 })
@@ -0,0 +1,139 @@
+package collectors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/log"
+)
+
+type CachedCollector struct {
+	collector             prometheus.Collector
+	interval              time.Duration
+	mutex                 sync.RWMutex
+	metrics               []prometheus.Metric
+	refreshedAt           time.Time
+	cacheAgeSecondsMetric prometheus.Gauge
+}
+
+// NewCachedCollector returns a CachedCollector that serves the last snapshot
+// of the metrics of collector, so scrapes do not wait for BOSH. The snapshot
+// is only taken by Refresh and Run.
+func NewCachedCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	collector prometheus.Collector,
+	interval time.Duration,
+) *CachedCollector {
+	cacheAgeSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "cache_age_seconds",
+			Help:      "Number of seconds since the metrics served from BOSH were fetched in the background.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	return &CachedCollector{
+		collector:             collector,
+		interval:              interval,
+		cacheAgeSecondsMetric: cacheAgeSecondsMetric,
+	}
+}
+
+// Refresh collects the metrics once and replaces the snapshot.
+func (c *CachedCollector) Refresh() {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.collector.Collect(ch)
+		close(ch)
+	}()
+
+	metrics := []prometheus.Metric{}
+	for metric := range ch {
+		snapshot := &dto.Metric{}
+		if err := metric.Write(snapshot); err != nil {
+			log.Errorf("Error while caching metric `%s`: %v", metric.Desc(), err)
+			continue
+		}
+		metrics = append(metrics, &cachedMetric{desc: metric.Desc(), metric: snapshot})
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.metrics = metrics
+	c.refreshedAt = time.Now()
+}
+
+// Run refreshes the snapshot every interval until ctx is done.
+func (c *CachedCollector) Run(ctx context.Context) {
+	log.Infof("Fetching metrics from BOSH in the background every %s", c.interval)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.Refresh()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *CachedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	c.cacheAgeSecondsMetric.Describe(ch)
+}
+
+func (c *CachedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	// Nothing is served until the first background fetch completes.
+	if c.refreshedAt.IsZero() {
+		return
+	}
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+
+	c.cacheAgeSecondsMetric.Set(time.Since(c.refreshedAt).Seconds())
+	c.cacheAgeSecondsMetric.Collect(ch)
+}
+
+// cachedMetric is a metric frozen at the time of the snapshot, so later
+// collections do not change the values being served.
+type cachedMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+func (m *cachedMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m *cachedMetric) Write(out *dto.Metric) error {
+	out.Label = m.metric.Label
+	out.Gauge = m.metric.Gauge
+	out.Counter = m.metric.Counter
+	out.Summary = m.metric.Summary
+	out.Untyped = m.metric.Untyped
+	out.Histogram = m.metric.Histogram
+	out.TimestampMs = m.metric.TimestampMs
+
+	return nil
+}
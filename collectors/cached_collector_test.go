@@ -0,0 +1,162 @@
+package collectors_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("CachedCollector", func() {
+	var (
+		namespace       string
+		environment     string
+		boshName        string
+		boshUUID        string
+		cachedCollector *CachedCollector
+
+		fakeMetric            prometheus.Gauge
+		cachedFakeMetric      prometheus.Gauge
+		cacheAgeSecondsMetric prometheus.Gauge
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+
+		fakeMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "fake_metric",
+				Help:      "Fake metric.",
+			},
+		)
+		fakeMetric.Set(1)
+
+		cachedFakeMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "fake_metric",
+				Help:      "Fake metric.",
+			},
+		)
+		cachedFakeMetric.Set(1)
+
+		cacheAgeSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "cache_age_seconds",
+				Help:      "Number of seconds since the metrics served from BOSH were fetched in the background.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		cachedCollector = NewCachedCollector(namespace, environment, boshName, boshUUID, fakeMetric, 0)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go cachedCollector.Describe(descriptions)
+		})
+
+		It("returns the metric descriptions of the collector", func() {
+			Eventually(descriptions).Should(Receive(Equal(fakeMetric.Desc())))
+		})
+
+		It("returns a cache_age_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(cacheAgeSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			metrics = make(chan prometheus.Metric)
+		})
+
+		Context("when the metrics have not been fetched yet", func() {
+			JustBeforeEach(func() {
+				go cachedCollector.Collect(metrics)
+			})
+
+			It("does not return any metric", func() {
+				Consistently(metrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the metrics have been fetched", func() {
+			JustBeforeEach(func() {
+				cachedCollector.Refresh()
+				fakeMetric.Set(2)
+				go cachedCollector.Collect(metrics)
+			})
+
+			It("returns the metrics of the last fetch", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(cachedFakeMetric)))
+			})
+
+			It("returns a cache_age_seconds metric", func() {
+				Eventually(metrics).Should(Receive(WithTransform(func(metric prometheus.Metric) string {
+					return metric.Desc().String()
+				}, Equal(cacheAgeSecondsMetric.Desc().String()))))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		var (
+			ctx      context.Context
+			cancel   context.CancelFunc
+			returned chan struct{}
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			returned = make(chan struct{})
+		})
+
+		JustBeforeEach(func() {
+			cachedCollector = NewCachedCollector(namespace, environment, boshName, boshUUID, fakeMetric, time.Hour)
+			go func(cachedCollector *CachedCollector, ctx context.Context, returned chan struct{}) {
+				defer close(returned)
+				cachedCollector.Run(ctx)
+			}(cachedCollector, ctx, returned)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("returns once the context is done", func() {
+			Consistently(returned).ShouldNot(BeClosed())
+			cancel()
+			Eventually(returned).Should(BeClosed())
+		})
+	})
+})
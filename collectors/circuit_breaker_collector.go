@@ -0,0 +1,124 @@
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type CircuitBreakerCollector struct {
+	collector                prometheus.Collector
+	circuitBreaker           *deployments.CircuitBreaker
+	directorUpMetric         prometheus.Gauge
+	circuitBreakerOpenMetric prometheus.Gauge
+}
+
+// NewCircuitBreakerCollector returns a CircuitBreakerCollector that collects
+// the metrics of collector, reading its deployments through circuitBreaker,
+// and then reports the state of circuitBreaker as of that collection.
+func NewCircuitBreakerCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	collector prometheus.Collector,
+	circuitBreaker *deployments.CircuitBreaker,
+) *CircuitBreakerCollector {
+	directorUpMetric := NewDirectorUpMetric(namespace, environment, boshName, boshUUID)
+
+	circuitBreakerOpenMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "director",
+			Name:      "circuit_breaker_open",
+			Help:      "Whether scrapes of the BOSH Director are skipped after consecutive failures (1 for open, 0 for closed).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	return &CircuitBreakerCollector{
+		collector:                collector,
+		circuitBreaker:           circuitBreaker,
+		directorUpMetric:         directorUpMetric,
+		circuitBreakerOpenMetric: circuitBreakerOpenMetric,
+	}
+}
+
+// NewDirectorUpMetric returns the gauge reporting whether the deployments
+// could be read from the BOSH Director. It is also used on its own to report
+// a director that could not be set up as down.
+func NewDirectorUpMetric(namespace string, environment string, boshName string, boshUUID string) prometheus.Gauge {
+	return prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "director",
+			Name:      "up",
+			Help:      "Whether the last scrape read the deployments from the BOSH Director (1 for up, 0 for down).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+}
+
+func (c *CircuitBreakerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collector.Collect(ch)
+
+	var upMetric, openMetric float64
+	up, open := c.circuitBreaker.State()
+	if up {
+		upMetric = 1
+	}
+	if open {
+		openMetric = 1
+	}
+
+	c.directorUpMetric.Set(upMetric)
+	c.directorUpMetric.Collect(ch)
+
+	c.circuitBreakerOpenMetric.Set(openMetric)
+	c.circuitBreakerOpenMetric.Collect(ch)
+}
+
+func (c *CircuitBreakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	c.directorUpMetric.Describe(ch)
+	c.circuitBreakerOpenMetric.Describe(ch)
+}
+
+type CircuitBreakerGateCollector struct {
+	collector      prometheus.Collector
+	circuitBreaker *deployments.CircuitBreaker
+}
+
+// NewCircuitBreakerGateCollector returns a CircuitBreakerGateCollector that
+// collects the metrics of collector only while circuitBreaker is closed, so
+// the collectors calling the BOSH Director on their own do not call it either
+// while its deployments scrapes are skipped.
+func NewCircuitBreakerGateCollector(
+	collector prometheus.Collector,
+	circuitBreaker *deployments.CircuitBreaker,
+) *CircuitBreakerGateCollector {
+	return &CircuitBreakerGateCollector{
+		collector:      collector,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (c *CircuitBreakerGateCollector) Collect(ch chan<- prometheus.Metric) {
+	if !c.circuitBreaker.Closed() {
+		return
+	}
+
+	c.collector.Collect(ch)
+}
+
+func (c *CircuitBreakerGateCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
@@ -0,0 +1,252 @@
+package collectors_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("CircuitBreakerCollector", func() {
+	var (
+		namespace               string
+		environment             string
+		boshName                string
+		boshUUID                string
+		threshold               int
+		failures                int
+		circuitBreaker          *deployments.CircuitBreaker
+		circuitBreakerCollector *CircuitBreakerCollector
+
+		fakeMetric               prometheus.Gauge
+		directorUpMetric         prometheus.Gauge
+		circuitBreakerOpenMetric prometheus.Gauge
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		threshold = 2
+		failures = 0
+
+		fakeMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "fake_metric",
+				Help:      "Fake metric.",
+			},
+		)
+		fakeMetric.Set(1)
+
+		directorUpMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "director",
+				Name:      "up",
+				Help:      "Whether the last scrape read the deployments from the BOSH Director (1 for up, 0 for down).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		circuitBreakerOpenMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "director",
+				Name:      "circuit_breaker_open",
+				Help:      "Whether scrapes of the BOSH Director are skipped after consecutive failures (1 for open, 0 for closed).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		// Reading a missing deployments file fails without any deployment.
+		circuitBreaker = deployments.NewCircuitBreaker(deployments.NewFileFetcher("/nonexistent/deployments.json"), threshold, time.Hour)
+		for i := 0; i < failures; i++ {
+			circuitBreaker.Deployments()
+		}
+
+		circuitBreakerCollector = NewCircuitBreakerCollector(namespace, environment, boshName, boshUUID, fakeMetric, circuitBreaker)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go circuitBreakerCollector.Describe(descriptions)
+		})
+
+		It("returns the metric descriptions of the collector", func() {
+			Eventually(descriptions).Should(Receive(Equal(fakeMetric.Desc())))
+		})
+
+		It("returns a director_up metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(directorUpMetric.Desc())))
+		})
+
+		It("returns a director_circuit_breaker_open metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(circuitBreakerOpenMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go circuitBreakerCollector.Collect(metrics)
+		})
+
+		It("returns the metrics of the collector", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(fakeMetric)))
+		})
+
+		It("returns an up director_up metric", func() {
+			directorUpMetric.Set(float64(1))
+			Eventually(metrics).Should(Receive(PrometheusMetric(directorUpMetric)))
+		})
+
+		It("returns a closed director_circuit_breaker_open metric", func() {
+			circuitBreakerOpenMetric.Set(float64(0))
+			Eventually(metrics).Should(Receive(PrometheusMetric(circuitBreakerOpenMetric)))
+		})
+
+		Context("when the last scrape failed", func() {
+			BeforeEach(func() {
+				failures = 1
+			})
+
+			It("returns a down director_up metric", func() {
+				directorUpMetric.Set(float64(0))
+				Eventually(metrics).Should(Receive(PrometheusMetric(directorUpMetric)))
+			})
+
+			It("returns a closed director_circuit_breaker_open metric", func() {
+				circuitBreakerOpenMetric.Set(float64(0))
+				Eventually(metrics).Should(Receive(PrometheusMetric(circuitBreakerOpenMetric)))
+			})
+		})
+
+		Context("when the circuit breaker is open", func() {
+			BeforeEach(func() {
+				failures = threshold
+			})
+
+			It("returns a down director_up metric", func() {
+				directorUpMetric.Set(float64(0))
+				Eventually(metrics).Should(Receive(PrometheusMetric(directorUpMetric)))
+			})
+
+			It("returns an open director_circuit_breaker_open metric", func() {
+				circuitBreakerOpenMetric.Set(float64(1))
+				Eventually(metrics).Should(Receive(PrometheusMetric(circuitBreakerOpenMetric)))
+			})
+		})
+	})
+})
+
+var _ = Describe("CircuitBreakerGateCollector", func() {
+	var (
+		failures                    int
+		fakeMetric                  prometheus.Gauge
+		circuitBreaker              *deployments.CircuitBreaker
+		circuitBreakerGateCollector *CircuitBreakerGateCollector
+	)
+
+	BeforeEach(func() {
+		failures = 0
+
+		fakeMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "test_exporter",
+				Name:      "fake_metric",
+				Help:      "Fake metric.",
+			},
+		)
+		fakeMetric.Set(1)
+	})
+
+	JustBeforeEach(func() {
+		// Reading a missing deployments file fails without any deployment.
+		circuitBreaker = deployments.NewCircuitBreaker(deployments.NewFileFetcher("/nonexistent/deployments.json"), 2, time.Hour)
+		for i := 0; i < failures; i++ {
+			circuitBreaker.Deployments()
+		}
+
+		circuitBreakerGateCollector = NewCircuitBreakerGateCollector(fakeMetric, circuitBreaker)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go circuitBreakerGateCollector.Describe(descriptions)
+		})
+
+		It("returns the metric descriptions of the collector", func() {
+			Eventually(descriptions).Should(Receive(Equal(fakeMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go circuitBreakerGateCollector.Collect(metrics)
+		})
+
+		It("returns the metrics of the collector", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(fakeMetric)))
+		})
+
+		Context("when the circuit breaker is open", func() {
+			BeforeEach(func() {
+				failures = 2
+			})
+
+			It("does not return the metrics of the collector", func() {
+				Consistently(metrics).ShouldNot(Receive())
+			})
+		})
+	})
+})
@@ -0,0 +1,151 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/configs"
+)
+
+type ConfigsCollector struct {
+	configsFetcher                         *configs.Fetcher
+	cloudConfigVersionMetric               *prometheus.GaugeVec
+	runtimeConfigVersionMetric             *prometheus.GaugeVec
+	lastConfigsScrapeErrorMetric           prometheus.Gauge
+	lastConfigsScrapeTimestampMetric       prometheus.Gauge
+	lastConfigsScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewConfigsCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	configsFetcher *configs.Fetcher,
+) *ConfigsCollector {
+	cloudConfigVersionMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "cloud_config_version",
+			Help:      "ID of the latest BOSH Cloud Config.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_config_name"},
+	)
+
+	runtimeConfigVersionMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "runtime_config_version",
+			Help:      "ID of the latest BOSH Runtime Config.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_config_name"},
+	)
+
+	lastConfigsScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_configs_scrape_error",
+			Help:      "Whether the last scrape of Config metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastConfigsScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_configs_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Config metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastConfigsScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_configs_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Config metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &ConfigsCollector{
+		configsFetcher:                         configsFetcher,
+		cloudConfigVersionMetric:               cloudConfigVersionMetric,
+		runtimeConfigVersionMetric:             runtimeConfigVersionMetric,
+		lastConfigsScrapeErrorMetric:           lastConfigsScrapeErrorMetric,
+		lastConfigsScrapeTimestampMetric:       lastConfigsScrapeTimestampMetric,
+		lastConfigsScrapeDurationSecondsMetric: lastConfigsScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *ConfigsCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	c.cloudConfigVersionMetric.Reset()
+	c.runtimeConfigVersionMetric.Reset()
+
+	configsInfo, err := c.configsFetcher.Configs()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, config := range configsInfo {
+		switch config.Type {
+		case configs.CloudConfigType:
+			c.cloudConfigVersionMetric.WithLabelValues(config.Name).Set(float64(config.ID))
+		case configs.RuntimeConfigType:
+			c.runtimeConfigVersionMetric.WithLabelValues(config.Name).Set(float64(config.ID))
+		}
+	}
+	c.cloudConfigVersionMetric.Collect(ch)
+	c.runtimeConfigVersionMetric.Collect(ch)
+
+	c.lastConfigsScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastConfigsScrapeErrorMetric.Collect(ch)
+
+	c.lastConfigsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastConfigsScrapeTimestampMetric.Collect(ch)
+
+	c.lastConfigsScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastConfigsScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *ConfigsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.cloudConfigVersionMetric.Describe(ch)
+	c.runtimeConfigVersionMetric.Describe(ch)
+	c.lastConfigsScrapeErrorMetric.Describe(ch)
+	c.lastConfigsScrapeTimestampMetric.Describe(ch)
+	c.lastConfigsScrapeDurationSecondsMetric.Describe(ch)
+}
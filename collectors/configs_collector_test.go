@@ -0,0 +1,213 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/configs"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("ConfigsCollector", func() {
+	var (
+		namespace        string
+		environment      string
+		boshName         string
+		boshUUID         string
+		boshClient       *directorfakes.FakeDirector
+		configsFetcher   *configs.Fetcher
+		configsCollector *ConfigsCollector
+
+		cloudConfigVersionMetric               *prometheus.GaugeVec
+		runtimeConfigVersionMetric             *prometheus.GaugeVec
+		lastConfigsScrapeErrorMetric           prometheus.Gauge
+		lastConfigsScrapeTimestampMetric       prometheus.Gauge
+		lastConfigsScrapeDurationSecondsMetric prometheus.Gauge
+
+		configName = "fake-config-name"
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		cloudConfigVersionMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "cloud_config_version",
+				Help:      "ID of the latest BOSH Cloud Config.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_config_name"},
+		)
+
+		runtimeConfigVersionMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "runtime_config_version",
+				Help:      "ID of the latest BOSH Runtime Config.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_config_name"},
+		)
+
+		lastConfigsScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_configs_scrape_error",
+				Help:      "Whether the last scrape of Config metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastConfigsScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_configs_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Config metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastConfigsScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_configs_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Config metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		configsFetcher = configs.NewFetcher(boshClient)
+		configsCollector = NewConfigsCollector(namespace, environment, boshName, boshUUID, configsFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go configsCollector.Describe(descriptions)
+		})
+
+		It("returns a cloud_config_version metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(cloudConfigVersionMetric.WithLabelValues(configName).Desc())))
+		})
+
+		It("returns a runtime_config_version metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(runtimeConfigVersionMetric.WithLabelValues(configName).Desc())))
+		})
+
+		It("returns a last_configs_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastConfigsScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_configs_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastConfigsScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_configs_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastConfigsScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.ListConfigsStub = func(limit int, filter director.ConfigsFilter) ([]director.Config, error) {
+				switch filter.Type {
+				case "cloud":
+					return []director.Config{{ID: "3", Name: configName, Type: "cloud"}}, nil
+				case "runtime":
+					return []director.Config{{ID: "5", Name: configName, Type: "runtime"}}, nil
+				}
+				return []director.Config{}, nil
+			}
+
+			cloudConfigVersionMetric.WithLabelValues(configName).Set(3)
+			runtimeConfigVersionMetric.WithLabelValues(configName).Set(5)
+			lastConfigsScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go configsCollector.Collect(metrics)
+		})
+
+		It("returns a cloud_config_version metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(cloudConfigVersionMetric.WithLabelValues(configName))))
+		})
+
+		It("returns a runtime_config_version metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(runtimeConfigVersionMetric.WithLabelValues(configName))))
+		})
+
+		It("returns a last_configs_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastConfigsScrapeErrorMetric)))
+		})
+
+		Context("when reading the configs fails", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsStub = nil
+				boshClient.ListConfigsReturns([]director.Config{}, errors.New("no configs"))
+
+				lastConfigsScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a cloud_config_version metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(cloudConfigVersionMetric.WithLabelValues(configName))))
+			})
+
+			It("returns a failed last_configs_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastConfigsScrapeErrorMetric)))
+			})
+		})
+	})
+})
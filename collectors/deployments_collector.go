@@ -1,17 +1,40 @@
 package collectors
 
 import (
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
 )
 
+var invalidLabelNameCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
 type DeploymentsCollector struct {
+	deprecatedStemcellsFilter                  *filters.DeprecatedFilter
+	deprecatedReleasesFilter                   *filters.DeprecatedFilter
+	tagKeys                                    []string
+	deploymentInfoMetric                       *prometheus.GaugeVec
 	deploymentReleaseInfoMetric                *prometheus.GaugeVec
 	deploymentStemcellInfoMetric               *prometheus.GaugeVec
+	deploymentReleasesTotalMetric              *prometheus.GaugeVec
+	deploymentStemcellsTotalMetric             *prometheus.GaugeVec
+	deploymentStemcellUpgradeAvailableMetric   *prometheus.GaugeVec
 	deploymentInstancesMetric                  *prometheus.GaugeVec
+	deploymentInstancesPerAZMetric             *prometheus.GaugeVec
+	deploymentInstancesHealthyMetric           *prometheus.GaugeVec
+	deploymentInstancesCountMetric             *prometheus.GaugeVec
+	deploymentInstancesHealthyRatioMetric      *prometheus.GaugeVec
+	deploymentInstanceDNSMetric                *prometheus.GaugeVec
+	deploymentErrandInfoMetric                 *prometheus.GaugeVec
+	deploymentErrandsMetric                    *prometheus.GaugeVec
+	deploymentStaleMetric                      *prometheus.GaugeVec
+	deploymentResurrectionPausedMetric         *prometheus.GaugeVec
+	releasesInUseMetric                        *prometheus.GaugeVec
+	stemcellsInUseMetric                       *prometheus.GaugeVec
 	lastDeploymentsScrapeTimestampMetric       prometheus.Gauge
 	lastDeploymentsScrapeDurationSecondsMetric prometheus.Gauge
 }
@@ -21,7 +44,40 @@ func NewDeploymentsCollector(
 	environment string,
 	boshName string,
 	boshUUID string,
+	deprecatedStemcellsFilter *filters.DeprecatedFilter,
+	deprecatedReleasesFilter *filters.DeprecatedFilter,
+	tagKeys []string,
 ) *DeploymentsCollector {
+	// Tag keys are sanitized into label names, so keys that end up with the
+	// same label name are only reported once.
+	deploymentInfoLabels := []string{"bosh_deployment"}
+	deploymentInfoTagKeys := []string{}
+	seenLabels := map[string]bool{}
+	for _, tagKey := range tagKeys {
+		label := "bosh_deployment_tag_" + invalidLabelNameCharsRegexp.ReplaceAllString(tagKey, "_")
+		if seenLabels[label] {
+			continue
+		}
+		seenLabels[label] = true
+		deploymentInfoLabels = append(deploymentInfoLabels, label)
+		deploymentInfoTagKeys = append(deploymentInfoTagKeys, tagKey)
+	}
+
+	deploymentInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "info",
+			Help:      "Labeled BOSH Deployment Info with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		deploymentInfoLabels,
+	)
+
 	deploymentReleaseInfoMetric := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -34,7 +90,7 @@ func NewDeploymentsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_release_name", "bosh_release_version"},
+		[]string{"bosh_deployment", "bosh_release_name", "bosh_release_version", "deprecated"},
 	)
 
 	deploymentStemcellInfoMetric := prometheus.NewGaugeVec(
@@ -49,6 +105,51 @@ func NewDeploymentsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
+		[]string{"bosh_deployment", "bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name", "bosh_stemcell_cpi", "bosh_stemcell_api_version", "deprecated"},
+	)
+
+	deploymentReleasesTotalMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "releases_total",
+			Help:      "Number of releases in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentStemcellsTotalMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "stemcells_total",
+			Help:      "Number of stemcells in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentStemcellUpgradeAvailableMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "stemcell_upgrade_available",
+			Help:      "Whether a newer stemcell version for the same OS is uploaded to BOSH (1 for available, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
 		[]string{"bosh_deployment", "bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name"},
 	)
 
@@ -67,6 +168,171 @@ func NewDeploymentsCollector(
 		[]string{"bosh_deployment", "bosh_vm_type"},
 	)
 
+	deploymentInstancesPerAZMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "instances_per_az",
+			Help:      "Number of instances in this deployment by availability zone.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_az"},
+	)
+
+	deploymentInstancesHealthyMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "instances_healthy",
+			Help:      "Number of healthy instances in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentInstancesCountMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "instances_count",
+			Help:      "Number of instances in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentInstancesHealthyRatioMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "instances_healthy_ratio",
+			Help:      "Ratio of healthy instances to all instances in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentInstanceDNSMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "instance_dns",
+			Help:      "Labeled BOSH Deployment Instance DNS address with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_dns"},
+	)
+
+	deploymentErrandInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "errand_info",
+			Help:      "Labeled BOSH Deployment Errand Info with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_errand_name"},
+	)
+
+	deploymentErrandsMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "errands",
+			Help:      "Number of errands in this deployment.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentStaleMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "stale",
+			Help:      "Whether any release or stemcell of this deployment is older than the newest version uploaded to BOSH (1 for stale, 0 for up to date).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	deploymentResurrectionPausedMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "deployment",
+			Name:      "resurrection_paused",
+			Help:      "Whether the resurrection of any instance of this deployment is paused (1 for paused, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment"},
+	)
+
+	releasesInUseMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "releases_in_use",
+			Help:      "Labeled BOSH Release used by any deployment with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_release_name", "bosh_release_version"},
+	)
+
+	stemcellsInUseMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "stemcells_in_use",
+			Help:      "Labeled BOSH Stemcell used by any deployment with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name"},
+	)
+
 	lastDeploymentsScrapeTimestampMetric := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -96,9 +362,27 @@ func NewDeploymentsCollector(
 	)
 
 	collector := &DeploymentsCollector{
+		deprecatedStemcellsFilter:                  deprecatedStemcellsFilter,
+		deprecatedReleasesFilter:                   deprecatedReleasesFilter,
+		tagKeys:                                    deploymentInfoTagKeys,
+		deploymentInfoMetric:                       deploymentInfoMetric,
 		deploymentReleaseInfoMetric:                deploymentReleaseInfoMetric,
 		deploymentStemcellInfoMetric:               deploymentStemcellInfoMetric,
+		deploymentReleasesTotalMetric:              deploymentReleasesTotalMetric,
+		deploymentStemcellsTotalMetric:             deploymentStemcellsTotalMetric,
+		deploymentStemcellUpgradeAvailableMetric:   deploymentStemcellUpgradeAvailableMetric,
 		deploymentInstancesMetric:                  deploymentInstancesMetric,
+		deploymentInstancesPerAZMetric:             deploymentInstancesPerAZMetric,
+		deploymentInstancesHealthyMetric:           deploymentInstancesHealthyMetric,
+		deploymentInstancesCountMetric:             deploymentInstancesCountMetric,
+		deploymentInstancesHealthyRatioMetric:      deploymentInstancesHealthyRatioMetric,
+		deploymentInstanceDNSMetric:                deploymentInstanceDNSMetric,
+		deploymentErrandInfoMetric:                 deploymentErrandInfoMetric,
+		deploymentErrandsMetric:                    deploymentErrandsMetric,
+		deploymentStaleMetric:                      deploymentStaleMetric,
+		deploymentResurrectionPausedMetric:         deploymentResurrectionPausedMetric,
+		releasesInUseMetric:                        releasesInUseMetric,
+		stemcellsInUseMetric:                       stemcellsInUseMetric,
 		lastDeploymentsScrapeTimestampMetric:       lastDeploymentsScrapeTimestampMetric,
 		lastDeploymentsScrapeDurationSecondsMetric: lastDeploymentsScrapeDurationSecondsMetric,
 	}
@@ -108,19 +392,56 @@ func NewDeploymentsCollector(
 func (c *DeploymentsCollector) Collect(deployments []deployments.DeploymentInfo, ch chan<- prometheus.Metric) error {
 	var begun = time.Now()
 
+	c.deploymentInfoMetric.Reset()
 	c.deploymentReleaseInfoMetric.Reset()
 	c.deploymentStemcellInfoMetric.Reset()
+	c.deploymentReleasesTotalMetric.Reset()
+	c.deploymentStemcellsTotalMetric.Reset()
+	c.deploymentStemcellUpgradeAvailableMetric.Reset()
 	c.deploymentInstancesMetric.Reset()
+	c.deploymentInstancesPerAZMetric.Reset()
+	c.deploymentInstancesHealthyMetric.Reset()
+	c.deploymentInstancesCountMetric.Reset()
+	c.deploymentInstancesHealthyRatioMetric.Reset()
+	c.deploymentInstanceDNSMetric.Reset()
+	c.deploymentErrandInfoMetric.Reset()
+	c.deploymentErrandsMetric.Reset()
+	c.deploymentStaleMetric.Reset()
+	c.deploymentResurrectionPausedMetric.Reset()
+	c.releasesInUseMetric.Reset()
+	c.stemcellsInUseMetric.Reset()
 
 	for _, deployment := range deployments {
+		c.reportDeploymentInfoMetrics(deployment, ch)
 		c.reportDeploymentReleaseInfoMetrics(deployment, ch)
 		c.reportDeploymentStemcellInfoMetrics(deployment, ch)
 		c.reportDeploymentInstancesMetrics(deployment, ch)
+		c.reportDeploymentInstancesHealthMetrics(deployment, ch)
+		c.reportDeploymentInstanceDNSMetrics(deployment, ch)
+		c.reportDeploymentErrandsMetrics(deployment, ch)
+		c.reportDeploymentStaleMetrics(deployment, ch)
+		c.reportDeploymentResurrectionPausedMetrics(deployment, ch)
+		c.reportInUseMetrics(deployment, ch)
 	}
 
+	c.deploymentInfoMetric.Collect(ch)
 	c.deploymentReleaseInfoMetric.Collect(ch)
 	c.deploymentStemcellInfoMetric.Collect(ch)
+	c.deploymentReleasesTotalMetric.Collect(ch)
+	c.deploymentStemcellsTotalMetric.Collect(ch)
+	c.deploymentStemcellUpgradeAvailableMetric.Collect(ch)
 	c.deploymentInstancesMetric.Collect(ch)
+	c.deploymentInstancesPerAZMetric.Collect(ch)
+	c.deploymentInstancesHealthyMetric.Collect(ch)
+	c.deploymentInstancesCountMetric.Collect(ch)
+	c.deploymentInstancesHealthyRatioMetric.Collect(ch)
+	c.deploymentInstanceDNSMetric.Collect(ch)
+	c.deploymentErrandInfoMetric.Collect(ch)
+	c.deploymentErrandsMetric.Collect(ch)
+	c.deploymentStaleMetric.Collect(ch)
+	c.deploymentResurrectionPausedMetric.Collect(ch)
+	c.releasesInUseMetric.Collect(ch)
+	c.stemcellsInUseMetric.Collect(ch)
 
 	c.lastDeploymentsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
 	c.lastDeploymentsScrapeTimestampMetric.Collect(ch)
@@ -132,13 +453,44 @@ func (c *DeploymentsCollector) Collect(deployments []deployments.DeploymentInfo,
 }
 
 func (c *DeploymentsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.deploymentInfoMetric.Describe(ch)
 	c.deploymentReleaseInfoMetric.Describe(ch)
 	c.deploymentStemcellInfoMetric.Describe(ch)
+	c.deploymentReleasesTotalMetric.Describe(ch)
+	c.deploymentStemcellsTotalMetric.Describe(ch)
+	c.deploymentStemcellUpgradeAvailableMetric.Describe(ch)
 	c.deploymentInstancesMetric.Describe(ch)
+	c.deploymentInstancesPerAZMetric.Describe(ch)
+	c.deploymentInstancesHealthyMetric.Describe(ch)
+	c.deploymentInstancesCountMetric.Describe(ch)
+	c.deploymentInstancesHealthyRatioMetric.Describe(ch)
+	c.deploymentInstanceDNSMetric.Describe(ch)
+	c.deploymentErrandInfoMetric.Describe(ch)
+	c.deploymentErrandsMetric.Describe(ch)
+	c.deploymentStaleMetric.Describe(ch)
+	c.deploymentResurrectionPausedMetric.Describe(ch)
+	c.releasesInUseMetric.Describe(ch)
+	c.stemcellsInUseMetric.Describe(ch)
 	c.lastDeploymentsScrapeTimestampMetric.Describe(ch)
 	c.lastDeploymentsScrapeDurationSecondsMetric.Describe(ch)
 }
 
+func (c *DeploymentsCollector) reportDeploymentInfoMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	if len(deployment.Tags) == 0 {
+		return
+	}
+
+	labelValues := []string{deployment.Name}
+	for _, tagKey := range c.tagKeys {
+		labelValues = append(labelValues, deployment.Tags[tagKey])
+	}
+
+	c.deploymentInfoMetric.WithLabelValues(labelValues...).Set(float64(1))
+}
+
 func (c *DeploymentsCollector) reportDeploymentReleaseInfoMetrics(
 	deployment deployments.DeploymentInfo,
 	ch chan<- prometheus.Metric,
@@ -148,8 +500,11 @@ func (c *DeploymentsCollector) reportDeploymentReleaseInfoMetrics(
 			deployment.Name,
 			release.Name,
 			release.Version,
+			strconv.FormatBool(c.deprecatedReleasesFilter.Deprecated(release.Name, release.Version)),
 		).Set(float64(1))
 	}
+
+	c.deploymentReleasesTotalMetric.WithLabelValues(deployment.Name).Set(float64(len(deployment.Releases)))
 }
 
 func (c *DeploymentsCollector) reportDeploymentStemcellInfoMetrics(
@@ -162,8 +517,29 @@ func (c *DeploymentsCollector) reportDeploymentStemcellInfoMetrics(
 			stemcell.Name,
 			stemcell.Version,
 			stemcell.OSName,
+			stemcell.CPI,
+			stemcell.APIVersion,
+			strconv.FormatBool(c.deprecatedStemcellsFilter.Deprecated(stemcell.Name, stemcell.Version)),
 		).Set(float64(1))
+
+		if stemcell.UpgradeAvailable == nil {
+			continue
+		}
+
+		upgradeAvailable := 0
+		if *stemcell.UpgradeAvailable {
+			upgradeAvailable = 1
+		}
+
+		c.deploymentStemcellUpgradeAvailableMetric.WithLabelValues(
+			deployment.Name,
+			stemcell.Name,
+			stemcell.Version,
+			stemcell.OSName,
+		).Set(float64(upgradeAvailable))
 	}
+
+	c.deploymentStemcellsTotalMetric.WithLabelValues(deployment.Name).Set(float64(len(deployment.Stemcells)))
 }
 
 func (c *DeploymentsCollector) reportDeploymentInstancesMetrics(
@@ -175,5 +551,107 @@ func (c *DeploymentsCollector) reportDeploymentInstancesMetrics(
 			deployment.Name,
 			instance.VMType,
 		).Add(float64(1))
+
+		az := instance.AZ
+		if az == "" {
+			az = "unknown"
+		}
+		c.deploymentInstancesPerAZMetric.WithLabelValues(deployment.Name, az).Add(float64(1))
+	}
+}
+
+func (c *DeploymentsCollector) reportDeploymentInstancesHealthMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	healthyInstances := 0
+	for _, instance := range deployment.Instances {
+		if instance.Healthy {
+			healthyInstances++
+		}
+	}
+
+	c.deploymentInstancesHealthyMetric.WithLabelValues(deployment.Name).Set(float64(healthyInstances))
+	c.deploymentInstancesCountMetric.WithLabelValues(deployment.Name).Set(float64(len(deployment.Instances)))
+
+	if len(deployment.Instances) > 0 {
+		c.deploymentInstancesHealthyRatioMetric.WithLabelValues(deployment.Name).Set(float64(healthyInstances) / float64(len(deployment.Instances)))
+	}
+}
+
+func (c *DeploymentsCollector) reportDeploymentInstanceDNSMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	for _, instance := range deployment.Instances {
+		for _, dns := range instance.DNS {
+			c.deploymentInstanceDNSMetric.WithLabelValues(
+				deployment.Name,
+				instance.Name,
+				instance.ID,
+				instance.Index,
+				dns,
+			).Set(float64(1))
+		}
+	}
+}
+
+func (c *DeploymentsCollector) reportDeploymentErrandsMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	for _, errand := range deployment.Errands {
+		c.deploymentErrandInfoMetric.WithLabelValues(
+			deployment.Name,
+			errand.Name,
+		).Set(float64(1))
+	}
+
+	c.deploymentErrandsMetric.WithLabelValues(deployment.Name).Set(float64(len(deployment.Errands)))
+}
+
+func (c *DeploymentsCollector) reportDeploymentStaleMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	if deployment.Stale == nil {
+		return
+	}
+
+	stale := 0
+	if *deployment.Stale {
+		stale = 1
+	}
+
+	c.deploymentStaleMetric.WithLabelValues(deployment.Name).Set(float64(stale))
+}
+
+func (c *DeploymentsCollector) reportDeploymentResurrectionPausedMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	resurrectionPaused := 0
+	for _, instance := range deployment.Instances {
+		if instance.ResurrectionPaused {
+			resurrectionPaused = 1
+			break
+		}
+	}
+
+	c.deploymentResurrectionPausedMetric.WithLabelValues(deployment.Name).Set(float64(resurrectionPaused))
+}
+
+// reportInUseMetrics reports each release and stemcell once, no matter how
+// many deployments use it.
+func (c *DeploymentsCollector) reportInUseMetrics(
+	deployment deployments.DeploymentInfo,
+	ch chan<- prometheus.Metric,
+) {
+	for _, release := range deployment.Releases {
+		c.releasesInUseMetric.WithLabelValues(release.Name, release.Version).Set(float64(1))
+	}
+
+	for _, stemcell := range deployment.Stemcells {
+		c.stemcellsInUseMetric.WithLabelValues(stemcell.Name, stemcell.Version, stemcell.OSName).Set(float64(1))
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/prometheus/common/log"
 
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
 
 	. "github.com/bosh-prometheus/bosh_exporter/collectors"
 	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
@@ -19,27 +20,56 @@ func init() {
 
 var _ = Describe("DeploymentsCollector", func() {
 	var (
-		namespace            string
-		environment          string
-		boshName             string
-		boshUUID             string
-		deploymentsCollector *DeploymentsCollector
+		namespace                 string
+		environment               string
+		boshName                  string
+		boshUUID                  string
+		deprecatedStemcellsFilter *filters.DeprecatedFilter
+		deprecatedReleasesFilter  *filters.DeprecatedFilter
+		tagKeys                   []string
+		deploymentsCollector      *DeploymentsCollector
 
+		deploymentInfoMetric                       *prometheus.GaugeVec
 		deploymentReleaseInfoMetric                *prometheus.GaugeVec
 		deploymentStemcellInfoMetric               *prometheus.GaugeVec
+		deploymentReleasesTotalMetric              *prometheus.GaugeVec
+		deploymentStemcellsTotalMetric             *prometheus.GaugeVec
+		deploymentStemcellUpgradeAvailableMetric   *prometheus.GaugeVec
 		deploymentInstancesMetric                  *prometheus.GaugeVec
+		deploymentInstancesPerAZMetric             *prometheus.GaugeVec
+		deploymentInstancesHealthyMetric           *prometheus.GaugeVec
+		deploymentInstancesCountMetric             *prometheus.GaugeVec
+		deploymentInstancesHealthyRatioMetric      *prometheus.GaugeVec
+		deploymentInstanceDNSMetric                *prometheus.GaugeVec
+		deploymentErrandInfoMetric                 *prometheus.GaugeVec
+		deploymentErrandsMetric                    *prometheus.GaugeVec
+		deploymentStaleMetric                      *prometheus.GaugeVec
+		deploymentResurrectionPausedMetric         *prometheus.GaugeVec
+		releasesInUseMetric                        *prometheus.GaugeVec
+		stemcellsInUseMetric                       *prometheus.GaugeVec
 		lastDeploymentsScrapeTimestampMetric       prometheus.Gauge
 		lastDeploymentsScrapeDurationSecondsMetric prometheus.Gauge
 
-		deploymentName  = "fake-deployment-name"
-		releaseName     = "fake-release-name"
-		releaseVersion  = "1.2.3"
-		stemcellName    = "fake-stemcell-name"
-		stemcellVersion = "4.5.6"
-		stemcellOSName  = "fake-stemcell-os-name"
-		vmTypeSmall     = "fake-vm-type-small"
-		vmTypeMedium    = "fake-vm-type-medium"
-		vmTypeLarge     = "fake-vm-type-large"
+		deploymentName     = "fake-deployment-name"
+		releaseName        = "fake-release-name"
+		releaseVersion     = "1.2.3"
+		stemcellName       = "fake-stemcell-name"
+		stemcellVersion    = "4.5.6"
+		stemcellOSName     = "fake-stemcell-os-name"
+		stemcellCPI        = "fake-stemcell-cpi"
+		stemcellAPIVersion = "2"
+		vmTypeSmall        = "fake-vm-type-small"
+		vmTypeMedium       = "fake-vm-type-medium"
+		vmTypeLarge        = "fake-vm-type-large"
+		azZ1               = "fake-az-z1"
+		azZ2               = "fake-az-z2"
+		jobName            = "fake-job-name"
+		jobID              = "fake-job-id"
+		jobIndex           = "0"
+		jobDNS             = "fake-job-id.fake-job-name.default.fake-deployment-name.bosh"
+		errandName         = "fake-errand-name"
+		tagTeam            = "fake-team"
+		tagCostCenter      = "fake-cost-center"
 	)
 
 	BeforeEach(func() {
@@ -47,6 +77,30 @@ var _ = Describe("DeploymentsCollector", func() {
 		environment = "test_environment"
 		boshName = "test_bosh_name"
 		boshUUID = "test_bosh_uuid"
+		deprecatedStemcellsFilter, _ = filters.NewDeprecatedFilter([]string{})
+		deprecatedReleasesFilter, _ = filters.NewDeprecatedFilter([]string{})
+		tagKeys = []string{"team", "cost-center"}
+
+		deploymentInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "info",
+				Help:      "Labeled BOSH Deployment Info with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_deployment_tag_team", "bosh_deployment_tag_cost_center"},
+		)
+
+		deploymentInfoMetric.WithLabelValues(
+			deploymentName,
+			tagTeam,
+			tagCostCenter,
+		).Set(float64(1))
 
 		deploymentReleaseInfoMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -60,13 +114,14 @@ var _ = Describe("DeploymentsCollector", func() {
 					"bosh_uuid":   boshUUID,
 				},
 			},
-			[]string{"bosh_deployment", "bosh_release_name", "bosh_release_version"},
+			[]string{"bosh_deployment", "bosh_release_name", "bosh_release_version", "deprecated"},
 		)
 
 		deploymentReleaseInfoMetric.WithLabelValues(
 			deploymentName,
 			releaseName,
 			releaseVersion,
+			"false",
 		).Set(float64(1))
 
 		deploymentStemcellInfoMetric = prometheus.NewGaugeVec(
@@ -81,7 +136,7 @@ var _ = Describe("DeploymentsCollector", func() {
 					"bosh_uuid":   boshUUID,
 				},
 			},
-			[]string{"bosh_deployment", "bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name"},
+			[]string{"bosh_deployment", "bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name", "bosh_stemcell_cpi", "bosh_stemcell_api_version", "deprecated"},
 		)
 
 		deploymentStemcellInfoMetric.WithLabelValues(
@@ -89,8 +144,62 @@ var _ = Describe("DeploymentsCollector", func() {
 			stemcellName,
 			stemcellVersion,
 			stemcellOSName,
+			stemcellCPI,
+			stemcellAPIVersion,
+			"false",
 		).Set(float64(1))
 
+		deploymentReleasesTotalMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "releases_total",
+				Help:      "Number of releases in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentReleasesTotalMetric.WithLabelValues(deploymentName).Set(float64(1))
+
+		deploymentStemcellsTotalMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "stemcells_total",
+				Help:      "Number of stemcells in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentStemcellsTotalMetric.WithLabelValues(deploymentName).Set(float64(1))
+
+		deploymentStemcellUpgradeAvailableMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "stemcell_upgrade_available",
+				Help:      "Whether a newer stemcell version for the same OS is uploaded to BOSH (1 for available, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name"},
+		)
+
+		deploymentStemcellUpgradeAvailableMetric.WithLabelValues(deploymentName, stemcellName, stemcellVersion, stemcellOSName).Set(float64(0))
+
 		deploymentInstancesMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -119,6 +228,199 @@ var _ = Describe("DeploymentsCollector", func() {
 			vmTypeLarge,
 		).Set(float64(3))
 
+		deploymentInstancesPerAZMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "instances_per_az",
+				Help:      "Number of instances in this deployment by availability zone.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_az"},
+		)
+
+		deploymentInstancesPerAZMetric.WithLabelValues(deploymentName, "unknown").Set(float64(6))
+
+		deploymentInstancesHealthyMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "instances_healthy",
+				Help:      "Number of healthy instances in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentInstancesHealthyMetric.WithLabelValues(deploymentName).Set(float64(4))
+
+		deploymentInstancesCountMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "instances_count",
+				Help:      "Number of instances in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentInstancesCountMetric.WithLabelValues(deploymentName).Set(float64(6))
+
+		deploymentInstancesHealthyRatioMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "instances_healthy_ratio",
+				Help:      "Ratio of healthy instances to all instances in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentInstancesHealthyRatioMetric.WithLabelValues(deploymentName).Set(float64(4) / float64(6))
+
+		deploymentInstanceDNSMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "instance_dns",
+				Help:      "Labeled BOSH Deployment Instance DNS address with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_dns"},
+		)
+
+		deploymentInstanceDNSMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobDNS,
+		).Set(float64(1))
+
+		deploymentErrandInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "errand_info",
+				Help:      "Labeled BOSH Deployment Errand Info with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_errand_name"},
+		)
+
+		deploymentErrandInfoMetric.WithLabelValues(deploymentName, errandName).Set(float64(1))
+
+		deploymentErrandsMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "errands",
+				Help:      "Number of errands in this deployment.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentErrandsMetric.WithLabelValues(deploymentName).Set(float64(1))
+
+		deploymentStaleMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "stale",
+				Help:      "Whether any release or stemcell of this deployment is older than the newest version uploaded to BOSH (1 for stale, 0 for up to date).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentStaleMetric.WithLabelValues(deploymentName).Set(float64(0))
+
+		deploymentResurrectionPausedMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "deployment",
+				Name:      "resurrection_paused",
+				Help:      "Whether the resurrection of any instance of this deployment is paused (1 for paused, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment"},
+		)
+
+		deploymentResurrectionPausedMetric.WithLabelValues(deploymentName).Set(float64(0))
+
+		releasesInUseMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "releases_in_use",
+				Help:      "Labeled BOSH Release used by any deployment with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_release_name", "bosh_release_version"},
+		)
+
+		releasesInUseMetric.WithLabelValues(releaseName, releaseVersion).Set(float64(1))
+
+		stemcellsInUseMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "stemcells_in_use",
+				Help:      "Labeled BOSH Stemcell used by any deployment with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_stemcell_name", "bosh_stemcell_version", "bosh_stemcell_os_name"},
+		)
+
+		stemcellsInUseMetric.WithLabelValues(stemcellName, stemcellVersion, stemcellOSName).Set(float64(1))
+
 		lastDeploymentsScrapeTimestampMetric = prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -154,6 +456,9 @@ var _ = Describe("DeploymentsCollector", func() {
 			environment,
 			boshName,
 			boshUUID,
+			deprecatedStemcellsFilter,
+			deprecatedReleasesFilter,
+			tagKeys,
 		)
 	})
 
@@ -170,11 +475,20 @@ var _ = Describe("DeploymentsCollector", func() {
 			go deploymentsCollector.Describe(descriptions)
 		})
 
+		It("returns a deployment_info description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInfoMetric.WithLabelValues(
+				deploymentName,
+				tagTeam,
+				tagCostCenter,
+			).Desc())))
+		})
+
 		It("returns a deployment_release_info description", func() {
 			Eventually(descriptions).Should(Receive(Equal(deploymentReleaseInfoMetric.WithLabelValues(
 				deploymentName,
 				releaseName,
 				releaseVersion,
+				"false",
 			).Desc())))
 		})
 
@@ -184,6 +498,26 @@ var _ = Describe("DeploymentsCollector", func() {
 				stemcellName,
 				stemcellVersion,
 				stemcellOSName,
+				stemcellCPI,
+				stemcellAPIVersion,
+				"false",
+			).Desc())))
+		})
+
+		It("returns a deployment_releases_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentReleasesTotalMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_stemcells_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentStemcellsTotalMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_stemcell_upgrade_available metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentStemcellUpgradeAvailableMetric.WithLabelValues(
+				deploymentName,
+				stemcellName,
+				stemcellVersion,
+				stemcellOSName,
 			).Desc())))
 		})
 
@@ -194,6 +528,59 @@ var _ = Describe("DeploymentsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a deployment_instances_per_az metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInstancesPerAZMetric.WithLabelValues(
+				deploymentName,
+				"unknown",
+			).Desc())))
+		})
+
+		It("returns a deployment_instances_healthy metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInstancesHealthyMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_instances_count metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInstancesCountMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_instances_healthy_ratio metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInstancesHealthyRatioMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_instance_dns metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentInstanceDNSMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobDNS,
+			).Desc())))
+		})
+
+		It("returns a deployment_errand_info metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentErrandInfoMetric.WithLabelValues(deploymentName, errandName).Desc())))
+		})
+
+		It("returns a deployment_errands metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentErrandsMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_stale metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentStaleMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a deployment_resurrection_paused metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(deploymentResurrectionPausedMetric.WithLabelValues(deploymentName).Desc())))
+		})
+
+		It("returns a releases_in_use metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(releasesInUseMetric.WithLabelValues(releaseName, releaseVersion).Desc())))
+		})
+
+		It("returns a stemcells_in_use metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(stemcellsInUseMetric.WithLabelValues(stemcellName, stemcellVersion, stemcellOSName).Desc())))
+		})
+
 		It("returns a last_deployments_scrape_timestamp metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(lastDeploymentsScrapeTimestampMetric.Desc())))
 		})
@@ -212,18 +599,23 @@ var _ = Describe("DeploymentsCollector", func() {
 			releases = []deployments.Release{release}
 
 			stemcell = deployments.Stemcell{
-				Name:    stemcellName,
-				Version: stemcellVersion,
-				OSName:  stemcellOSName,
+				Name:             stemcellName,
+				Version:          stemcellVersion,
+				OSName:           stemcellOSName,
+				CPI:              stemcellCPI,
+				APIVersion:       stemcellAPIVersion,
+				UpgradeAvailable: new(bool),
 			}
 			stemcells = []deployments.Stemcell{stemcell}
 
+			errands = []deployments.Errand{{Name: errandName}}
+
 			instances = []deployments.Instance{
-				{VMType: vmTypeSmall},
-				{VMType: vmTypeMedium},
+				{Name: jobName, ID: jobID, Index: jobIndex, DNS: []string{jobDNS}, VMType: vmTypeSmall, Healthy: true},
+				{VMType: vmTypeMedium, Healthy: true},
 				{VMType: vmTypeMedium},
-				{VMType: vmTypeLarge},
-				{VMType: vmTypeLarge},
+				{VMType: vmTypeLarge, Healthy: true},
+				{VMType: vmTypeLarge, Healthy: true},
 				{VMType: vmTypeLarge},
 			}
 
@@ -241,6 +633,9 @@ var _ = Describe("DeploymentsCollector", func() {
 				Releases:  releases,
 				Stemcells: stemcells,
 				Instances: instances,
+				Errands:   errands,
+				Tags:      map[string]string{"team": tagTeam, "cost-center": tagCostCenter},
+				Stale:     new(bool),
 			}
 			deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
 
@@ -256,11 +651,54 @@ var _ = Describe("DeploymentsCollector", func() {
 			}()
 		})
 
+		It("returns a deployment_info metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInfoMetric.WithLabelValues(
+				deploymentName,
+				tagTeam,
+				tagCostCenter,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when a deployment lacks some of the tags", func() {
+			BeforeEach(func() {
+				deploymentInfo.Tags = map[string]string{"team": tagTeam}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentInfoMetric.WithLabelValues(deploymentName, tagTeam, "").Set(float64(1))
+			})
+
+			It("returns a deployment_info metric with an empty tag label", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInfoMetric.WithLabelValues(
+					deploymentName,
+					tagTeam,
+					"",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when a deployment has no tags", func() {
+			BeforeEach(func() {
+				deploymentInfo.Tags = nil
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+			})
+
+			It("should not return a deployment_info metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentInfoMetric.WithLabelValues(
+					deploymentName,
+					tagTeam,
+					tagCostCenter,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		It("returns a deployment_release_info metric", func() {
 			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentReleaseInfoMetric.WithLabelValues(
 				deploymentName,
 				releaseName,
 				releaseVersion,
+				"false",
 			))))
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
@@ -271,10 +709,71 @@ var _ = Describe("DeploymentsCollector", func() {
 				stemcellName,
 				stemcellVersion,
 				stemcellOSName,
+				stemcellCPI,
+				stemcellAPIVersion,
+				"false",
 			))))
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
+		It("returns a deployment_releases_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentReleasesTotalMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_stemcells_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStemcellsTotalMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_stemcell_upgrade_available metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStemcellUpgradeAvailableMetric.WithLabelValues(
+				deploymentName,
+				stemcellName,
+				stemcellVersion,
+				stemcellOSName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when a stemcell upgrade is available", func() {
+			BeforeEach(func() {
+				deploymentInfo.Stemcells = []deployments.Stemcell{stemcell}
+				upgradeAvailable := true
+				deploymentInfo.Stemcells[0].UpgradeAvailable = &upgradeAvailable
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentStemcellUpgradeAvailableMetric.WithLabelValues(deploymentName, stemcellName, stemcellVersion, stemcellOSName).Set(float64(1))
+			})
+
+			It("returns an available deployment_stemcell_upgrade_available metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStemcellUpgradeAvailableMetric.WithLabelValues(
+					deploymentName,
+					stemcellName,
+					stemcellVersion,
+					stemcellOSName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the stemcells uploaded to the director could not be read", func() {
+			BeforeEach(func() {
+				deploymentInfo.Stemcells = []deployments.Stemcell{stemcell}
+				deploymentInfo.Stemcells[0].UpgradeAvailable = nil
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+			})
+
+			It("does not return a deployment_stemcell_upgrade_available metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentStemcellUpgradeAvailableMetric.WithLabelValues(
+					deploymentName,
+					stemcellName,
+					stemcellVersion,
+					stemcellOSName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		It("returns a deployment_instances for small vmType instance", func() {
 			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesMetric.WithLabelValues(
 				deploymentName,
@@ -299,6 +798,206 @@ var _ = Describe("DeploymentsCollector", func() {
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
+		It("returns a deployment_instances_per_az metric for instances without an AZ", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesPerAZMetric.WithLabelValues(
+				deploymentName,
+				"unknown",
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when the instances are spread across AZs", func() {
+			BeforeEach(func() {
+				deploymentInfo.Instances = []deployments.Instance{
+					{VMType: vmTypeSmall, AZ: azZ1},
+					{VMType: vmTypeSmall, AZ: azZ2},
+					{VMType: vmTypeSmall, AZ: azZ1},
+					{VMType: vmTypeSmall, AZ: azZ2},
+					{VMType: vmTypeSmall, AZ: azZ2},
+					{VMType: vmTypeSmall},
+				}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+
+				deploymentInstancesPerAZMetric.WithLabelValues(deploymentName, azZ1).Set(float64(2))
+				deploymentInstancesPerAZMetric.WithLabelValues(deploymentName, azZ2).Set(float64(3))
+				deploymentInstancesPerAZMetric.WithLabelValues(deploymentName, "unknown").Set(float64(1))
+			})
+
+			It("returns a deployment_instances_per_az metric for the first AZ", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesPerAZMetric.WithLabelValues(
+					deploymentName,
+					azZ1,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a deployment_instances_per_az metric for the second AZ", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesPerAZMetric.WithLabelValues(
+					deploymentName,
+					azZ2,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a deployment_instances_per_az metric for the instances without an AZ", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesPerAZMetric.WithLabelValues(
+					deploymentName,
+					"unknown",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a deployment_instances_healthy metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesHealthyMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_instances_count metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesCountMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_instances_healthy_ratio metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesHealthyRatioMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_instance_dns metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstanceDNSMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobDNS,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_errand_info metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentErrandInfoMetric.WithLabelValues(deploymentName, errandName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_errands metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentErrandsMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a deployment_stale metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStaleMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when the deployment is stale", func() {
+			BeforeEach(func() {
+				stale := true
+				deploymentInfo.Stale = &stale
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentStaleMetric.WithLabelValues(deploymentName).Set(float64(1))
+			})
+
+			It("returns a stale deployment_stale metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStaleMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the releases and stemcells uploaded to the director could not be read", func() {
+			BeforeEach(func() {
+				deploymentInfo.Stale = nil
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+			})
+
+			It("does not return a deployment_stale metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentStaleMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a deployment_resurrection_paused metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(deploymentResurrectionPausedMetric.WithLabelValues(deploymentName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when the resurrection of an instance is paused", func() {
+			BeforeEach(func() {
+				deploymentInfo.Instances = []deployments.Instance{
+					{Name: jobName, ID: jobID, Index: jobIndex, VMType: vmTypeSmall, Healthy: true},
+					{VMType: vmTypeMedium, Healthy: true, ResurrectionPaused: true},
+				}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentResurrectionPausedMetric.WithLabelValues(deploymentName).Set(float64(1))
+			})
+
+			It("returns a paused deployment_resurrection_paused metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentResurrectionPausedMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when there are no errands", func() {
+			BeforeEach(func() {
+				deploymentInfo.Errands = []deployments.Errand{}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentErrandsMetric.WithLabelValues(deploymentName).Set(float64(0))
+			})
+
+			It("should not return a deployment_errand_info metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentErrandInfoMetric.WithLabelValues(deploymentName, errandName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an empty deployment_errands metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentErrandsMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when an instance has no DNS records", func() {
+			BeforeEach(func() {
+				deploymentInfo.Instances = []deployments.Instance{
+					{Name: jobName, ID: jobID, Index: jobIndex, VMType: vmTypeSmall, Healthy: true},
+				}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+			})
+
+			It("should not return a deployment_instance_dns metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentInstanceDNSMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobDNS,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when there are no instances", func() {
+			BeforeEach(func() {
+				deploymentInfo.Instances = []deployments.Instance{}
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentInstancesHealthyMetric.WithLabelValues(deploymentName).Set(float64(0))
+				deploymentInstancesCountMetric.WithLabelValues(deploymentName).Set(float64(0))
+			})
+
+			It("returns a deployment_instances_healthy metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesHealthyMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a deployment_instances_count metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentInstancesCountMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("should not return a deployment_instances_healthy_ratio metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentInstancesHealthyRatioMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		Context("when there are no deployments", func() {
 			BeforeEach(func() {
 				deploymentsInfo = []deployments.DeploymentInfo{}
@@ -312,10 +1011,105 @@ var _ = Describe("DeploymentsCollector", func() {
 			})
 		})
 
+		It("returns a releases_in_use metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(releasesInUseMetric.WithLabelValues(releaseName, releaseVersion))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a stemcells_in_use metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(stemcellsInUseMetric.WithLabelValues(stemcellName, stemcellVersion, stemcellOSName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when several deployments use the same release and stemcell", func() {
+			BeforeEach(func() {
+				otherDeploymentInfo := deploymentInfo
+				otherDeploymentInfo.Name = "fake-other-deployment-name"
+				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo, otherDeploymentInfo}
+			})
+
+			It("returns a single releases_in_use and stemcells_in_use metric", func() {
+				releasesInUse := 0
+				stemcellsInUse := 0
+				for {
+					var metric prometheus.Metric
+					Eventually(metrics).Should(Receive(&metric))
+
+					switch metric.Desc().String() {
+					case releasesInUseMetric.WithLabelValues(releaseName, releaseVersion).Desc().String():
+						releasesInUse++
+					case stemcellsInUseMetric.WithLabelValues(stemcellName, stemcellVersion, stemcellOSName).Desc().String():
+						stemcellsInUse++
+					}
+
+					if metric.Desc().String() == lastDeploymentsScrapeDurationSecondsMetric.Desc().String() {
+						break
+					}
+				}
+
+				Expect(releasesInUse).To(Equal(1))
+				Expect(stemcellsInUse).To(Equal(1))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when releases and stemcells are deprecated", func() {
+			BeforeEach(func() {
+				deprecatedReleasesFilter, _ = filters.NewDeprecatedFilter([]string{releaseName + "/1.*"})
+				deprecatedStemcellsFilter, _ = filters.NewDeprecatedFilter([]string{stemcellName + "/" + stemcellVersion})
+
+				deploymentReleaseInfoMetric.WithLabelValues(
+					deploymentName,
+					releaseName,
+					releaseVersion,
+					"true",
+				).Set(float64(1))
+
+				deploymentStemcellInfoMetric.WithLabelValues(
+					deploymentName,
+					stemcellName,
+					stemcellVersion,
+					stemcellOSName,
+					stemcellCPI,
+					stemcellAPIVersion,
+					"true",
+				).Set(float64(1))
+			})
+
+			It("returns a deprecated deployment_release_info metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentReleaseInfoMetric.WithLabelValues(
+					deploymentName,
+					releaseName,
+					releaseVersion,
+					"true",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a deprecated deployment_stemcell_info metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStemcellInfoMetric.WithLabelValues(
+					deploymentName,
+					stemcellName,
+					stemcellVersion,
+					stemcellOSName,
+					stemcellCPI,
+					stemcellAPIVersion,
+					"true",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		Context("when there are no releases", func() {
 			BeforeEach(func() {
 				deploymentInfo.Releases = []deployments.Release{}
 				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentReleasesTotalMetric.WithLabelValues(deploymentName).Set(float64(0))
+			})
+
+			It("returns an empty deployment_releases_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentReleasesTotalMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
 			})
 
 			It("should not return a deployment_release_info metric", func() {
@@ -323,6 +1117,7 @@ var _ = Describe("DeploymentsCollector", func() {
 					deploymentName,
 					releaseName,
 					releaseVersion,
+					"false",
 				))))
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
@@ -332,6 +1127,12 @@ var _ = Describe("DeploymentsCollector", func() {
 			BeforeEach(func() {
 				deploymentInfo.Stemcells = []deployments.Stemcell{}
 				deploymentsInfo = []deployments.DeploymentInfo{deploymentInfo}
+				deploymentStemcellsTotalMetric.WithLabelValues(deploymentName).Set(float64(0))
+			})
+
+			It("returns an empty deployment_stemcells_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(deploymentStemcellsTotalMetric.WithLabelValues(deploymentName))))
+				Consistently(errMetrics).ShouldNot(Receive())
 			})
 
 			It("should not return a deployment_stemcell_info metric", func() {
@@ -340,6 +1141,9 @@ var _ = Describe("DeploymentsCollector", func() {
 					stemcellName,
 					stemcellVersion,
 					stemcellOSName,
+					stemcellCPI,
+					stemcellAPIVersion,
+					"false",
 				))))
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
@@ -358,6 +1162,14 @@ var _ = Describe("DeploymentsCollector", func() {
 				))))
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
+
+			It("should not return a deployment_instances_per_az metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(deploymentInstancesPerAZMetric.WithLabelValues(
+					deploymentName,
+					"unknown",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
 		})
 	})
 })
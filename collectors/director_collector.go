@@ -0,0 +1,47 @@
+package collectors
+
+import (
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DirectorCollector struct {
+	directorInfoMetric prometheus.Gauge
+}
+
+// NewDirectorCollector reports the BOSH Director Info read at startup, so
+// scrapes do not issue additional Info calls against the director.
+func NewDirectorCollector(
+	namespace string,
+	environment string,
+	boshInfo director.Info,
+) *DirectorCollector {
+	directorInfoMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "director",
+			Name:      "info",
+			Help:      "Labeled BOSH Director Info with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment":  environment,
+				"bosh_name":    boshInfo.Name,
+				"bosh_uuid":    boshInfo.UUID,
+				"bosh_version": boshInfo.Version,
+				"bosh_cpi":     boshInfo.CPI,
+			},
+		},
+	)
+	directorInfoMetric.Set(float64(1))
+
+	return &DirectorCollector{
+		directorInfoMetric: directorInfoMetric,
+	}
+}
+
+func (c *DirectorCollector) Collect(ch chan<- prometheus.Metric) {
+	c.directorInfoMetric.Collect(ch)
+}
+
+func (c *DirectorCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.directorInfoMetric.Describe(ch)
+}
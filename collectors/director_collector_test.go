@@ -0,0 +1,91 @@
+package collectors_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("DirectorCollector", func() {
+	var (
+		namespace         string
+		environment       string
+		boshInfo          director.Info
+		directorCollector *DirectorCollector
+
+		directorInfoMetric prometheus.Gauge
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshInfo = director.Info{
+			Name:    "test_bosh_name",
+			UUID:    "test_bosh_uuid",
+			Version: "270.2.0 (00000000)",
+			CPI:     "fake-cpi",
+		}
+
+		directorInfoMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "director",
+				Name:      "info",
+				Help:      "Labeled BOSH Director Info with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment":  environment,
+					"bosh_name":    boshInfo.Name,
+					"bosh_uuid":    boshInfo.UUID,
+					"bosh_version": boshInfo.Version,
+					"bosh_cpi":     boshInfo.CPI,
+				},
+			},
+		)
+		directorInfoMetric.Set(float64(1))
+	})
+
+	JustBeforeEach(func() {
+		directorCollector = NewDirectorCollector(namespace, environment, boshInfo)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go directorCollector.Describe(descriptions)
+		})
+
+		It("returns a director_info metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(directorInfoMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go directorCollector.Collect(metrics)
+		})
+
+		It("returns a director_info metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(directorInfoMetric)))
+		})
+	})
+})
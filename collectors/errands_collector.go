@@ -0,0 +1,126 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/errands"
+)
+
+type ErrandsCollector struct {
+	errandsFetcher                         *errands.Fetcher
+	errandLastExitCodeMetric               *prometheus.GaugeVec
+	lastErrandsScrapeErrorMetric           prometheus.Gauge
+	lastErrandsScrapeTimestampMetric       prometheus.Gauge
+	lastErrandsScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewErrandsCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	errandsFetcher *errands.Fetcher,
+) *ErrandsCollector {
+	errandLastExitCodeMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "deployment_errand_last_exit_code",
+			Help:      "Exit code of the last finished run of a BOSH Deployment Errand.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_errand_name"},
+	)
+
+	lastErrandsScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_errands_scrape_error",
+			Help:      "Whether the last scrape of Errand metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastErrandsScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_errands_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Errand metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastErrandsScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_errands_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Errand metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &ErrandsCollector{
+		errandsFetcher:                         errandsFetcher,
+		errandLastExitCodeMetric:               errandLastExitCodeMetric,
+		lastErrandsScrapeErrorMetric:           lastErrandsScrapeErrorMetric,
+		lastErrandsScrapeTimestampMetric:       lastErrandsScrapeTimestampMetric,
+		lastErrandsScrapeDurationSecondsMetric: lastErrandsScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *ErrandsCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	c.errandLastExitCodeMetric.Reset()
+
+	errandRunsInfo, err := c.errandsFetcher.ErrandRuns()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, errandRun := range errandRunsInfo {
+		c.errandLastExitCodeMetric.WithLabelValues(errandRun.Deployment, errandRun.Name).Set(float64(errandRun.ExitCode))
+	}
+	c.errandLastExitCodeMetric.Collect(ch)
+
+	c.lastErrandsScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastErrandsScrapeErrorMetric.Collect(ch)
+
+	c.lastErrandsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastErrandsScrapeTimestampMetric.Collect(ch)
+
+	c.lastErrandsScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastErrandsScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *ErrandsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.errandLastExitCodeMetric.Describe(ch)
+	c.lastErrandsScrapeErrorMetric.Describe(ch)
+	c.lastErrandsScrapeTimestampMetric.Describe(ch)
+	c.lastErrandsScrapeDurationSecondsMetric.Describe(ch)
+}
@@ -0,0 +1,228 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/errands"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("ErrandsCollector", func() {
+	var (
+		namespace        string
+		environment      string
+		boshName         string
+		boshUUID         string
+		boshClient       *directorfakes.FakeDirector
+		errandsFetcher   *errands.Fetcher
+		errandsCollector *ErrandsCollector
+
+		errandLastExitCodeMetric               *prometheus.GaugeVec
+		lastErrandsScrapeErrorMetric           prometheus.Gauge
+		lastErrandsScrapeTimestampMetric       prometheus.Gauge
+		lastErrandsScrapeDurationSecondsMetric prometheus.Gauge
+
+		deploymentName = "fake-deployment-name"
+		errandName     = "fake-errand-name"
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		errandLastExitCodeMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "deployment_errand_last_exit_code",
+				Help:      "Exit code of the last finished run of a BOSH Deployment Errand.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_errand_name"},
+		)
+
+		lastErrandsScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_errands_scrape_error",
+				Help:      "Whether the last scrape of Errand metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastErrandsScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_errands_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Errand metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastErrandsScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_errands_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Errand metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		errandsFetcher = errands.NewFetcher(boshClient, 100)
+		errandsCollector = NewErrandsCollector(namespace, environment, boshName, boshUUID, errandsFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go errandsCollector.Describe(descriptions)
+		})
+
+		It("returns a deployment_errand_last_exit_code metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(errandLastExitCodeMetric.WithLabelValues(
+				deploymentName,
+				errandName,
+			).Desc())))
+		})
+
+		It("returns a last_errands_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastErrandsScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_errands_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastErrandsScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_errands_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastErrandsScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.RecentTasksReturns([]director.Task{
+				&directorfakes.FakeTask{
+					StateStub:       func() string { return "error" },
+					DescriptionStub: func() string { return "run errand " + errandName + " from deployment " + deploymentName },
+					ResultOutputStub: func(reporter director.TaskReporter) error {
+						defer reporter.TaskFinished(1, "error")
+						reporter.TaskOutputChunk(1, []byte(`{"exit_code":1}`+"\n"))
+						return errors.New("task failed")
+					},
+				},
+			}, nil)
+
+			errandLastExitCodeMetric.WithLabelValues(deploymentName, errandName).Set(1)
+			lastErrandsScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go errandsCollector.Collect(metrics)
+		})
+
+		It("returns a deployment_errand_last_exit_code metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(errandLastExitCodeMetric.WithLabelValues(deploymentName, errandName))))
+		})
+
+		Context("when an errand run has no result", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{
+					&directorfakes.FakeTask{
+						StateStub:       func() string { return "done" },
+						DescriptionStub: func() string { return "run errand " + errandName + " from deployment " + deploymentName },
+					},
+				}, nil)
+			})
+
+			It("does not return a deployment_errand_last_exit_code metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(errandLastExitCodeMetric.WithLabelValues(deploymentName, errandName))))
+			})
+		})
+
+		It("returns a last_errands_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastErrandsScrapeErrorMetric)))
+		})
+
+		Context("when reading the result of an errand run fails", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{
+					&directorfakes.FakeTask{
+						StateStub:       func() string { return "done" },
+						DescriptionStub: func() string { return "run errand " + errandName + " from deployment " + deploymentName },
+						ResultOutputStub: func(director.TaskReporter) error {
+							return errors.New("no result")
+						},
+					},
+				}, nil)
+
+				lastErrandsScrapeErrorMetric.Set(1)
+			})
+
+			It("returns a failed last_errands_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastErrandsScrapeErrorMetric)))
+			})
+		})
+
+		Context("when reading the recent tasks fails", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{}, errors.New("no tasks"))
+
+				lastErrandsScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a deployment_errand_last_exit_code metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(errandLastExitCodeMetric.WithLabelValues(deploymentName, errandName))))
+			})
+
+			It("returns a failed last_errands_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastErrandsScrapeErrorMetric)))
+			})
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/events"
+)
+
+type EventsCollector struct {
+	eventsFetcher                         *events.Fetcher
+	eventsMetric                          *prometheus.CounterVec
+	lastEventsScrapeErrorMetric           prometheus.Gauge
+	lastEventsScrapeTimestampMetric       prometheus.Gauge
+	lastEventsScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewEventsCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	eventsFetcher *events.Fetcher,
+) *EventsCollector {
+	eventsMetric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "events_total",
+			Help:      "Total number of BOSH Events by action and object type.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_event_action", "bosh_event_object_type"},
+	)
+
+	lastEventsScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_events_scrape_error",
+			Help:      "Whether the last scrape of Event metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastEventsScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_events_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Event metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastEventsScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_events_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Event metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &EventsCollector{
+		eventsFetcher:                         eventsFetcher,
+		eventsMetric:                          eventsMetric,
+		lastEventsScrapeErrorMetric:           lastEventsScrapeErrorMetric,
+		lastEventsScrapeTimestampMetric:       lastEventsScrapeTimestampMetric,
+		lastEventsScrapeDurationSecondsMetric: lastEventsScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *EventsCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	eventsInfo, err := c.eventsFetcher.Events()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, event := range eventsInfo {
+		c.eventsMetric.WithLabelValues(event.Action, event.ObjectType).Inc()
+	}
+	c.eventsMetric.Collect(ch)
+
+	c.lastEventsScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastEventsScrapeErrorMetric.Collect(ch)
+
+	c.lastEventsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastEventsScrapeTimestampMetric.Collect(ch)
+
+	c.lastEventsScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastEventsScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *EventsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.eventsMetric.Describe(ch)
+	c.lastEventsScrapeErrorMetric.Describe(ch)
+	c.lastEventsScrapeTimestampMetric.Describe(ch)
+	c.lastEventsScrapeDurationSecondsMetric.Describe(ch)
+}
@@ -0,0 +1,239 @@
+package collectors_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/events"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("EventsCollector", func() {
+	var (
+		namespace       string
+		environment     string
+		boshName        string
+		boshUUID        string
+		boshClient      *directorfakes.FakeDirector
+		eventsFetcher   *events.Fetcher
+		eventsCollector *EventsCollector
+
+		eventsMetric                          *prometheus.CounterVec
+		lastEventsScrapeErrorMetric           prometheus.Gauge
+		lastEventsScrapeTimestampMetric       prometheus.Gauge
+		lastEventsScrapeDurationSecondsMetric prometheus.Gauge
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		eventsMetric = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "events_total",
+				Help:      "Total number of BOSH Events by action and object type.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_event_action", "bosh_event_object_type"},
+		)
+
+		lastEventsScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_events_scrape_error",
+				Help:      "Whether the last scrape of Event metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastEventsScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_events_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Event metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastEventsScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_events_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Event metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		eventsFetcher = events.NewFetcher(boshClient, time.Hour)
+		eventsCollector = NewEventsCollector(namespace, environment, boshName, boshUUID, eventsFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go eventsCollector.Describe(descriptions)
+		})
+
+		It("returns a events_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(eventsMetric.WithLabelValues(
+				"update",
+				"deployment",
+			).Desc())))
+		})
+
+		It("returns a last_events_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastEventsScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_events_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastEventsScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_events_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastEventsScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.EventsReturns([]director.Event{
+				&directorfakes.FakeEvent{
+					IDStub:         func() string { return "2" },
+					ActionStub:     func() string { return "update" },
+					ObjectTypeStub: func() string { return "deployment" },
+				},
+				&directorfakes.FakeEvent{
+					IDStub:         func() string { return "1" },
+					ActionStub:     func() string { return "update" },
+					ObjectTypeStub: func() string { return "deployment" },
+				},
+			}, nil)
+			boshClient.EventsReturnsOnCall(1, []director.Event{}, nil)
+
+			eventsMetric.WithLabelValues("update", "deployment").Add(2)
+			lastEventsScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go eventsCollector.Collect(metrics)
+		})
+
+		It("returns a events_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(eventsMetric.WithLabelValues("update", "deployment"))))
+		})
+
+		It("returns a last_events_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastEventsScrapeErrorMetric)))
+		})
+
+		Context("when the events were already counted", func() {
+			BeforeEach(func() {
+				boshClient.EventsReturnsOnCall(2, []director.Event{
+					&directorfakes.FakeEvent{
+						IDStub:         func() string { return "3" },
+						ActionStub:     func() string { return "update" },
+						ObjectTypeStub: func() string { return "deployment" },
+					},
+					&directorfakes.FakeEvent{
+						IDStub:         func() string { return "2" },
+						ActionStub:     func() string { return "update" },
+						ObjectTypeStub: func() string { return "deployment" },
+					},
+				}, nil)
+
+				eventsMetric.WithLabelValues("update", "deployment").Inc()
+			})
+
+			It("only counts the new events", func() {
+				Eventually(metrics).Should(Receive())
+				Eventually(metrics).Should(Receive())
+				Eventually(metrics).Should(Receive())
+				Eventually(metrics).Should(Receive())
+
+				go eventsCollector.Collect(metrics)
+				Eventually(metrics).Should(Receive(PrometheusMetric(eventsMetric.WithLabelValues("update", "deployment"))))
+			})
+		})
+
+		Context("when reading a later page of events fails", func() {
+			BeforeEach(func() {
+				boshClient.EventsReturnsOnCall(1, nil, errors.New("no events"))
+				boshClient.EventsReturnsOnCall(3, []director.Event{}, nil)
+			})
+
+			It("does not count the events of the first page twice", func() {
+				Eventually(metrics).Should(Receive())
+				Eventually(metrics).Should(Receive())
+				Eventually(metrics).Should(Receive())
+				Consistently(metrics).ShouldNot(Receive())
+
+				go eventsCollector.Collect(metrics)
+				Eventually(metrics).Should(Receive(PrometheusMetric(eventsMetric.WithLabelValues("update", "deployment"))))
+			})
+		})
+
+		Context("when reading the events fails", func() {
+			BeforeEach(func() {
+				boshClient.EventsReturns([]director.Event{}, errors.New("no events"))
+
+				lastEventsScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a events_total metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(eventsMetric.WithLabelValues("update", "deployment"))))
+			})
+
+			It("returns a failed last_events_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastEventsScrapeErrorMetric)))
+			})
+		})
+	})
+})
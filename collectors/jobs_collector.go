@@ -3,7 +3,9 @@ package collectors
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,7 +17,18 @@ import (
 type JobsCollector struct {
 	azsFilter                           *filters.AZsFilter
 	cidrsFilter                         *filters.CidrFilter
+	onlyUnhealthy                       bool
+	instanceGroupMetrics                bool
+	vitalsVMTypeLabel                   bool
+	enabledMetrics                      []*prometheus.GaugeVec
 	jobHealthyMetric                    *prometheus.GaugeVec
+	jobResurrectionPausedMetric         *prometheus.GaugeVec
+	jobIgnoreMetric                     *prometheus.GaugeVec
+	jobInstanceInfoMetric               *prometheus.GaugeVec
+	jobNoVMInfoMetric                   *prometheus.GaugeVec
+	jobUnhealthyInfoMetric              *prometheus.GaugeVec
+	jobInstancesExpectedMetric          *prometheus.GaugeVec
+	jobInstancesPresentMetric           *prometheus.GaugeVec
 	jobLoadAvg01Metric                  *prometheus.GaugeVec
 	jobLoadAvg05Metric                  *prometheus.GaugeVec
 	jobLoadAvg15Metric                  *prometheus.GaugeVec
@@ -26,17 +39,30 @@ type JobsCollector struct {
 	jobMemPercentMetric                 *prometheus.GaugeVec
 	jobSwapKBMetric                     *prometheus.GaugeVec
 	jobSwapPercentMetric                *prometheus.GaugeVec
+	jobSwapActiveMetric                 *prometheus.GaugeVec
 	jobSystemDiskInodePercentMetric     *prometheus.GaugeVec
 	jobSystemDiskPercentMetric          *prometheus.GaugeVec
 	jobEphemeralDiskInodePercentMetric  *prometheus.GaugeVec
 	jobEphemeralDiskPercentMetric       *prometheus.GaugeVec
 	jobPersistentDiskInodePercentMetric *prometheus.GaugeVec
 	jobPersistentDiskPercentMetric      *prometheus.GaugeVec
+	jobStartTimeMetric                  *prometheus.GaugeVec
+	jobVMCreatedAtMetric                *prometheus.GaugeVec
+	jobProcessesMetric                  *prometheus.GaugeVec
+	jobProcessesFailingMetric           *prometheus.GaugeVec
 	jobProcessHealthyMetric             *prometheus.GaugeVec
 	jobProcessUptimeMetric              *prometheus.GaugeVec
+	jobProcessStartTimeMetric           *prometheus.GaugeVec
 	jobProcessCPUTotalMetric            *prometheus.GaugeVec
+	jobProcessCPUUserMetric             *prometheus.GaugeVec
+	jobProcessCPUSysMetric              *prometheus.GaugeVec
 	jobProcessMemKBMetric               *prometheus.GaugeVec
 	jobProcessMemPercentMetric          *prometheus.GaugeVec
+	instanceGroupCPUSysAvgMetric        *prometheus.GaugeVec
+	instanceGroupCPUUserAvgMetric       *prometheus.GaugeVec
+	instanceGroupCPUWaitAvgMetric       *prometheus.GaugeVec
+	instanceGroupMemPercentMaxMetric    *prometheus.GaugeVec
+	instanceGroupProcessesFailingMetric *prometheus.GaugeVec
 	lastJobsScrapeTimestampMetric       prometheus.Gauge
 	lastJobsScrapeDurationSecondsMetric prometheus.Gauge
 }
@@ -48,7 +74,17 @@ func NewJobsCollector(
 	boshUUID string,
 	azsFilter *filters.AZsFilter,
 	cidrsFilter *filters.CidrFilter,
+	onlyUnhealthy bool,
+	instanceInfoMetrics bool,
+	instanceGroupMetrics bool,
+	vitalsVMTypeLabel bool,
+	metricsFilter *filters.MetricsFilter,
 ) *JobsCollector {
+	vitalsLabels := []string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"}
+	if vitalsVMTypeLabel {
+		vitalsLabels = append(vitalsLabels, "bosh_job_vm_type")
+	}
+
 	jobHealthyMetric := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -64,6 +100,111 @@ func NewJobsCollector(
 		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
 	)
 
+	jobResurrectionPausedMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "resurrection_paused",
+			Help:      "BOSH Job Resurrection Paused (1 for paused, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+	)
+
+	jobIgnoreMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "ignore",
+			Help:      "BOSH Job Ignore (1 when the instance is ignored during deploys, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+	)
+
+	jobInstanceInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "instance_info",
+			Help:      "Labeled BOSH Job Instance Info with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_agent_id", "bosh_job_vm_cid", "bosh_job_state"},
+	)
+
+	jobNoVMInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "novm_info",
+			Help:      "Labeled BOSH Job without a VM with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az"},
+	)
+
+	jobUnhealthyInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "unhealthy_info",
+			Help:      "Labeled BOSH Job reported as unhealthy for a known reason with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "reason"},
+	)
+
+	jobInstancesExpectedMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "instances_expected",
+			Help:      "Number of BOSH Job instances expected from the highest instance index.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	jobInstancesPresentMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "instances_present",
+			Help:      "Number of BOSH Job instances present.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
 	jobLoadAvg01Metric := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -76,7 +217,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobLoadAvg05Metric := prometheus.NewGaugeVec(
@@ -91,7 +232,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobLoadAvg15Metric := prometheus.NewGaugeVec(
@@ -106,7 +247,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobCPUSysMetric := prometheus.NewGaugeVec(
@@ -121,7 +262,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobCPUUserMetric := prometheus.NewGaugeVec(
@@ -136,7 +277,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobCPUWaitMetric := prometheus.NewGaugeVec(
@@ -151,7 +292,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobMemKBMetric := prometheus.NewGaugeVec(
@@ -166,7 +307,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobMemPercentMetric := prometheus.NewGaugeVec(
@@ -181,7 +322,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobSwapKBMetric := prometheus.NewGaugeVec(
@@ -196,7 +337,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobSwapPercentMetric := prometheus.NewGaugeVec(
@@ -211,7 +352,22 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
+	)
+
+	jobSwapActiveMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "swap_active",
+			Help:      "BOSH Job Swap Active (1 when swap is in use, 0 otherwise).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		vitalsLabels,
 	)
 
 	jobSystemDiskInodePercentMetric := prometheus.NewGaugeVec(
@@ -226,7 +382,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobSystemDiskPercentMetric := prometheus.NewGaugeVec(
@@ -241,7 +397,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobEphemeralDiskInodePercentMetric := prometheus.NewGaugeVec(
@@ -256,7 +412,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobEphemeralDiskPercentMetric := prometheus.NewGaugeVec(
@@ -271,7 +427,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobPersistentDiskInodePercentMetric := prometheus.NewGaugeVec(
@@ -286,7 +442,7 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
-		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		vitalsLabels,
 	)
 
 	jobPersistentDiskPercentMetric := prometheus.NewGaugeVec(
@@ -301,6 +457,66 @@ func NewJobsCollector(
 				"bosh_uuid":   boshUUID,
 			},
 		},
+		vitalsLabels,
+	)
+
+	jobStartTimeMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "start_time_seconds",
+			Help:      "BOSH Job start time in seconds since 1970, computed from the Job Uptime.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		vitalsLabels,
+	)
+
+	jobVMCreatedAtMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "vm_created_at_seconds",
+			Help:      "BOSH Job VM creation time in seconds since 1970.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		vitalsLabels,
+	)
+
+	jobProcessesMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "processes_total",
+			Help:      "Number of BOSH Job Processes.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+	)
+
+	jobProcessesFailingMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "processes_failing_total",
+			Help:      "Number of unhealthy BOSH Job Processes.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
 		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
 	)
 
@@ -334,6 +550,21 @@ func NewJobsCollector(
 		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
 	)
 
+	jobProcessStartTimeMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job_process",
+			Name:      "start_time_seconds",
+			Help:      "BOSH Job Process start time in seconds since 1970, computed from the Job Process Uptime.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+	)
+
 	jobProcessCPUTotalMetric := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -349,6 +580,36 @@ func NewJobsCollector(
 		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
 	)
 
+	jobProcessCPUUserMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job_process",
+			Name:      "cpu_user",
+			Help:      "BOSH Job Process CPU User.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+	)
+
+	jobProcessCPUSysMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job_process",
+			Name:      "cpu_sys",
+			Help:      "BOSH Job Process CPU System.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+	)
+
 	jobProcessMemKBMetric := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -379,6 +640,81 @@ func NewJobsCollector(
 		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
 	)
 
+	instanceGroupCPUSysAvgMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance_group",
+			Name:      "cpu_sys_avg",
+			Help:      "Average BOSH Job CPU System of the instances in this instance group.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	instanceGroupCPUUserAvgMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance_group",
+			Name:      "cpu_user_avg",
+			Help:      "Average BOSH Job CPU User of the instances in this instance group.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	instanceGroupCPUWaitAvgMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance_group",
+			Name:      "cpu_wait_avg",
+			Help:      "Average BOSH Job CPU Wait of the instances in this instance group.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	instanceGroupMemPercentMaxMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance_group",
+			Name:      "mem_percent_max",
+			Help:      "Maximum BOSH Job Memory Percent of the instances in this instance group.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	instanceGroupProcessesFailingMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "instance_group",
+			Name:      "processes_failing_total",
+			Help:      "Number of failing BOSH Job Processes of the instances in this instance group.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
 	lastJobsScrapeTimestampMetric := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -410,7 +746,17 @@ func NewJobsCollector(
 	collector := &JobsCollector{
 		azsFilter:                           azsFilter,
 		cidrsFilter:                         cidrsFilter,
+		onlyUnhealthy:                       onlyUnhealthy,
+		instanceGroupMetrics:                instanceGroupMetrics,
+		vitalsVMTypeLabel:                   vitalsVMTypeLabel,
 		jobHealthyMetric:                    jobHealthyMetric,
+		jobResurrectionPausedMetric:         jobResurrectionPausedMetric,
+		jobIgnoreMetric:                     jobIgnoreMetric,
+		jobInstanceInfoMetric:               jobInstanceInfoMetric,
+		jobNoVMInfoMetric:                   jobNoVMInfoMetric,
+		jobUnhealthyInfoMetric:              jobUnhealthyInfoMetric,
+		jobInstancesExpectedMetric:          jobInstancesExpectedMetric,
+		jobInstancesPresentMetric:           jobInstancesPresentMetric,
 		jobLoadAvg01Metric:                  jobLoadAvg01Metric,
 		jobLoadAvg05Metric:                  jobLoadAvg05Metric,
 		jobLoadAvg15Metric:                  jobLoadAvg15Metric,
@@ -421,20 +767,102 @@ func NewJobsCollector(
 		jobMemPercentMetric:                 jobMemPercentMetric,
 		jobSwapKBMetric:                     jobSwapKBMetric,
 		jobSwapPercentMetric:                jobSwapPercentMetric,
+		jobSwapActiveMetric:                 jobSwapActiveMetric,
 		jobSystemDiskInodePercentMetric:     jobSystemDiskInodePercentMetric,
 		jobSystemDiskPercentMetric:          jobSystemDiskPercentMetric,
 		jobEphemeralDiskInodePercentMetric:  jobEphemeralDiskInodePercentMetric,
 		jobEphemeralDiskPercentMetric:       jobEphemeralDiskPercentMetric,
 		jobPersistentDiskInodePercentMetric: jobPersistentDiskInodePercentMetric,
 		jobPersistentDiskPercentMetric:      jobPersistentDiskPercentMetric,
+		jobStartTimeMetric:                  jobStartTimeMetric,
+		jobVMCreatedAtMetric:                jobVMCreatedAtMetric,
+		jobProcessesMetric:                  jobProcessesMetric,
+		jobProcessesFailingMetric:           jobProcessesFailingMetric,
 		jobProcessHealthyMetric:             jobProcessHealthyMetric,
 		jobProcessUptimeMetric:              jobProcessUptimeMetric,
+		jobProcessStartTimeMetric:           jobProcessStartTimeMetric,
 		jobProcessCPUTotalMetric:            jobProcessCPUTotalMetric,
+		jobProcessCPUUserMetric:             jobProcessCPUUserMetric,
+		jobProcessCPUSysMetric:              jobProcessCPUSysMetric,
 		jobProcessMemKBMetric:               jobProcessMemKBMetric,
 		jobProcessMemPercentMetric:          jobProcessMemPercentMetric,
+		instanceGroupCPUSysAvgMetric:        instanceGroupCPUSysAvgMetric,
+		instanceGroupCPUUserAvgMetric:       instanceGroupCPUUserAvgMetric,
+		instanceGroupCPUWaitAvgMetric:       instanceGroupCPUWaitAvgMetric,
+		instanceGroupMemPercentMaxMetric:    instanceGroupMemPercentMaxMetric,
+		instanceGroupProcessesFailingMetric: instanceGroupProcessesFailingMetric,
 		lastJobsScrapeTimestampMetric:       lastJobsScrapeTimestampMetric,
 		lastJobsScrapeDurationSecondsMetric: lastJobsScrapeDurationSecondsMetric,
 	}
+
+	for _, metric := range []struct {
+		name   string
+		metric *prometheus.GaugeVec
+	}{
+		{"job_healthy", jobHealthyMetric},
+		{"job_resurrection_paused", jobResurrectionPausedMetric},
+		{"job_ignore", jobIgnoreMetric},
+		{"job_novm_info", jobNoVMInfoMetric},
+		{"job_unhealthy_info", jobUnhealthyInfoMetric},
+		{"job_instances_expected", jobInstancesExpectedMetric},
+		{"job_instances_present", jobInstancesPresentMetric},
+		{"job_load_avg01", jobLoadAvg01Metric},
+		{"job_load_avg05", jobLoadAvg05Metric},
+		{"job_load_avg15", jobLoadAvg15Metric},
+		{"job_cpu_sys", jobCPUSysMetric},
+		{"job_cpu_user", jobCPUUserMetric},
+		{"job_cpu_wait", jobCPUWaitMetric},
+		{"job_mem_kb", jobMemKBMetric},
+		{"job_mem_percent", jobMemPercentMetric},
+		{"job_swap_kb", jobSwapKBMetric},
+		{"job_swap_percent", jobSwapPercentMetric},
+		{"job_swap_active", jobSwapActiveMetric},
+		{"job_system_disk_inode_percent", jobSystemDiskInodePercentMetric},
+		{"job_system_disk_percent", jobSystemDiskPercentMetric},
+		{"job_ephemeral_disk_inode_percent", jobEphemeralDiskInodePercentMetric},
+		{"job_ephemeral_disk_percent", jobEphemeralDiskPercentMetric},
+		{"job_persistent_disk_inode_percent", jobPersistentDiskInodePercentMetric},
+		{"job_persistent_disk_percent", jobPersistentDiskPercentMetric},
+		{"job_start_time_seconds", jobStartTimeMetric},
+		{"job_vm_created_at_seconds", jobVMCreatedAtMetric},
+		{"job_processes_total", jobProcessesMetric},
+		{"job_processes_failing_total", jobProcessesFailingMetric},
+		{"job_process_healthy", jobProcessHealthyMetric},
+		{"job_process_uptime_seconds", jobProcessUptimeMetric},
+		{"job_process_start_time_seconds", jobProcessStartTimeMetric},
+		{"job_process_cpu_total", jobProcessCPUTotalMetric},
+		{"job_process_cpu_user", jobProcessCPUUserMetric},
+		{"job_process_cpu_sys", jobProcessCPUSysMetric},
+		{"job_process_mem_kb", jobProcessMemKBMetric},
+		{"job_process_mem_percent", jobProcessMemPercentMetric},
+	} {
+		if metricsFilter.Enabled(metric.name) {
+			collector.enabledMetrics = append(collector.enabledMetrics, metric.metric)
+		}
+	}
+
+	// Instance info labels change on every VM recreate, so they are opt-in.
+	if instanceInfoMetrics && metricsFilter.Enabled("job_instance_info") {
+		collector.enabledMetrics = append(collector.enabledMetrics, jobInstanceInfoMetric)
+	}
+
+	if instanceGroupMetrics {
+		for _, metric := range []struct {
+			name   string
+			metric *prometheus.GaugeVec
+		}{
+			{"instance_group_cpu_sys_avg", instanceGroupCPUSysAvgMetric},
+			{"instance_group_cpu_user_avg", instanceGroupCPUUserAvgMetric},
+			{"instance_group_cpu_wait_avg", instanceGroupCPUWaitAvgMetric},
+			{"instance_group_mem_percent_max", instanceGroupMemPercentMaxMetric},
+			{"instance_group_processes_failing_total", instanceGroupProcessesFailingMetric},
+		} {
+			if metricsFilter.Enabled(metric.name) {
+				collector.enabledMetrics = append(collector.enabledMetrics, metric.metric)
+			}
+		}
+	}
+
 	return collector
 }
 
@@ -443,6 +871,13 @@ func (c *JobsCollector) Collect(deployments []deployments.DeploymentInfo, ch cha
 	var begun = time.Now()
 
 	c.jobHealthyMetric.Reset()
+	c.jobResurrectionPausedMetric.Reset()
+	c.jobIgnoreMetric.Reset()
+	c.jobInstanceInfoMetric.Reset()
+	c.jobNoVMInfoMetric.Reset()
+	c.jobUnhealthyInfoMetric.Reset()
+	c.jobInstancesExpectedMetric.Reset()
+	c.jobInstancesPresentMetric.Reset()
 	c.jobLoadAvg01Metric.Reset()
 	c.jobLoadAvg05Metric.Reset()
 	c.jobLoadAvg15Metric.Reset()
@@ -453,82 +888,202 @@ func (c *JobsCollector) Collect(deployments []deployments.DeploymentInfo, ch cha
 	c.jobMemPercentMetric.Reset()
 	c.jobSwapKBMetric.Reset()
 	c.jobSwapPercentMetric.Reset()
+	c.jobSwapActiveMetric.Reset()
 	c.jobSystemDiskInodePercentMetric.Reset()
 	c.jobSystemDiskPercentMetric.Reset()
 	c.jobEphemeralDiskInodePercentMetric.Reset()
 	c.jobEphemeralDiskPercentMetric.Reset()
 	c.jobPersistentDiskInodePercentMetric.Reset()
 	c.jobPersistentDiskPercentMetric.Reset()
+	c.jobStartTimeMetric.Reset()
+	c.jobVMCreatedAtMetric.Reset()
+	c.jobProcessesMetric.Reset()
+	c.jobProcessesFailingMetric.Reset()
 	c.jobProcessHealthyMetric.Reset()
 	c.jobProcessUptimeMetric.Reset()
+	c.jobProcessStartTimeMetric.Reset()
 	c.jobProcessCPUTotalMetric.Reset()
+	c.jobProcessCPUUserMetric.Reset()
+	c.jobProcessCPUSysMetric.Reset()
 	c.jobProcessMemKBMetric.Reset()
 	c.jobProcessMemPercentMetric.Reset()
+	c.instanceGroupCPUSysAvgMetric.Reset()
+	c.instanceGroupCPUUserAvgMetric.Reset()
+	c.instanceGroupCPUWaitAvgMetric.Reset()
+	c.instanceGroupMemPercentMaxMetric.Reset()
+	c.instanceGroupProcessesFailingMetric.Reset()
+
+	// The metric vectors are safe for concurrent use, so deployments are
+	// reported by a bounded pool of workers before the vectors are collected.
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for w := 0; w < jobsCollectorWorkers(len(deployments)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if reportErr := c.reportDeploymentMetrics(deployments[i], begun, ch); reportErr != nil {
+					mu.Lock()
+					err = reportErr
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range deployments {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, metric := range c.enabledMetrics {
+		metric.Collect(ch)
+	}
+
+	c.lastJobsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastJobsScrapeTimestampMetric.Collect(ch)
+
+	c.lastJobsScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastJobsScrapeDurationSecondsMetric.Collect(ch)
+
+	return err
+}
+
+func (c *JobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.enabledMetrics {
+		metric.Describe(ch)
+	}
+	c.lastJobsScrapeTimestampMetric.Describe(ch)
+	c.lastJobsScrapeDurationSecondsMetric.Describe(ch)
+}
+
+// reportJobInstancesMetrics compares, per instance group, the number of
+// instances present with the number implied by the highest index seen, so a
+// missing index (e.g. after a failed deploy) shows up as a gap. It ignores the
+// AZs filter, as indexes are assigned across all AZs of an instance group.
+func (c *JobsCollector) reportJobInstancesMetrics(deployment deployments.DeploymentInfo) {
+	instancesExpected := make(map[string]int)
+	instancesPresent := make(map[string]int)
+
+	for _, instance := range deployment.Instances {
+		instancesPresent[instance.Name]++
+
+		index, err := strconv.Atoi(instance.Index)
+		if err != nil {
+			continue
+		}
+		if index+1 > instancesExpected[instance.Name] {
+			instancesExpected[instance.Name] = index + 1
+		}
+	}
+
+	for jobName, present := range instancesPresent {
+		c.jobInstancesExpectedMetric.WithLabelValues(deployment.Name, jobName).Set(float64(instancesExpected[jobName]))
+		c.jobInstancesPresentMetric.WithLabelValues(deployment.Name, jobName).Set(float64(present))
+	}
+}
+
+// instanceGroupVitals accumulates the vitals of the instances of an instance
+// group. Values that cannot be parsed are left out of the aggregations.
+type instanceGroupVitals struct {
+	cpuSys           []float64
+	cpuUser          []float64
+	cpuWait          []float64
+	memPercentMax    *float64
+	processesFailing int
+}
 
-	for _, deployment := range deployments {
-		err = c.reportJobMetrics(deployment, ch)
+// reportInstanceGroupMetrics aggregates the vitals of the instances of each
+// instance group (honoring the AZs filter), so dashboards do not need
+// recording rules over the per-instance series. Instances without a VM are
+// left out, and the only unhealthy option does not apply.
+func (c *JobsCollector) reportInstanceGroupMetrics(deployment deployments.DeploymentInfo) {
+	instanceGroups := make(map[string]*instanceGroupVitals)
+
+	for _, instance := range deployment.Instances {
+		if !c.azsFilter.Enabled(instance.AZ) || instance.NoVM {
+			continue
+		}
+
+		vitals, ok := instanceGroups[instance.Name]
+		if !ok {
+			vitals = &instanceGroupVitals{}
+			instanceGroups[instance.Name] = vitals
+		}
+
+		if cpuSys, err := strconv.ParseFloat(instance.Vitals.CPU.Sys, 64); err == nil {
+			vitals.cpuSys = append(vitals.cpuSys, cpuSys)
+		}
+		if cpuUser, err := strconv.ParseFloat(instance.Vitals.CPU.User, 64); err == nil {
+			vitals.cpuUser = append(vitals.cpuUser, cpuUser)
+		}
+		if cpuWait, err := strconv.ParseFloat(instance.Vitals.CPU.Wait, 64); err == nil {
+			vitals.cpuWait = append(vitals.cpuWait, cpuWait)
+		}
+		if memPercent, err := strconv.ParseFloat(instance.Vitals.Mem.Percent, 64); err == nil {
+			if vitals.memPercentMax == nil || memPercent > *vitals.memPercentMax {
+				vitals.memPercentMax = &memPercent
+			}
+		}
+
+		vitals.processesFailing += instance.ExcludedFailingProcesses
+		for _, process := range instance.Processes {
+			if !process.Healthy {
+				vitals.processesFailing++
+			}
+		}
 	}
 
-	c.jobHealthyMetric.Collect(ch)
-	c.jobLoadAvg01Metric.Collect(ch)
-	c.jobLoadAvg05Metric.Collect(ch)
-	c.jobLoadAvg15Metric.Collect(ch)
-	c.jobCPUSysMetric.Collect(ch)
-	c.jobCPUUserMetric.Collect(ch)
-	c.jobCPUWaitMetric.Collect(ch)
-	c.jobMemKBMetric.Collect(ch)
-	c.jobMemPercentMetric.Collect(ch)
-	c.jobSwapKBMetric.Collect(ch)
-	c.jobSwapPercentMetric.Collect(ch)
-	c.jobSystemDiskInodePercentMetric.Collect(ch)
-	c.jobSystemDiskPercentMetric.Collect(ch)
-	c.jobEphemeralDiskInodePercentMetric.Collect(ch)
-	c.jobEphemeralDiskPercentMetric.Collect(ch)
-	c.jobPersistentDiskInodePercentMetric.Collect(ch)
-	c.jobPersistentDiskPercentMetric.Collect(ch)
-	c.jobProcessHealthyMetric.Collect(ch)
-	c.jobProcessUptimeMetric.Collect(ch)
-	c.jobProcessCPUTotalMetric.Collect(ch)
-	c.jobProcessMemKBMetric.Collect(ch)
-	c.jobProcessMemPercentMetric.Collect(ch)
+	for jobName, vitals := range instanceGroups {
+		if len(vitals.cpuSys) > 0 {
+			c.instanceGroupCPUSysAvgMetric.WithLabelValues(deployment.Name, jobName).Set(average(vitals.cpuSys))
+		}
+		if len(vitals.cpuUser) > 0 {
+			c.instanceGroupCPUUserAvgMetric.WithLabelValues(deployment.Name, jobName).Set(average(vitals.cpuUser))
+		}
+		if len(vitals.cpuWait) > 0 {
+			c.instanceGroupCPUWaitAvgMetric.WithLabelValues(deployment.Name, jobName).Set(average(vitals.cpuWait))
+		}
+		if vitals.memPercentMax != nil {
+			c.instanceGroupMemPercentMaxMetric.WithLabelValues(deployment.Name, jobName).Set(*vitals.memPercentMax)
+		}
+		c.instanceGroupProcessesFailingMetric.WithLabelValues(deployment.Name, jobName).Set(float64(vitals.processesFailing))
+	}
+}
 
-	c.lastJobsScrapeTimestampMetric.Set(float64(time.Now().Unix()))
-	c.lastJobsScrapeTimestampMetric.Collect(ch)
+func average(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
 
-	c.lastJobsScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
-	c.lastJobsScrapeDurationSecondsMetric.Collect(ch)
+	return sum / float64(len(values))
+}
 
-	return err
+// jobsCollectorWorkers returns the number of workers reporting deployments
+// concurrently, at most one per CPU.
+func jobsCollectorWorkers(deploymentsCount int) int {
+	workers := runtime.GOMAXPROCS(0)
+	if deploymentsCount < workers {
+		return deploymentsCount
+	}
+
+	return workers
 }
 
-func (c *JobsCollector) Describe(ch chan<- *prometheus.Desc) {
-	c.jobHealthyMetric.Describe(ch)
-	c.jobLoadAvg01Metric.Describe(ch)
-	c.jobLoadAvg05Metric.Describe(ch)
-	c.jobLoadAvg15Metric.Describe(ch)
-	c.jobCPUSysMetric.Describe(ch)
-	c.jobCPUUserMetric.Describe(ch)
-	c.jobCPUWaitMetric.Describe(ch)
-	c.jobMemKBMetric.Describe(ch)
-	c.jobMemPercentMetric.Describe(ch)
-	c.jobSwapKBMetric.Describe(ch)
-	c.jobSwapPercentMetric.Describe(ch)
-	c.jobSystemDiskInodePercentMetric.Describe(ch)
-	c.jobSystemDiskPercentMetric.Describe(ch)
-	c.jobEphemeralDiskInodePercentMetric.Describe(ch)
-	c.jobEphemeralDiskPercentMetric.Describe(ch)
-	c.jobPersistentDiskInodePercentMetric.Describe(ch)
-	c.jobPersistentDiskPercentMetric.Describe(ch)
-	c.jobProcessHealthyMetric.Describe(ch)
-	c.jobProcessUptimeMetric.Describe(ch)
-	c.jobProcessCPUTotalMetric.Describe(ch)
-	c.jobProcessMemKBMetric.Describe(ch)
-	c.jobProcessMemPercentMetric.Describe(ch)
-	c.lastJobsScrapeTimestampMetric.Describe(ch)
-	c.lastJobsScrapeDurationSecondsMetric.Describe(ch)
+func (c *JobsCollector) reportDeploymentMetrics(deployment deployments.DeploymentInfo, now time.Time, ch chan<- prometheus.Metric) error {
+	c.reportJobInstancesMetrics(deployment)
+	if c.instanceGroupMetrics {
+		c.reportInstanceGroupMetrics(deployment)
+	}
+
+	return c.reportJobMetrics(deployment, now, ch)
 }
 
-func (c *JobsCollector) reportJobMetrics(deployment deployments.DeploymentInfo, ch chan<- prometheus.Metric) error {
+// reportJobMetrics computes the start time metrics from the uptimes as of now,
+// the time of the collection.
+func (c *JobsCollector) reportJobMetrics(deployment deployments.DeploymentInfo, now time.Time, ch chan<- prometheus.Metric) error {
 	var err error
 
 	for _, instance := range deployment.Instances {
@@ -542,21 +1097,44 @@ func (c *JobsCollector) reportJobMetrics(deployment deployments.DeploymentInfo,
 		jobIndex := instance.Index
 		jobAZ := instance.AZ
 		jobIP, _ := c.cidrsFilter.Select(instance.IPs)
+		jobVMType := instance.VMType
 
 		err = c.jobHealthyMetrics(ch, instance.Healthy, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobLoadAvgMetrics(ch, instance.Vitals.Load, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobCPUMetrics(ch, instance.Vitals.CPU, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobMemMetrics(ch, instance.Vitals.Mem, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobSwapMetrics(ch, instance.Vitals.Swap, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobSystemDiskMetrics(ch, instance.Vitals.SystemDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobEphemeralDiskMetrics(ch, instance.Vitals.EphemeralDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
-		err = c.jobPersistentDiskMetrics(ch, instance.Vitals.PersistentDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
+		err = c.jobResurrectionPausedMetrics(ch, instance.ResurrectionPaused, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
+		err = c.jobIgnoreMetrics(ch, instance.Ignore, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
+		c.jobInstanceInfoMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, instance.AgentID, instance.VMID, instance.State).Set(float64(1))
+
+		if instance.UnhealthyReason != "" {
+			c.jobUnhealthyInfoMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, instance.UnhealthyReason).Set(float64(1))
+		}
+
+		if instance.NoVM {
+			c.jobNoVMInfoMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ).Set(float64(1))
+			continue
+		}
+
+		if c.onlyUnhealthy && instance.Healthy {
+			continue
+		}
+
+		err = c.jobLoadAvgMetrics(ch, instance.Vitals.Load, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobCPUMetrics(ch, instance.Vitals.CPU, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobMemMetrics(ch, instance.Vitals.Mem, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobSwapMetrics(ch, instance.Vitals.Swap, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobSystemDiskMetrics(ch, instance.Vitals.SystemDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobEphemeralDiskMetrics(ch, instance.Vitals.EphemeralDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobPersistentDiskMetrics(ch, instance.Vitals.PersistentDisk, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobStartTimeMetrics(ch, instance.Vitals.Uptime, now, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+		err = c.jobVMCreatedAtMetrics(ch, instance.VMCreatedAt, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobVMType)
+
+		err = c.jobProcessesMetrics(ch, instance, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP)
 
 		for _, process := range instance.Processes {
 			jobProcessName := process.Name
 
 			err = c.jobProcessHealthyMetrics(ch, process.Healthy, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobProcessName)
 			err = c.jobProcessUptimeMetrics(ch, process.Uptime, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobProcessName)
+			err = c.jobProcessStartTimeMetrics(ch, process.Uptime, now, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobProcessName)
 			err = c.jobProcessCPUMetrics(ch, process.CPU, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobProcessName)
 			err = c.jobProcessMemMetrics(ch, process.Mem, deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP, jobProcessName)
 		}
@@ -592,6 +1170,60 @@ func (c *JobsCollector) jobHealthyMetrics(
 	return nil
 }
 
+func (c *JobsCollector) jobResurrectionPausedMetrics(
+	ch chan<- prometheus.Metric,
+	resurrectionPaused bool,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+) error {
+	var resurrectionPausedMetric float64
+	if resurrectionPaused {
+		resurrectionPausedMetric = 1
+	}
+
+	c.jobResurrectionPausedMetric.WithLabelValues(
+		deploymentName,
+		jobName,
+		jobID,
+		jobIndex,
+		jobAZ,
+		jobIP,
+	).Set(resurrectionPausedMetric)
+
+	return nil
+}
+
+func (c *JobsCollector) jobIgnoreMetrics(
+	ch chan<- prometheus.Metric,
+	ignore bool,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+) error {
+	var ignoreMetric float64
+	if ignore {
+		ignoreMetric = 1
+	}
+
+	c.jobIgnoreMetric.WithLabelValues(
+		deploymentName,
+		jobName,
+		jobID,
+		jobIndex,
+		jobAZ,
+		jobIP,
+	).Set(ignoreMetric)
+
+	return nil
+}
+
 func (c *JobsCollector) jobLoadAvgMetrics(
 	ch chan<- prometheus.Metric,
 	loadAvg []string,
@@ -601,57 +1233,59 @@ func (c *JobsCollector) jobLoadAvgMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
-	if len(loadAvg) == 3 {
-		if loadAvg[0] != "" {
-			loadAvg01, err := strconv.ParseFloat(loadAvg[0], 64)
-			if err != nil {
-				err = errors.New(fmt.Sprintf("Error while converting Load avg01 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
-			} else {
-				c.jobLoadAvg01Metric.WithLabelValues(
-					deploymentName,
-					jobName,
-					jobID,
-					jobIndex,
-					jobAZ,
-					jobIP,
-				).Set(float64(loadAvg01))
-			}
+	if len(loadAvg) > 0 && loadAvg[0] != "" {
+		loadAvg01, err := strconv.ParseFloat(loadAvg[0], 64)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Error while converting Load avg01 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
+		} else {
+			c.jobLoadAvg01Metric.WithLabelValues(c.vitalsLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobVMType,
+			)...).Set(float64(loadAvg01))
 		}
+	}
 
-		if loadAvg[1] != "" {
-			loadAvg05, err := strconv.ParseFloat(loadAvg[1], 64)
-			if err != nil {
-				err = errors.New(fmt.Sprintf("Error while converting Load avg05 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
-			} else {
-				c.jobLoadAvg05Metric.WithLabelValues(
-					deploymentName,
-					jobName,
-					jobID,
-					jobIndex,
-					jobAZ,
-					jobIP,
-				).Set(float64(loadAvg05))
+	if len(loadAvg) > 1 && loadAvg[1] != "" {
+		loadAvg05, err := strconv.ParseFloat(loadAvg[1], 64)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Error while converting Load avg05 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
+		} else {
+			c.jobLoadAvg05Metric.WithLabelValues(c.vitalsLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobVMType,
+			)...).Set(float64(loadAvg05))
 
-			}
 		}
+	}
 
-		if loadAvg[2] != "" {
-			loadAvg15, err := strconv.ParseFloat(loadAvg[2], 64)
-			if err != nil {
-				err = errors.New(fmt.Sprintf("Error while converting Load avg15 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
-			} else {
-				c.jobLoadAvg15Metric.WithLabelValues(
-					deploymentName,
-					jobName,
-					jobID,
-					jobIndex,
-					jobAZ,
-					jobIP,
-				).Set(float64(loadAvg15))
-			}
+	if len(loadAvg) > 2 && loadAvg[2] != "" {
+		loadAvg15, err := strconv.ParseFloat(loadAvg[2], 64)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Error while converting Load avg15 metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
+		} else {
+			c.jobLoadAvg15Metric.WithLabelValues(c.vitalsLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobVMType,
+			)...).Set(float64(loadAvg15))
 		}
 	}
 
@@ -667,6 +1301,7 @@ func (c *JobsCollector) jobCPUMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -675,14 +1310,15 @@ func (c *JobsCollector) jobCPUMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting CPU Sys metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobCPUSysMetric.WithLabelValues(
+			c.jobCPUSysMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(cpuSys)
+				jobVMType,
+			)...).Set(cpuSys)
 		}
 	}
 
@@ -691,14 +1327,15 @@ func (c *JobsCollector) jobCPUMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting CPU User metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobCPUUserMetric.WithLabelValues(
+			c.jobCPUUserMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(cpuUser)
+				jobVMType,
+			)...).Set(cpuUser)
 		}
 	}
 
@@ -707,14 +1344,15 @@ func (c *JobsCollector) jobCPUMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting CPU Wait metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobCPUWaitMetric.WithLabelValues(
+			c.jobCPUWaitMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(cpuWait)
+				jobVMType,
+			)...).Set(cpuWait)
 		}
 	}
 
@@ -730,6 +1368,7 @@ func (c *JobsCollector) jobMemMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -738,14 +1377,15 @@ func (c *JobsCollector) jobMemMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Mem KB metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobMemKBMetric.WithLabelValues(
+			c.jobMemKBMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(memKB)
+				jobVMType,
+			)...).Set(memKB)
 		}
 	}
 
@@ -754,14 +1394,15 @@ func (c *JobsCollector) jobMemMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Mem Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobMemPercentMetric.WithLabelValues(
+			c.jobMemPercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(memPercent)
+				jobVMType,
+			)...).Set(memPercent)
 		}
 	}
 
@@ -777,6 +1418,7 @@ func (c *JobsCollector) jobSwapMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -785,14 +1427,29 @@ func (c *JobsCollector) jobSwapMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Swap KB metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobSwapKBMetric.WithLabelValues(
+			c.jobSwapKBMetric.WithLabelValues(c.vitalsLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobVMType,
+			)...).Set(swapKB)
+
+			swapActive := 0
+			if swapKB > 0 {
+				swapActive = 1
+			}
+			c.jobSwapActiveMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(swapKB)
+				jobVMType,
+			)...).Set(float64(swapActive))
 		}
 	}
 
@@ -801,14 +1458,15 @@ func (c *JobsCollector) jobSwapMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Swap Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobSwapPercentMetric.WithLabelValues(
+			c.jobSwapPercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(swapPercent)
+				jobVMType,
+			)...).Set(swapPercent)
 		}
 	}
 
@@ -824,6 +1482,7 @@ func (c *JobsCollector) jobSystemDiskMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -832,14 +1491,15 @@ func (c *JobsCollector) jobSystemDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting System Disk Inode Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobSystemDiskInodePercentMetric.WithLabelValues(
+			c.jobSystemDiskInodePercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(systemDiskInodePercent)
+				jobVMType,
+			)...).Set(systemDiskInodePercent)
 		}
 	}
 
@@ -848,14 +1508,15 @@ func (c *JobsCollector) jobSystemDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting System Disk Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobSystemDiskPercentMetric.WithLabelValues(
+			c.jobSystemDiskPercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(systemDiskPercent)
+				jobVMType,
+			)...).Set(systemDiskPercent)
 		}
 	}
 
@@ -871,6 +1532,7 @@ func (c *JobsCollector) jobEphemeralDiskMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -879,14 +1541,15 @@ func (c *JobsCollector) jobEphemeralDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Ephemeral Disk Inode Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobEphemeralDiskInodePercentMetric.WithLabelValues(
+			c.jobEphemeralDiskInodePercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(ephemeralDiskInodePercent)
+				jobVMType,
+			)...).Set(ephemeralDiskInodePercent)
 		}
 	}
 
@@ -895,14 +1558,15 @@ func (c *JobsCollector) jobEphemeralDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Ephemeral Disk Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobEphemeralDiskPercentMetric.WithLabelValues(
+			c.jobEphemeralDiskPercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(ephemeralDiskPercent)
+				jobVMType,
+			)...).Set(ephemeralDiskPercent)
 		}
 	}
 
@@ -918,6 +1582,7 @@ func (c *JobsCollector) jobPersistentDiskMetrics(
 	jobIndex string,
 	jobAZ string,
 	jobIP string,
+	jobVMType string,
 ) error {
 	var err error
 
@@ -926,14 +1591,15 @@ func (c *JobsCollector) jobPersistentDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Persistent Disk Inode Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobPersistentDiskInodePercentMetric.WithLabelValues(
+			c.jobPersistentDiskInodePercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(persistentDiskInodePercent)
+				jobVMType,
+			)...).Set(persistentDiskInodePercent)
 		}
 	}
 
@@ -942,20 +1608,137 @@ func (c *JobsCollector) jobPersistentDiskMetrics(
 		if err != nil {
 			err = errors.New(fmt.Sprintf("Error while converting Persistent Disk Percent metric for deployment `%s` and job `%s`: %v", deploymentName, jobName, err))
 		} else {
-			c.jobPersistentDiskPercentMetric.WithLabelValues(
+			c.jobPersistentDiskPercentMetric.WithLabelValues(c.vitalsLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-			).Set(persistentDiskPercent)
+				jobVMType,
+			)...).Set(persistentDiskPercent)
 		}
 	}
 
 	return err
 }
 
+func (c *JobsCollector) jobStartTimeMetrics(
+	ch chan<- prometheus.Metric,
+	uptime *uint64,
+	now time.Time,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+	jobVMType string,
+) error {
+	if uptime != nil {
+		c.jobStartTimeMetric.WithLabelValues(c.vitalsLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+			jobVMType,
+		)...).Set(startTime(now, *uptime))
+	}
+
+	return nil
+}
+
+func (c *JobsCollector) jobVMCreatedAtMetrics(
+	ch chan<- prometheus.Metric,
+	vmCreatedAt *time.Time,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+	jobVMType string,
+) error {
+	if vmCreatedAt != nil {
+		c.jobVMCreatedAtMetric.WithLabelValues(c.vitalsLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+			jobVMType,
+		)...).Set(float64(vmCreatedAt.Unix()))
+	}
+
+	return nil
+}
+
+// vitalsLabelValues returns the label values of the Job vitals metrics. The VM
+// type is only included when the bosh_job_vm_type label is enabled.
+func (c *JobsCollector) vitalsLabelValues(
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+	jobVMType string,
+) []string {
+	labelValues := []string{deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP}
+	if c.vitalsVMTypeLabel {
+		labelValues = append(labelValues, jobVMType)
+	}
+
+	return labelValues
+}
+
+// startTime returns the time in seconds since 1970 an uptime reported by the
+// agent started at. Unlike the uptime, it stays constant until a restart.
+func startTime(now time.Time, uptime uint64) float64 {
+	return float64(now.Add(-time.Duration(uptime) * time.Second).Unix())
+}
+
+func (c *JobsCollector) jobProcessesMetrics(
+	ch chan<- prometheus.Metric,
+	instance deployments.Instance,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+) error {
+	failing := instance.ExcludedFailingProcesses
+	for _, process := range instance.Processes {
+		if !process.Healthy {
+			failing++
+		}
+	}
+
+	c.jobProcessesMetric.WithLabelValues(
+		deploymentName,
+		jobName,
+		jobID,
+		jobIndex,
+		jobAZ,
+		jobIP,
+	).Set(float64(len(instance.Processes) + instance.ExcludedProcesses))
+
+	c.jobProcessesFailingMetric.WithLabelValues(
+		deploymentName,
+		jobName,
+		jobID,
+		jobIndex,
+		jobAZ,
+		jobIP,
+	).Set(float64(failing))
+
+	return nil
+}
+
 func (c *JobsCollector) jobProcessHealthyMetrics(
 	ch chan<- prometheus.Metric,
 	healthy bool,
@@ -1011,6 +1794,33 @@ func (c *JobsCollector) jobProcessUptimeMetrics(
 	return nil
 }
 
+func (c *JobsCollector) jobProcessStartTimeMetrics(
+	ch chan<- prometheus.Metric,
+	uptime *uint64,
+	now time.Time,
+	deploymentName string,
+	jobName string,
+	jobID string,
+	jobIndex string,
+	jobAZ string,
+	jobIP string,
+	jobProcessName string,
+) error {
+	if uptime != nil {
+		c.jobProcessStartTimeMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+			jobProcessName,
+		).Set(startTime(now, *uptime))
+	}
+
+	return nil
+}
+
 func (c *JobsCollector) jobProcessCPUMetrics(
 	ch chan<- prometheus.Metric,
 	cpu deployments.CPU,
@@ -1022,6 +1832,8 @@ func (c *JobsCollector) jobProcessCPUMetrics(
 	jobIP string,
 	jobProcessName string,
 ) error {
+	var err error
+
 	if cpu.Total != nil {
 		c.jobProcessCPUTotalMetric.WithLabelValues(
 			deploymentName,
@@ -1034,7 +1846,41 @@ func (c *JobsCollector) jobProcessCPUMetrics(
 		).Set(float64(*cpu.Total))
 	}
 
-	return nil
+	if cpu.User != "" {
+		cpuUser, parseErr := strconv.ParseFloat(cpu.User, 64)
+		if parseErr != nil {
+			err = errors.New(fmt.Sprintf("Error while converting Process CPU User metric for deployment `%s`, job `%s` and process `%s`: %v", deploymentName, jobName, jobProcessName, parseErr))
+		} else {
+			c.jobProcessCPUUserMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Set(cpuUser)
+		}
+	}
+
+	if cpu.Sys != "" {
+		cpuSys, parseErr := strconv.ParseFloat(cpu.Sys, 64)
+		if parseErr != nil {
+			err = errors.New(fmt.Sprintf("Error while converting Process CPU Sys metric for deployment `%s`, job `%s` and process `%s`: %v", deploymentName, jobName, jobProcessName, parseErr))
+		} else {
+			c.jobProcessCPUSysMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Set(cpuSys)
+		}
+	}
+
+	return err
 }
 
 func (c *JobsCollector) jobProcessMemMetrics(
@@ -1,12 +1,16 @@
 package collectors_test
 
 import (
+	"fmt"
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/log"
 
 	"github.com/bosh-prometheus/bosh_exporter/deployments"
@@ -20,18 +24,45 @@ func init() {
 	log.Base().SetLevel("fatal")
 }
 
+// startTimeMetric matches a start time metric of desc set to uptime seconds
+// ago, allowing for the time the collection took.
+func startTimeMetric(desc *prometheus.Desc, uptime uint64) types.GomegaMatcher {
+	return SatisfyAll(
+		WithTransform(func(metric prometheus.Metric) string {
+			return metric.Desc().String()
+		}, Equal(desc.String())),
+		WithTransform(func(metric prometheus.Metric) float64 {
+			dtoMetric := &dto.Metric{}
+			Expect(metric.Write(dtoMetric)).To(Succeed())
+			return dtoMetric.GetGauge().GetValue()
+		}, BeNumerically("~", float64(time.Now().Unix())-float64(uptime), 5)),
+	)
+}
+
 var _ = Describe("JobsCollector", func() {
 	var (
-		err           error
-		namespace     string
-		environment   string
-		boshName      string
-		boshUUID      string
-		azsFilter     *filters.AZsFilter
-		cidrsFilter   *filters.CidrFilter
-		jobsCollector *JobsCollector
+		err                  error
+		namespace            string
+		environment          string
+		boshName             string
+		boshUUID             string
+		azsFilter            *filters.AZsFilter
+		cidrsFilter          *filters.CidrFilter
+		onlyUnhealthy        bool
+		instanceInfoMetrics  bool
+		instanceGroupMetrics bool
+		vitalsVMTypeLabel    bool
+		metricsFilter        *filters.MetricsFilter
+		jobsCollector        *JobsCollector
 
 		jobHealthyMetric                    *prometheus.GaugeVec
+		jobIgnoreMetric                     *prometheus.GaugeVec
+		jobResurrectionPausedMetric         *prometheus.GaugeVec
+		jobInstanceInfoMetric               *prometheus.GaugeVec
+		jobNoVMInfoMetric                   *prometheus.GaugeVec
+		jobUnhealthyInfoMetric              *prometheus.GaugeVec
+		jobInstancesExpectedMetric          *prometheus.GaugeVec
+		jobInstancesPresentMetric           *prometheus.GaugeVec
 		jobLoadAvg01Metric                  *prometheus.GaugeVec
 		jobLoadAvg05Metric                  *prometheus.GaugeVec
 		jobLoadAvg15Metric                  *prometheus.GaugeVec
@@ -42,17 +73,30 @@ var _ = Describe("JobsCollector", func() {
 		jobMemPercentMetric                 *prometheus.GaugeVec
 		jobSwapKBMetric                     *prometheus.GaugeVec
 		jobSwapPercentMetric                *prometheus.GaugeVec
+		jobSwapActiveMetric                 *prometheus.GaugeVec
 		jobSystemDiskInodePercentMetric     *prometheus.GaugeVec
 		jobSystemDiskPercentMetric          *prometheus.GaugeVec
 		jobEphemeralDiskInodePercentMetric  *prometheus.GaugeVec
 		jobEphemeralDiskPercentMetric       *prometheus.GaugeVec
 		jobPersistentDiskInodePercentMetric *prometheus.GaugeVec
 		jobPersistentDiskPercentMetric      *prometheus.GaugeVec
+		jobStartTimeMetric                  *prometheus.GaugeVec
+		jobVMCreatedAtMetric                *prometheus.GaugeVec
+		jobProcessesMetric                  *prometheus.GaugeVec
+		jobProcessesFailingMetric           *prometheus.GaugeVec
 		jobProcessHealthyMetric             *prometheus.GaugeVec
 		jobProcessUptimeMetric              *prometheus.GaugeVec
+		jobProcessStartTimeMetric           *prometheus.GaugeVec
 		jobProcessCPUTotalMetric            *prometheus.GaugeVec
+		jobProcessCPUUserMetric             *prometheus.GaugeVec
+		jobProcessCPUSysMetric              *prometheus.GaugeVec
 		jobProcessMemKBMetric               *prometheus.GaugeVec
 		jobProcessMemPercentMetric          *prometheus.GaugeVec
+		instanceGroupCPUSysAvgMetric        *prometheus.GaugeVec
+		instanceGroupCPUUserAvgMetric       *prometheus.GaugeVec
+		instanceGroupCPUWaitAvgMetric       *prometheus.GaugeVec
+		instanceGroupMemPercentMaxMetric    *prometheus.GaugeVec
+		instanceGroupProcessesFailingMetric *prometheus.GaugeVec
 		lastJobsScrapeTimestampMetric       prometheus.Gauge
 		lastJobsScrapeDurationSecondsMetric prometheus.Gauge
 
@@ -62,6 +106,10 @@ var _ = Describe("JobsCollector", func() {
 		jobIndex                      = "0"
 		jobIP                         = "1.2.3.4"
 		jobAZ                         = "fake-job-az"
+		jobAgentID                    = "fake-job-agent-id"
+		jobVMID                       = "fake-job-vm-cid"
+		jobState                      = "started"
+		jobVMType                     = "fake-job-vm-type"
 		jobHealthy                    = true
 		jobCPUSys                     = float64(0.5)
 		jobCPUUser                    = float64(1.0)
@@ -79,10 +127,14 @@ var _ = Describe("JobsCollector", func() {
 		jobEphemeralDiskPercent       = 40
 		jobPersistentDiskInodePercent = 50
 		jobPersistentDiskPercent      = 60
+		jobUptime                     = uint64(7200)
+		jobVMCreatedAt                = time.Unix(1700000000, 0)
 		jobProcessName                = "fake-process-name"
 		jobProcessUptime              = uint64(3600)
 		jobProcessHealthy             = true
 		jobProcessCPUTotal            = float64(0.5)
+		jobProcessCPUUser             = float64(0.3)
+		jobProcessCPUSys              = float64(0.2)
 		jobProcessMemKB               = uint64(2000)
 		jobProcessMemPercent          = float64(20)
 	)
@@ -95,6 +147,12 @@ var _ = Describe("JobsCollector", func() {
 		azsFilter = filters.NewAZsFilter([]string{})
 		cidrsFilter, err = filters.NewCidrFilter([]string{"0.0.0.0/0"})
 		Expect(err).ToNot(HaveOccurred())
+		onlyUnhealthy = false
+		instanceInfoMetrics = false
+		instanceGroupMetrics = false
+		vitalsVMTypeLabel = false
+		metricsFilter, err = filters.NewMetricsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
 
 		jobHealthyMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -120,6 +178,144 @@ var _ = Describe("JobsCollector", func() {
 			jobIP,
 		).Set(float64(1))
 
+		jobResurrectionPausedMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "resurrection_paused",
+				Help:      "BOSH Job Resurrection Paused (1 for paused, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobResurrectionPausedMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(0))
+
+		jobIgnoreMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "ignore",
+				Help:      "BOSH Job Ignore (1 when the instance is ignored during deploys, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobIgnoreMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(0))
+
+		jobInstanceInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "instance_info",
+				Help:      "Labeled BOSH Job Instance Info with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_agent_id", "bosh_job_vm_cid", "bosh_job_state"},
+		)
+
+		jobInstanceInfoMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobAgentID,
+			jobVMID,
+			jobState,
+		).Set(float64(1))
+
+		jobNoVMInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "novm_info",
+				Help:      "Labeled BOSH Job without a VM with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az"},
+		)
+
+		jobUnhealthyInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "unhealthy_info",
+				Help:      "Labeled BOSH Job reported as unhealthy for a known reason with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "reason"},
+		)
+
+		jobInstancesExpectedMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "instances_expected",
+				Help:      "Number of BOSH Job instances expected from the highest instance index.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		jobInstancesExpectedMetric.WithLabelValues(deploymentName, jobName).Set(float64(1))
+
+		jobInstancesPresentMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "instances_present",
+				Help:      "Number of BOSH Job instances present.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		jobInstancesPresentMetric.WithLabelValues(deploymentName, jobName).Set(float64(1))
+
 		jobLoadAvg01Metric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -360,6 +556,30 @@ var _ = Describe("JobsCollector", func() {
 			jobIP,
 		).Set(float64(jobSwapPercent))
 
+		jobSwapActiveMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "swap_active",
+				Help:      "BOSH Job Swap Active (1 when swap is in use, 0 otherwise).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobSwapActiveMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(1))
+
 		jobSystemDiskInodePercentMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -504,6 +724,93 @@ var _ = Describe("JobsCollector", func() {
 			jobIP,
 		).Set(float64(jobPersistentDiskPercent))
 
+		jobStartTimeMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "start_time_seconds",
+				Help:      "BOSH Job start time in seconds since 1970, computed from the Job Uptime.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobVMCreatedAtMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "vm_created_at_seconds",
+				Help:      "BOSH Job VM creation time in seconds since 1970.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobVMCreatedAtMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(jobVMCreatedAt.Unix()))
+
+		jobProcessesMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "processes_total",
+				Help:      "Number of BOSH Job Processes.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobProcessesMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(1))
+
+		jobProcessesFailingMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "processes_failing_total",
+				Help:      "Number of unhealthy BOSH Job Processes.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip"},
+		)
+
+		jobProcessesFailingMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+		).Set(float64(0))
+
 		jobProcessHealthyMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -554,6 +861,21 @@ var _ = Describe("JobsCollector", func() {
 			jobProcessName,
 		).Set(float64(jobProcessUptime))
 
+		jobProcessStartTimeMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job_process",
+				Name:      "start_time_seconds",
+				Help:      "BOSH Job Process start time in seconds since 1970, computed from the Job Process Uptime.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+		)
+
 		jobProcessCPUTotalMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -579,6 +901,56 @@ var _ = Describe("JobsCollector", func() {
 			jobProcessName,
 		).Set(jobProcessCPUTotal)
 
+		jobProcessCPUUserMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job_process",
+				Name:      "cpu_user",
+				Help:      "BOSH Job Process CPU User.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+		)
+
+		jobProcessCPUUserMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+			jobProcessName,
+		).Set(jobProcessCPUUser)
+
+		jobProcessCPUSysMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job_process",
+				Name:      "cpu_sys",
+				Help:      "BOSH Job Process CPU System.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_process_name"},
+		)
+
+		jobProcessCPUSysMetric.WithLabelValues(
+			deploymentName,
+			jobName,
+			jobID,
+			jobIndex,
+			jobAZ,
+			jobIP,
+			jobProcessName,
+		).Set(jobProcessCPUSys)
+
 		jobProcessMemKBMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -629,24 +1001,99 @@ var _ = Describe("JobsCollector", func() {
 			jobProcessName,
 		).Set(jobProcessMemPercent)
 
-		lastJobsScrapeTimestampMetric = prometheus.NewGauge(
+		instanceGroupCPUSysAvgMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
-				Subsystem: "",
-				Name:      "last_jobs_scrape_timestamp",
-				Help:      "Number of seconds since 1970 since last scrape of Job metrics from BOSH.",
+				Subsystem: "instance_group",
+				Name:      "cpu_sys_avg",
+				Help:      "Average BOSH Job CPU System of the instances in this instance group.",
 				ConstLabels: prometheus.Labels{
 					"environment": environment,
 					"bosh_name":   boshName,
 					"bosh_uuid":   boshUUID,
 				},
 			},
+			[]string{"bosh_deployment", "bosh_job_name"},
 		)
 
-		lastJobsScrapeDurationSecondsMetric = prometheus.NewGauge(
+		instanceGroupCPUUserAvgMetric = prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
-				Subsystem: "",
+				Subsystem: "instance_group",
+				Name:      "cpu_user_avg",
+				Help:      "Average BOSH Job CPU User of the instances in this instance group.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		instanceGroupCPUWaitAvgMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "instance_group",
+				Name:      "cpu_wait_avg",
+				Help:      "Average BOSH Job CPU Wait of the instances in this instance group.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		instanceGroupMemPercentMaxMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "instance_group",
+				Name:      "mem_percent_max",
+				Help:      "Maximum BOSH Job Memory Percent of the instances in this instance group.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		instanceGroupProcessesFailingMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "instance_group",
+				Name:      "processes_failing_total",
+				Help:      "Number of failing BOSH Job Processes of the instances in this instance group.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		lastJobsScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_jobs_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Job metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastJobsScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
 				Name:      "last_jobs_scrape_duration_seconds",
 				Help:      "Duration of the last scrape of Job metrics from BOSH.",
 				ConstLabels: prometheus.Labels{
@@ -659,7 +1106,7 @@ var _ = Describe("JobsCollector", func() {
 	})
 
 	JustBeforeEach(func() {
-		jobsCollector = NewJobsCollector(namespace, environment, boshName, boshUUID, azsFilter, cidrsFilter)
+		jobsCollector = NewJobsCollector(namespace, environment, boshName, boshUUID, azsFilter, cidrsFilter, onlyUnhealthy, instanceInfoMetrics, instanceGroupMetrics, vitalsVMTypeLabel, metricsFilter)
 	})
 
 	Describe("Describe", func() {
@@ -675,6 +1122,46 @@ var _ = Describe("JobsCollector", func() {
 			go jobsCollector.Describe(descriptions)
 		})
 
+		Context("when metrics are filtered", func() {
+			BeforeEach(func() {
+				metricsFilter, err = filters.NewMetricsFilter([]string{"job_cpu_*"}, []string{"job_cpu_wait"})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns a job_cpu_sys metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(jobCPUSysMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Desc())))
+			})
+
+			It("does not return an excluded job_cpu_wait metric description", func() {
+				Consistently(descriptions).ShouldNot(Receive(Equal(jobCPUWaitMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Desc())))
+			})
+
+			It("does not return a job_mem_kb metric description not included", func() {
+				Consistently(descriptions).ShouldNot(Receive(Equal(jobMemKBMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Desc())))
+			})
+		})
+
 		It("returns a job_healthy metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobHealthyMetric.WithLabelValues(
 				deploymentName,
@@ -686,6 +1173,115 @@ var _ = Describe("JobsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a job_resurrection_paused metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobResurrectionPausedMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
+		It("returns a job_ignore metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobIgnoreMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
+		It("does not return a job_instance_info metric description", func() {
+			Consistently(descriptions).ShouldNot(Receive(Equal(jobInstanceInfoMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobAgentID,
+				jobVMID,
+				jobState,
+			).Desc())))
+		})
+
+		Context("when instance group metrics are enabled", func() {
+			BeforeEach(func() {
+				instanceGroupMetrics = true
+			})
+
+			It("returns an instance_group_cpu_sys_avg metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(instanceGroupCPUSysAvgMetric.WithLabelValues(deploymentName, jobName).Desc())))
+			})
+
+			It("returns an instance_group_cpu_user_avg metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(instanceGroupCPUUserAvgMetric.WithLabelValues(deploymentName, jobName).Desc())))
+			})
+
+			It("returns an instance_group_cpu_wait_avg metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(instanceGroupCPUWaitAvgMetric.WithLabelValues(deploymentName, jobName).Desc())))
+			})
+
+			It("returns an instance_group_mem_percent_max metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(instanceGroupMemPercentMaxMetric.WithLabelValues(deploymentName, jobName).Desc())))
+			})
+
+			It("returns an instance_group_processes_failing_total metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(instanceGroupProcessesFailingMetric.WithLabelValues(deploymentName, jobName).Desc())))
+			})
+		})
+
+		Context("when instance info metrics are enabled", func() {
+			BeforeEach(func() {
+				instanceInfoMetrics = true
+			})
+
+			It("returns a job_instance_info metric description", func() {
+				Eventually(descriptions).Should(Receive(Equal(jobInstanceInfoMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobAgentID,
+					jobVMID,
+					jobState,
+				).Desc())))
+			})
+		})
+
+		It("returns a job_novm_info metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobNoVMInfoMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+			).Desc())))
+		})
+
+		It("returns a job_unhealthy_info metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobUnhealthyInfoMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				deployments.NoVMReason,
+			).Desc())))
+		})
+
+		It("returns a job_instances_expected metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobInstancesExpectedMetric.WithLabelValues(deploymentName, jobName).Desc())))
+		})
+
+		It("returns a job_instances_present metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobInstancesPresentMetric.WithLabelValues(deploymentName, jobName).Desc())))
+		})
+
 		It("returns a job_load_avg01 metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobLoadAvg01Metric.WithLabelValues(
 				deploymentName,
@@ -785,6 +1381,17 @@ var _ = Describe("JobsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a job_swap_active metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobSwapActiveMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
 		It("returns a job_swap_percent metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobSwapPercentMetric.WithLabelValues(
 				deploymentName,
@@ -862,6 +1469,50 @@ var _ = Describe("JobsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a job_start_time_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobStartTimeMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
+		It("returns a job_vm_created_at_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobVMCreatedAtMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
+		It("returns a job_processes_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobProcessesMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
+		It("returns a job_processes_failing_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobProcessesFailingMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			).Desc())))
+		})
+
 		It("returns a job_process_healthy metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobProcessHealthyMetric.WithLabelValues(
 				deploymentName,
@@ -886,6 +1537,18 @@ var _ = Describe("JobsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a job_process_start_time_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobProcessStartTimeMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Desc())))
+		})
+
 		It("returns a job_process_cpu_total metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobProcessCPUTotalMetric.WithLabelValues(
 				deploymentName,
@@ -898,6 +1561,30 @@ var _ = Describe("JobsCollector", func() {
 			).Desc())))
 		})
 
+		It("returns a job_process_cpu_user metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobProcessCPUUserMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Desc())))
+		})
+
+		It("returns a job_process_cpu_sys metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobProcessCPUSysMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Desc())))
+		})
+
 		It("returns a job_process_mem_kb metric description", func() {
 			Eventually(descriptions).Should(Receive(Equal(jobProcessMemKBMetric.WithLabelValues(
 				deploymentName,
@@ -949,8 +1636,12 @@ var _ = Describe("JobsCollector", func() {
 					Name:    jobProcessName,
 					Uptime:  &jobProcessUptime,
 					Healthy: jobProcessHealthy,
-					CPU:     deployments.CPU{Total: &jobProcessCPUTotal},
-					Mem:     deployments.MemInt{KB: &jobProcessMemKB, Percent: &jobProcessMemPercent},
+					CPU: deployments.CPU{
+						Total: &jobProcessCPUTotal,
+						User:  strconv.FormatFloat(jobProcessCPUUser, 'E', -1, 64),
+						Sys:   strconv.FormatFloat(jobProcessCPUSys, 'E', -1, 64),
+					},
+					Mem: deployments.MemInt{KB: &jobProcessMemKB, Percent: &jobProcessMemPercent},
 				},
 			}
 
@@ -985,18 +1676,24 @@ var _ = Describe("JobsCollector", func() {
 					InodePercent: strconv.Itoa(int(jobPersistentDiskInodePercent)),
 					Percent:      strconv.Itoa(int(jobPersistentDiskPercent)),
 				},
+				Uptime: &jobUptime,
 			}
 
 			instances = []deployments.Instance{
 				{
-					Name:      jobName,
-					ID:        jobID,
-					Index:     jobIndex,
-					IPs:       []string{jobIP},
-					AZ:        jobAZ,
-					Healthy:   jobHealthy,
-					Vitals:    vitals,
-					Processes: processes,
+					AgentID:     jobAgentID,
+					Name:        jobName,
+					ID:          jobID,
+					Index:       jobIndex,
+					IPs:         []string{jobIP},
+					AZ:          jobAZ,
+					VMType:      jobVMType,
+					VMID:        jobVMID,
+					State:       jobState,
+					Healthy:     jobHealthy,
+					Vitals:      vitals,
+					Processes:   processes,
+					VMCreatedAt: &jobVMCreatedAt,
 				},
 			}
 
@@ -1058,20 +1755,58 @@ var _ = Describe("JobsCollector", func() {
 			})
 		})
 
-		It("returns a job_load_avg01 metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
-				deploymentName,
-				jobName,
-				jobID,
-				jobIndex,
-				jobAZ,
-				jobIP,
-			))))
-			Consistently(errMetrics).ShouldNot(Receive())
+		Context("when there are several deployments", func() {
+			BeforeEach(func() {
+				for i := 1; i <= 10; i++ {
+					otherDeploymentInfo := deploymentInfo
+					otherDeploymentInfo.Name = fmt.Sprintf("%s-%d", deploymentName, i)
+					deploymentsInfo = append(deploymentsInfo, otherDeploymentInfo)
+
+					jobHealthyMetric.WithLabelValues(
+						otherDeploymentInfo.Name,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						jobIP,
+					).Set(float64(1))
+				}
+
+				metrics = make(chan prometheus.Metric, 1000)
+			})
+
+			It("returns a job_healthy metric for every deployment", func() {
+				matchers := []types.GomegaMatcher{}
+				for i := 0; i < len(deploymentsInfo); i++ {
+					matchers = append(matchers, ContainElement(PrometheusMetric(jobHealthyMetric.WithLabelValues(
+						deploymentsInfo[i].Name,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						jobIP,
+					))))
+				}
+
+				// The deployments are reported concurrently, so their
+				// metrics are not received in order.
+				received := []prometheus.Metric{}
+				Eventually(func() []prometheus.Metric {
+					for {
+						select {
+						case metric := <-metrics:
+							received = append(received, metric)
+						default:
+							return received
+						}
+					}
+				}).Should(SatisfyAll(matchers...))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
 		})
 
-		It("returns a job_load_avg05 metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg05Metric.WithLabelValues(
+		It("returns a job_resurrection_paused metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobResurrectionPausedMetric.WithLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
@@ -1082,11 +1817,101 @@ var _ = Describe("JobsCollector", func() {
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
-		It("returns a job_load_avg15 metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg15Metric.WithLabelValues(
-				deploymentName,
-				jobName,
-				jobID,
+		Context("when the resurrection of the instance is paused", func() {
+			BeforeEach(func() {
+				instances[0].ResurrectionPaused = true
+
+				jobResurrectionPausedMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(1))
+			})
+
+			It("returns a paused job_resurrection_paused metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobResurrectionPausedMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_ignore metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobIgnoreMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when the instance is ignored", func() {
+			BeforeEach(func() {
+				instances[0].Ignore = true
+
+				jobIgnoreMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(1))
+			})
+
+			It("returns an ignored job_ignore metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobIgnoreMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_load_avg01 metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a job_load_avg05 metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg05Metric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a job_load_avg15 metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg15Metric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
@@ -1094,6 +1919,54 @@ var _ = Describe("JobsCollector", func() {
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
+		Context("when the vitals VM type label is enabled", func() {
+			var (
+				jobLoadAvg01WithVMTypeMetric *prometheus.GaugeVec
+			)
+
+			BeforeEach(func() {
+				vitalsVMTypeLabel = true
+
+				jobLoadAvg01WithVMTypeMetric = prometheus.NewGaugeVec(
+					prometheus.GaugeOpts{
+						Namespace: namespace,
+						Subsystem: "job",
+						Name:      "load_avg01",
+						Help:      "BOSH Job Load avg01.",
+						ConstLabels: prometheus.Labels{
+							"environment": environment,
+							"bosh_name":   boshName,
+							"bosh_uuid":   boshUUID,
+						},
+					},
+					[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_ip", "bosh_job_vm_type"},
+				)
+
+				jobLoadAvg01WithVMTypeMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobVMType,
+				).Set(jobLoadAvg01)
+			})
+
+			It("returns a job_load_avg01 metric labeled with the VM type", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg01WithVMTypeMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobVMType,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		Context("when there is no load avg values", func() {
 			BeforeEach(func() {
 				instances[0].Vitals.Load = []string{}
@@ -1128,6 +2001,48 @@ var _ = Describe("JobsCollector", func() {
 			})
 		})
 
+		Context("when there is only a load avg01 value", func() {
+			BeforeEach(func() {
+				instances[0].Vitals.Load = []string{strconv.FormatFloat(jobLoadAvg01, 'E', -1, 64)}
+			})
+
+			It("returns a job_load_avg01 metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_load_avg05 metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobLoadAvg05Metric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_load_avg15 metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobLoadAvg15Metric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		It("returns a job_cpu_sys metric", func() {
 			Eventually(metrics).Should(Receive(PrometheusMetric(jobCPUSysMetric.WithLabelValues(
 				deploymentName,
@@ -1323,6 +2238,67 @@ var _ = Describe("JobsCollector", func() {
 			})
 		})
 
+		It("returns a job_swap_active metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobSwapActiveMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when swap is not in use", func() {
+			BeforeEach(func() {
+				instances[0].Vitals.Swap = deployments.Mem{
+					KB:      "0",
+					Percent: "0",
+				}
+				jobSwapActiveMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(0))
+			})
+
+			It("returns an inactive job_swap_active metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobSwapActiveMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when there is no swap kb value for the job_swap_active metric", func() {
+			BeforeEach(func() {
+				instances[0].Vitals.Swap = deployments.Mem{
+					Percent: strconv.Itoa(jobSwapPercent),
+				}
+			})
+
+			It("does not return a job_swap_active metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobSwapActiveMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
 		It("returns a job_swap_percent metric", func() {
 			Eventually(metrics).Should(Receive(PrometheusMetric(jobSwapPercentMetric.WithLabelValues(
 				deploymentName,
@@ -1547,114 +2523,485 @@ var _ = Describe("JobsCollector", func() {
 			})
 		})
 
-		It("returns a healthy job_process_healthy metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+		It("returns a job_start_time_seconds metric", func() {
+			Eventually(metrics).Should(Receive(startTimeMetric(jobStartTimeMetric.WithLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-				jobProcessName,
-			))))
+			).Desc(), jobUptime)))
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
-		Context("when a process is not running", func() {
+		Context("when there is no uptime value", func() {
 			BeforeEach(func() {
-				instances[0].Processes[0].Healthy = false
+				instances[0].Vitals.Uptime = nil
+			})
 
-				jobProcessHealthyMetric.WithLabelValues(
+			It("does not return a job_start_time_seconds metric", func() {
+				Consistently(metrics).ShouldNot(Receive(startTimeMetric(jobStartTimeMetric.WithLabelValues(
 					deploymentName,
 					jobName,
 					jobID,
 					jobIndex,
 					jobAZ,
 					jobIP,
-					jobProcessName,
-				).Set(float64(0))
+				).Desc(), jobUptime)))
+				Consistently(errMetrics).ShouldNot(Receive())
 			})
+		})
 
-			It("returns an unhealthy job_process_healthy metric", func() {
-				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
-					deploymentName,
-					jobName,
-					jobID,
-					jobIndex,
-					jobAZ,
-					jobIP,
-					jobProcessName,
-				))))
+		Context("when an instance only reports CPU vitals", func() {
+			BeforeEach(func() {
+				instances[0].Vitals = deployments.Vitals{CPU: vitals.CPU}
+				metrics = make(chan prometheus.Metric, 1000)
+			})
+
+			It("returns the CPU metrics and no other vitals metrics", func() {
+				received := []prometheus.Metric{}
+				drain := func() []prometheus.Metric {
+					for {
+						select {
+						case metric := <-metrics:
+							received = append(received, metric)
+						default:
+							return received
+						}
+					}
+				}
+
+				Eventually(drain).Should(SatisfyAll(
+					ContainElement(PrometheusMetric(jobCPUSysMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP))),
+					ContainElement(PrometheusMetric(jobCPUUserMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP))),
+					ContainElement(PrometheusMetric(jobCPUWaitMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP))),
+				))
+
+				for _, vitalsMetric := range []*prometheus.GaugeVec{
+					jobLoadAvg01Metric,
+					jobMemKBMetric,
+					jobMemPercentMetric,
+					jobSwapKBMetric,
+					jobSwapActiveMetric,
+					jobSwapPercentMetric,
+					jobSystemDiskInodePercentMetric,
+					jobSystemDiskPercentMetric,
+					jobEphemeralDiskInodePercentMetric,
+					jobEphemeralDiskPercentMetric,
+					jobPersistentDiskInodePercentMetric,
+					jobPersistentDiskPercentMetric,
+				} {
+					desc := vitalsMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobIP).Desc()
+					Consistently(drain).ShouldNot(ContainElement(WithTransform(prometheus.Metric.Desc, Equal(desc))))
+				}
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
 		})
 
-		It("returns a job_process_uptime_seconds metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessUptimeMetric.WithLabelValues(
+		It("returns a job_vm_created_at_seconds metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobVMCreatedAtMetric.WithLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-				jobProcessName,
 			))))
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
-		Context("when there is no process uptime value", func() {
+		Context("when the VM creation time is not reported", func() {
 			BeforeEach(func() {
-				instances[0].Processes[0].Uptime = nil
+				instances[0].VMCreatedAt = nil
 			})
 
-			It("does not return a job_process_uptime_seconds metric", func() {
-				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessUptimeMetric.WithLabelValues(
+			It("does not return a job_vm_created_at_seconds metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobVMCreatedAtMetric.WithLabelValues(
 					deploymentName,
 					jobName,
 					jobID,
 					jobIndex,
 					jobAZ,
 					jobIP,
-					jobProcessName,
 				))))
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
 		})
 
-		It("returns a job_process_cpu_total metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessCPUTotalMetric.WithLabelValues(
+		It("returns a job_processes_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesMetric.WithLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
 				jobIndex,
 				jobAZ,
 				jobIP,
-				jobProcessName,
 			))))
 			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
-		Context("when there is no process cpu total value", func() {
-			BeforeEach(func() {
-				instances[0].Processes[0].CPU = deployments.CPU{}
-			})
-
-			It("does not return a job_process_cpu_total metric", func() {
-				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessCPUTotalMetric.WithLabelValues(
-					deploymentName,
-					jobName,
-					jobID,
-					jobIndex,
-					jobAZ,
-					jobIP,
-					jobProcessName,
-				))))
-				Consistently(errMetrics).ShouldNot(Receive())
-			})
+		It("returns a job_processes_failing_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesFailingMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
 		})
 
-		It("returns a job_process_mem_kb metric", func() {
-			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessMemKBMetric.WithLabelValues(
+		It("returns a healthy job_process_healthy metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when a process is not running", func() {
+			BeforeEach(func() {
+				instances[0].Processes[0].Healthy = false
+
+				jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				).Set(float64(0))
+			})
+
+			It("returns an unhealthy job_process_healthy metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+		Context("when excluded processes are counted", func() {
+			BeforeEach(func() {
+				instances[0].ExcludedProcesses = 2
+				instances[0].ExcludedFailingProcesses = 1
+
+				jobProcessesMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(3))
+
+				jobProcessesFailingMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(1))
+			})
+
+			It("returns a job_processes_total metric including the excluded processes", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a job_processes_failing_total metric including the excluded failing processes", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesFailingMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when an unhealthy instance has a failing and a running process", func() {
+			var failingJobProcessName = "fake-failing-process-name"
+
+			BeforeEach(func() {
+				instances[0].Healthy = false
+				instances[0].Processes = append(instances[0].Processes, deployments.Process{
+					Name:    failingJobProcessName,
+					Healthy: false,
+				})
+
+				jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					failingJobProcessName,
+				).Set(float64(0))
+
+				jobProcessesMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(2))
+
+				jobProcessesFailingMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(1))
+			})
+
+			It("returns a job_processes_total metric counting both processes", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a job_processes_failing_total metric counting the failing process", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessesFailingMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a healthy job_process_healthy metric for the running process", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an unhealthy job_process_healthy metric for the failing process", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					failingJobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_process_uptime_seconds metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessUptimeMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when there is no process uptime value", func() {
+			BeforeEach(func() {
+				instances[0].Processes[0].Uptime = nil
+			})
+
+			It("does not return a job_process_uptime_seconds metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessUptimeMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_process_start_time_seconds metric", func() {
+			Eventually(metrics).Should(Receive(startTimeMetric(jobProcessStartTimeMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			).Desc(), jobProcessUptime)))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when there is no process uptime value for the start time", func() {
+			BeforeEach(func() {
+				instances[0].Processes[0].Uptime = nil
+			})
+
+			It("does not return a job_process_start_time_seconds metric", func() {
+				Consistently(metrics).ShouldNot(Receive(startTimeMetric(jobProcessStartTimeMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				).Desc(), jobProcessUptime)))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_process_cpu_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessCPUTotalMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when there is no process cpu total value", func() {
+			BeforeEach(func() {
+				instances[0].Processes[0].CPU = deployments.CPU{}
+			})
+
+			It("does not return a job_process_cpu_total metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessCPUTotalMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_process_cpu_user metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessCPUUserMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a job_process_cpu_sys metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessCPUSysMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobIP,
+				jobProcessName,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when there is only a process cpu total value", func() {
+			BeforeEach(func() {
+				instances[0].Processes[0].CPU = deployments.CPU{Total: &jobProcessCPUTotal}
+			})
+
+			It("returns a job_process_cpu_total metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessCPUTotalMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_process_cpu_user metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessCPUUserMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_process_cpu_sys metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessCPUSysMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("returns a job_process_mem_kb metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessMemKBMetric.WithLabelValues(
 				deploymentName,
 				jobName,
 				jobID,
@@ -1717,6 +3064,386 @@ var _ = Describe("JobsCollector", func() {
 			})
 		})
 
+		It("returns a job_instances_expected metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobInstancesExpectedMetric.WithLabelValues(deploymentName, jobName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a job_instances_present metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobInstancesPresentMetric.WithLabelValues(deploymentName, jobName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when an instance index is missing", func() {
+			BeforeEach(func() {
+				deploymentsInfo[0].Instances = append(instances, deployments.Instance{
+					Name:  jobName,
+					ID:    "fake-job-id-2",
+					Index: "2",
+					IPs:   []string{jobIP},
+					AZ:    jobAZ,
+				})
+
+				jobInstancesExpectedMetric.WithLabelValues(deploymentName, jobName).Set(float64(3))
+				jobInstancesPresentMetric.WithLabelValues(deploymentName, jobName).Set(float64(2))
+			})
+
+			It("returns a job_instances_expected metric from the highest index", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobInstancesExpectedMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a job_instances_present metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobInstancesPresentMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		It("does not return a job_instance_info metric", func() {
+			Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobInstanceInfoMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobAgentID,
+				jobVMID,
+				jobState,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("does not return an instance_group_cpu_sys_avg metric", func() {
+			Consistently(metrics).ShouldNot(Receive(PrometheusMetric(instanceGroupCPUSysAvgMetric.WithLabelValues(deploymentName, jobName))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when instance group metrics are enabled", func() {
+			BeforeEach(func() {
+				instanceGroupMetrics = true
+
+				otherVitals := vitals
+				otherVitals.CPU = deployments.CPU{Sys: "1.5", User: "2.0", Wait: "0.5"}
+				otherVitals.Mem = deployments.Mem{KB: strconv.Itoa(jobMemKB), Percent: "30"}
+				deploymentsInfo[0].Instances = append(instances, deployments.Instance{
+					Name:    jobName,
+					ID:      "fake-job-id-2",
+					Index:   "1",
+					IPs:     []string{"1.2.3.5"},
+					AZ:      jobAZ,
+					VMType:  jobVMType,
+					Healthy: false,
+					Vitals:  otherVitals,
+					Processes: []deployments.Process{
+						{Name: jobProcessName, Healthy: false},
+						{Name: "fake-other-process-name", Healthy: true},
+					},
+				})
+			})
+
+			It("returns an instance_group_cpu_sys_avg metric", func() {
+				instanceGroupCPUSysAvgMetric.WithLabelValues(deploymentName, jobName).Set(float64(1.0))
+				Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupCPUSysAvgMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an instance_group_cpu_user_avg metric", func() {
+				instanceGroupCPUUserAvgMetric.WithLabelValues(deploymentName, jobName).Set(float64(1.5))
+				Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupCPUUserAvgMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an instance_group_cpu_wait_avg metric", func() {
+				instanceGroupCPUWaitAvgMetric.WithLabelValues(deploymentName, jobName).Set(float64(1.0))
+				Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupCPUWaitAvgMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an instance_group_mem_percent_max metric", func() {
+				instanceGroupMemPercentMaxMetric.WithLabelValues(deploymentName, jobName).Set(float64(30))
+				Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupMemPercentMaxMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns an instance_group_processes_failing_total metric", func() {
+				instanceGroupProcessesFailingMetric.WithLabelValues(deploymentName, jobName).Set(float64(1))
+				Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupProcessesFailingMetric.WithLabelValues(deploymentName, jobName))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			Context("when an instance has no VM", func() {
+				BeforeEach(func() {
+					deploymentsInfo[0].Instances[1].NoVM = true
+				})
+
+				It("does not aggregate the instance", func() {
+					instanceGroupMemPercentMaxMetric.WithLabelValues(deploymentName, jobName).Set(float64(jobMemPercent))
+					Eventually(metrics).Should(Receive(PrometheusMetric(instanceGroupMemPercentMaxMetric.WithLabelValues(deploymentName, jobName))))
+					Consistently(errMetrics).ShouldNot(Receive())
+				})
+			})
+		})
+
+		Context("when instance info metrics are enabled", func() {
+			BeforeEach(func() {
+				instanceInfoMetrics = true
+			})
+
+			It("returns a job_instance_info metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobInstanceInfoMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobAgentID,
+					jobVMID,
+					jobState,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the instance has no VM", func() {
+			BeforeEach(func() {
+				instances[0].NoVM = true
+				instances[0].Healthy = false
+
+				jobHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				).Set(float64(0))
+
+				jobNoVMInfoMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+				).Set(float64(1))
+			})
+
+			It("returns an unhealthy job_healthy metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("returns a job_novm_info metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobNoVMInfoMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_unhealthy_info metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobUnhealthyInfoMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					deployments.NoVMReason,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			Context("and it is reported as unhealthy", func() {
+				BeforeEach(func() {
+					instances[0].UnhealthyReason = deployments.NoVMReason
+
+					jobUnhealthyInfoMetric.WithLabelValues(
+						deploymentName,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						deployments.NoVMReason,
+					).Set(float64(1))
+				})
+
+				It("returns a job_unhealthy_info metric with the no_vm reason", func() {
+					Eventually(metrics).Should(Receive(PrometheusMetric(jobUnhealthyInfoMetric.WithLabelValues(
+						deploymentName,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						deployments.NoVMReason,
+					))))
+					Consistently(errMetrics).ShouldNot(Receive())
+				})
+			})
+
+			It("does not return a job_load_avg01 metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_process_healthy metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when metrics are filtered", func() {
+			BeforeEach(func() {
+				metricsFilter, err = filters.NewMetricsFilter([]string{"job_cpu_*"}, []string{"job_cpu_wait"})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns a job_cpu_sys metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobCPUSysMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return an excluded job_cpu_wait metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobCPUWaitMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_mem_kb metric not included", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobMemKBMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_healthy metric not included", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when only unhealthy instances are reported", func() {
+			BeforeEach(func() {
+				onlyUnhealthy = true
+			})
+
+			It("returns a job_healthy metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(jobHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_load_avg01 metric for a healthy instance", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			It("does not return a job_process_healthy metric for a healthy instance", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobIP,
+					jobProcessName,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+
+			Context("and the instance is unhealthy", func() {
+				BeforeEach(func() {
+					instances[0].Healthy = false
+				})
+
+				It("returns a job_load_avg01 metric", func() {
+					Eventually(metrics).Should(Receive(PrometheusMetric(jobLoadAvg01Metric.WithLabelValues(
+						deploymentName,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						jobIP,
+					))))
+					Consistently(errMetrics).ShouldNot(Receive())
+				})
+
+				It("returns a job_process_healthy metric", func() {
+					Eventually(metrics).Should(Receive(PrometheusMetric(jobProcessHealthyMetric.WithLabelValues(
+						deploymentName,
+						jobName,
+						jobID,
+						jobIndex,
+						jobAZ,
+						jobIP,
+						jobProcessName,
+					))))
+					Consistently(errMetrics).ShouldNot(Receive())
+				})
+			})
+		})
+
 		Context("when there are no deployments", func() {
 			BeforeEach(func() {
 				deploymentsInfo = []deployments.DeploymentInfo{}
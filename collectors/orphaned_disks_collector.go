@@ -0,0 +1,181 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/disks"
+)
+
+type OrphanedDisksCollector struct {
+	orphanedDisksFetcher                         *disks.Fetcher
+	orphanedDisksMetric                          *prometheus.GaugeVec
+	orphanedDiskSizeMBMetric                     *prometheus.GaugeVec
+	orphanedDiskOrphanedAtMetric                 *prometheus.GaugeVec
+	lastOrphanedDisksScrapeErrorMetric           prometheus.Gauge
+	lastOrphanedDisksScrapeTimestampMetric       prometheus.Gauge
+	lastOrphanedDisksScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewOrphanedDisksCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	orphanedDisksFetcher *disks.Fetcher,
+) *OrphanedDisksCollector {
+	orphanedDisksMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "orphaned_disks_total",
+			Help:      "Number of BOSH Orphaned Disks.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name"},
+	)
+
+	orphanedDiskSizeMBMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "orphaned_disk_size_mb",
+			Help:      "Size of the BOSH Orphaned Disk in MB.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_disk_cid"},
+	)
+
+	orphanedDiskOrphanedAtMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "orphaned_disk_orphaned_at_timestamp",
+			Help:      "Number of seconds since 1970 since the BOSH Disk was orphaned.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_disk_cid"},
+	)
+
+	lastOrphanedDisksScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_orphaned_disks_scrape_error",
+			Help:      "Whether the last scrape of Orphaned Disk metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastOrphanedDisksScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_orphaned_disks_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Orphaned Disk metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastOrphanedDisksScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_orphaned_disks_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Orphaned Disk metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &OrphanedDisksCollector{
+		orphanedDisksFetcher:                         orphanedDisksFetcher,
+		orphanedDisksMetric:                          orphanedDisksMetric,
+		orphanedDiskSizeMBMetric:                     orphanedDiskSizeMBMetric,
+		orphanedDiskOrphanedAtMetric:                 orphanedDiskOrphanedAtMetric,
+		lastOrphanedDisksScrapeErrorMetric:           lastOrphanedDisksScrapeErrorMetric,
+		lastOrphanedDisksScrapeTimestampMetric:       lastOrphanedDisksScrapeTimestampMetric,
+		lastOrphanedDisksScrapeDurationSecondsMetric: lastOrphanedDisksScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *OrphanedDisksCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	c.orphanedDisksMetric.Reset()
+	c.orphanedDiskSizeMBMetric.Reset()
+	c.orphanedDiskOrphanedAtMetric.Reset()
+
+	orphanedDisksInfo, err := c.orphanedDisksFetcher.OrphanedDisks()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, orphanedDisk := range orphanedDisksInfo {
+		c.orphanedDisksMetric.WithLabelValues(
+			orphanedDisk.Deployment,
+			orphanedDisk.InstanceGroup,
+		).Inc()
+
+		c.orphanedDiskSizeMBMetric.WithLabelValues(
+			orphanedDisk.Deployment,
+			orphanedDisk.InstanceGroup,
+			orphanedDisk.CID,
+		).Set(float64(orphanedDisk.SizeMB))
+
+		c.orphanedDiskOrphanedAtMetric.WithLabelValues(
+			orphanedDisk.Deployment,
+			orphanedDisk.InstanceGroup,
+			orphanedDisk.CID,
+		).Set(float64(orphanedDisk.OrphanedAt.Unix()))
+	}
+	c.orphanedDisksMetric.Collect(ch)
+	c.orphanedDiskSizeMBMetric.Collect(ch)
+	c.orphanedDiskOrphanedAtMetric.Collect(ch)
+
+	c.lastOrphanedDisksScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastOrphanedDisksScrapeErrorMetric.Collect(ch)
+
+	c.lastOrphanedDisksScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastOrphanedDisksScrapeTimestampMetric.Collect(ch)
+
+	c.lastOrphanedDisksScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastOrphanedDisksScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *OrphanedDisksCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.orphanedDisksMetric.Describe(ch)
+	c.orphanedDiskSizeMBMetric.Describe(ch)
+	c.orphanedDiskOrphanedAtMetric.Describe(ch)
+	c.lastOrphanedDisksScrapeErrorMetric.Describe(ch)
+	c.lastOrphanedDisksScrapeTimestampMetric.Describe(ch)
+	c.lastOrphanedDisksScrapeDurationSecondsMetric.Describe(ch)
+}
@@ -0,0 +1,244 @@
+package collectors_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/disks"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("OrphanedDisksCollector", func() {
+	var (
+		namespace              string
+		environment            string
+		boshName               string
+		boshUUID               string
+		boshClient             *directorfakes.FakeDirector
+		orphanedDisksFetcher   *disks.Fetcher
+		orphanedDisksCollector *OrphanedDisksCollector
+
+		orphanedDisksMetric                          *prometheus.GaugeVec
+		orphanedDiskSizeMBMetric                     *prometheus.GaugeVec
+		orphanedDiskOrphanedAtMetric                 *prometheus.GaugeVec
+		lastOrphanedDisksScrapeErrorMetric           prometheus.Gauge
+		lastOrphanedDisksScrapeTimestampMetric       prometheus.Gauge
+		lastOrphanedDisksScrapeDurationSecondsMetric prometheus.Gauge
+
+		deploymentName = "fake-deployment-name"
+		jobName        = "fake-job-name"
+		diskCID        = "fake-disk-cid"
+		diskSizeMB     = uint64(1024)
+		orphanedAt     = time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		orphanedDisksMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "orphaned_disks_total",
+				Help:      "Number of BOSH Orphaned Disks.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name"},
+		)
+
+		orphanedDiskSizeMBMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "orphaned_disk_size_mb",
+				Help:      "Size of the BOSH Orphaned Disk in MB.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_disk_cid"},
+		)
+
+		orphanedDiskOrphanedAtMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "orphaned_disk_orphaned_at_timestamp",
+				Help:      "Number of seconds since 1970 since the BOSH Disk was orphaned.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_disk_cid"},
+		)
+
+		lastOrphanedDisksScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_orphaned_disks_scrape_error",
+				Help:      "Whether the last scrape of Orphaned Disk metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastOrphanedDisksScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_orphaned_disks_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Orphaned Disk metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastOrphanedDisksScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_orphaned_disks_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Orphaned Disk metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		orphanedDisksFetcher = disks.NewFetcher(boshClient)
+		orphanedDisksCollector = NewOrphanedDisksCollector(namespace, environment, boshName, boshUUID, orphanedDisksFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go orphanedDisksCollector.Describe(descriptions)
+		})
+
+		It("returns a orphaned_disks_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(orphanedDisksMetric.WithLabelValues(deploymentName, jobName).Desc())))
+		})
+
+		It("returns a orphaned_disk_size_mb metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(orphanedDiskSizeMBMetric.WithLabelValues(deploymentName, jobName, diskCID).Desc())))
+		})
+
+		It("returns a orphaned_disk_orphaned_at_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(orphanedDiskOrphanedAtMetric.WithLabelValues(deploymentName, jobName, diskCID).Desc())))
+		})
+
+		It("returns a last_orphaned_disks_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastOrphanedDisksScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_orphaned_disks_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastOrphanedDisksScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_orphaned_disks_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastOrphanedDisksScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.OrphanDisksReturns([]director.OrphanDisk{
+				&directorfakes.FakeOrphanDisk{
+					CIDStub:  func() string { return diskCID },
+					SizeStub: func() uint64 { return diskSizeMB },
+					DeploymentStub: func() director.Deployment {
+						return &directorfakes.FakeDeployment{NameStub: func() string { return deploymentName }}
+					},
+					InstanceNameStub: func() string { return jobName + "/fake-job-id" },
+					OrphanedAtStub:   func() time.Time { return orphanedAt },
+				},
+			}, nil)
+
+			orphanedDisksMetric.WithLabelValues(deploymentName, jobName).Set(1)
+			orphanedDiskSizeMBMetric.WithLabelValues(deploymentName, jobName, diskCID).Set(float64(diskSizeMB))
+			orphanedDiskOrphanedAtMetric.WithLabelValues(deploymentName, jobName, diskCID).Set(float64(orphanedAt.Unix()))
+			lastOrphanedDisksScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go orphanedDisksCollector.Collect(metrics)
+		})
+
+		It("returns a orphaned_disks_total metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(orphanedDisksMetric.WithLabelValues(deploymentName, jobName))))
+		})
+
+		It("returns a orphaned_disk_size_mb metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(orphanedDiskSizeMBMetric.WithLabelValues(deploymentName, jobName, diskCID))))
+		})
+
+		It("returns a orphaned_disk_orphaned_at_timestamp metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(orphanedDiskOrphanedAtMetric.WithLabelValues(deploymentName, jobName, diskCID))))
+		})
+
+		It("returns a last_orphaned_disks_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastOrphanedDisksScrapeErrorMetric)))
+		})
+
+		Context("when reading the orphaned disks fails", func() {
+			BeforeEach(func() {
+				boshClient.OrphanDisksReturns([]director.OrphanDisk{}, errors.New("no orphaned disks"))
+
+				lastOrphanedDisksScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a orphaned_disks_total metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(orphanedDisksMetric.WithLabelValues(deploymentName, jobName))))
+			})
+
+			It("returns a failed last_orphaned_disks_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastOrphanedDisksScrapeErrorMetric)))
+			})
+		})
+	})
+})
@@ -0,0 +1,127 @@
+package collectors
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type ReleasesCollector struct {
+	releasesFetcher                         *deployments.Fetcher
+	releaseInfoMetric                       *prometheus.GaugeVec
+	lastReleasesScrapeErrorMetric           prometheus.Gauge
+	lastReleasesScrapeTimestampMetric       prometheus.Gauge
+	lastReleasesScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewReleasesCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	releasesFetcher *deployments.Fetcher,
+) *ReleasesCollector {
+	releaseInfoMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "release_info",
+			Help:      "Labeled BOSH Release uploaded to the Director with a constant '1' value.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_release_name", "bosh_release_version", "in_use"},
+	)
+
+	lastReleasesScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_releases_scrape_error",
+			Help:      "Whether the last scrape of Release metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastReleasesScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_releases_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Release metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastReleasesScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_releases_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Release metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &ReleasesCollector{
+		releasesFetcher:                         releasesFetcher,
+		releaseInfoMetric:                       releaseInfoMetric,
+		lastReleasesScrapeErrorMetric:           lastReleasesScrapeErrorMetric,
+		lastReleasesScrapeTimestampMetric:       lastReleasesScrapeTimestampMetric,
+		lastReleasesScrapeDurationSecondsMetric: lastReleasesScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *ReleasesCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	c.releaseInfoMetric.Reset()
+
+	releases, err := c.releasesFetcher.Releases()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, release := range releases {
+		c.releaseInfoMetric.WithLabelValues(release.Name, release.Version, strconv.FormatBool(release.InUse)).Set(float64(1))
+	}
+	c.releaseInfoMetric.Collect(ch)
+
+	c.lastReleasesScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastReleasesScrapeErrorMetric.Collect(ch)
+
+	c.lastReleasesScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastReleasesScrapeTimestampMetric.Collect(ch)
+
+	c.lastReleasesScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastReleasesScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *ReleasesCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.releaseInfoMetric.Describe(ch)
+	c.lastReleasesScrapeErrorMetric.Describe(ch)
+	c.lastReleasesScrapeTimestampMetric.Describe(ch)
+	c.lastReleasesScrapeDurationSecondsMetric.Describe(ch)
+}
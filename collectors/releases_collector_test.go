@@ -0,0 +1,206 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/cppforlife/go-semi-semantic/version"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("ReleasesCollector", func() {
+	var (
+		namespace         string
+		environment       string
+		boshName          string
+		boshUUID          string
+		boshClient        *directorfakes.FakeDirector
+		releasesFetcher   *deployments.Fetcher
+		releasesCollector *ReleasesCollector
+
+		releaseInfoMetric                       *prometheus.GaugeVec
+		lastReleasesScrapeErrorMetric           prometheus.Gauge
+		lastReleasesScrapeTimestampMetric       prometheus.Gauge
+		lastReleasesScrapeDurationSecondsMetric prometheus.Gauge
+
+		releaseName = "fake-release-name"
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		releaseInfoMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "release_info",
+				Help:      "Labeled BOSH Release uploaded to the Director with a constant '1' value.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_release_name", "bosh_release_version", "in_use"},
+		)
+
+		lastReleasesScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_releases_scrape_error",
+				Help:      "Whether the last scrape of Release metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastReleasesScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_releases_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Release metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastReleasesScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_releases_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Release metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		deploymentsFilter, err := filters.NewDeploymentsFilter([]string{}, []string{}, boshClient)
+		Expect(err).ToNot(HaveOccurred())
+		instanceGroupsFilter, err := filters.NewInstanceGroupsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		releasesFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+			DeploymentsFilter:    *deploymentsFilter,
+			InstanceGroupsFilter: *instanceGroupsFilter,
+			AZsFilter:            *filters.NewAZsFilter([]string{}),
+			RetryAttempts:        1,
+		})
+		releasesCollector = NewReleasesCollector(namespace, environment, boshName, boshUUID, releasesFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go releasesCollector.Describe(descriptions)
+		})
+
+		It("returns a release_info metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(releaseInfoMetric.WithLabelValues(releaseName, "1.0.0", "true").Desc())))
+		})
+
+		It("returns a last_releases_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastReleasesScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_releases_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastReleasesScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_releases_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastReleasesScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.ReleasesReturns([]director.Release{
+				&directorfakes.FakeRelease{
+					NameStub:        func() string { return releaseName },
+					VersionStub:     func() version.Version { return version.MustNewVersionFromString("1.0.0") },
+					VersionMarkStub: func(mark string) string { return "" },
+				},
+				&directorfakes.FakeRelease{
+					NameStub:        func() string { return releaseName },
+					VersionStub:     func() version.Version { return version.MustNewVersionFromString("2.0.0") },
+					VersionMarkStub: func(mark string) string { return mark },
+				},
+			}, nil)
+
+			releaseInfoMetric.WithLabelValues(releaseName, "1.0.0", "false").Set(1)
+			releaseInfoMetric.WithLabelValues(releaseName, "2.0.0", "true").Set(1)
+			lastReleasesScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go releasesCollector.Collect(metrics)
+		})
+
+		It("returns a release_info metric for an unused release version", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(releaseInfoMetric.WithLabelValues(releaseName, "1.0.0", "false"))))
+		})
+
+		It("returns a release_info metric for a release version in use", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(releaseInfoMetric.WithLabelValues(releaseName, "2.0.0", "true"))))
+		})
+
+		It("returns a last_releases_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastReleasesScrapeErrorMetric)))
+		})
+
+		Context("when reading the releases fails", func() {
+			BeforeEach(func() {
+				boshClient.ReleasesReturns(nil, errors.New("no releases"))
+
+				lastReleasesScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a release_info metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(releaseInfoMetric.WithLabelValues(releaseName, "1.0.0", "false"))))
+			})
+
+			It("returns a failed last_releases_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastReleasesScrapeErrorMetric)))
+			})
+		})
+	})
+})
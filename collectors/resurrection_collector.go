@@ -0,0 +1,81 @@
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/resurrection"
+)
+
+type ResurrectionCollector struct {
+	resurrectionFetcher               *resurrection.Fetcher
+	directorResurrectionEnabledMetric prometheus.Gauge
+	lastResurrectionScrapeErrorMetric prometheus.Gauge
+}
+
+func NewResurrectionCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	resurrectionFetcher *resurrection.Fetcher,
+) *ResurrectionCollector {
+	directorResurrectionEnabledMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "director",
+			Name:      "resurrection_enabled",
+			Help:      "Whether BOSH resurrection is enabled director-wide (1 for enabled, 0 for disabled).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastResurrectionScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_resurrection_scrape_error",
+			Help:      "Whether the last scrape of Resurrection metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	return &ResurrectionCollector{
+		resurrectionFetcher:               resurrectionFetcher,
+		directorResurrectionEnabledMetric: directorResurrectionEnabledMetric,
+		lastResurrectionScrapeErrorMetric: lastResurrectionScrapeErrorMetric,
+	}
+}
+
+func (c *ResurrectionCollector) Collect(ch chan<- prometheus.Metric) {
+	scrapeError := 0
+
+	enabled, err := c.resurrectionFetcher.Enabled()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	} else {
+		var enabledMetric float64
+		if enabled {
+			enabledMetric = 1
+		}
+		c.directorResurrectionEnabledMetric.Set(enabledMetric)
+		c.directorResurrectionEnabledMetric.Collect(ch)
+	}
+
+	c.lastResurrectionScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastResurrectionScrapeErrorMetric.Collect(ch)
+}
+
+func (c *ResurrectionCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.directorResurrectionEnabledMetric.Describe(ch)
+	c.lastResurrectionScrapeErrorMetric.Describe(ch)
+}
@@ -0,0 +1,152 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/resurrection"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("ResurrectionCollector", func() {
+	var (
+		namespace             string
+		environment           string
+		boshName              string
+		boshUUID              string
+		boshClient            *directorfakes.FakeDirector
+		resurrectionFetcher   *resurrection.Fetcher
+		resurrectionCollector *ResurrectionCollector
+
+		directorResurrectionEnabledMetric prometheus.Gauge
+		lastResurrectionScrapeErrorMetric prometheus.Gauge
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		directorResurrectionEnabledMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "director",
+				Name:      "resurrection_enabled",
+				Help:      "Whether BOSH resurrection is enabled director-wide (1 for enabled, 0 for disabled).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastResurrectionScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_resurrection_scrape_error",
+				Help:      "Whether the last scrape of Resurrection metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		resurrectionFetcher = resurrection.NewFetcher(boshClient, 0)
+		resurrectionCollector = NewResurrectionCollector(namespace, environment, boshName, boshUUID, resurrectionFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go resurrectionCollector.Describe(descriptions)
+		})
+
+		It("returns a director_resurrection_enabled metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(directorResurrectionEnabledMetric.Desc())))
+		})
+
+		It("returns a last_resurrection_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastResurrectionScrapeErrorMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.ListConfigsReturns([]director.Config{}, nil)
+
+			directorResurrectionEnabledMetric.Set(1)
+			lastResurrectionScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go resurrectionCollector.Collect(metrics)
+		})
+
+		It("returns a director_resurrection_enabled metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(directorResurrectionEnabledMetric)))
+		})
+
+		It("returns a last_resurrection_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastResurrectionScrapeErrorMetric)))
+		})
+
+		Context("when resurrection is disabled", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsReturns([]director.Config{
+					{ID: "1", Name: "default", Type: "resurrection", Content: "rules:\n- enabled: false\n"},
+				}, nil)
+
+				directorResurrectionEnabledMetric.Set(0)
+			})
+
+			It("returns a disabled director_resurrection_enabled metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(directorResurrectionEnabledMetric)))
+			})
+		})
+
+		Context("when reading the resurrection configs fails", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsReturns([]director.Config{}, errors.New("no configs"))
+
+				lastResurrectionScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a director_resurrection_enabled metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(directorResurrectionEnabledMetric)))
+			})
+
+			It("returns a failed last_resurrection_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastResurrectionScrapeErrorMetric)))
+			})
+		})
+	})
+})
@@ -18,21 +18,55 @@ import (
 
 const (
 	boshDeploymentNameLabel = model.MetaLabelPrefix + "bosh_deployment"
+	boshJobNameLabel        = model.MetaLabelPrefix + "bosh_job_name"
+	boshJobAZLabel          = model.MetaLabelPrefix + "bosh_job_az"
+	boshJobIDLabel          = model.MetaLabelPrefix + "bosh_job_id"
+	boshJobIndexLabel       = model.MetaLabelPrefix + "bosh_job_index"
 	boshJobProcessNameLabel = model.MetaLabelPrefix + "bosh_job_process_name"
 )
 
+const (
+	ServiceDiscoveryGroupByProcess = "process"
+	ServiceDiscoveryGroupByJob     = "job"
+)
+
 type LabelGroups map[LabelGroupKey][]string
 
 type LabelGroupKey struct {
 	DeploymentName string
+	JobName        string
+	AZ             string
+	ID             string
+	Index          string
 	ProcessName    string
 }
 
 func (k *LabelGroupKey) Labels() model.LabelSet {
-	return model.LabelSet{
+	labels := model.LabelSet{
 		model.LabelName(boshDeploymentNameLabel): model.LabelValue(k.DeploymentName),
-		model.LabelName(boshJobProcessNameLabel): model.LabelValue(k.ProcessName),
 	}
+
+	if k.JobName != "" {
+		labels[model.LabelName(boshJobNameLabel)] = model.LabelValue(k.JobName)
+	}
+
+	if k.AZ != "" {
+		labels[model.LabelName(boshJobAZLabel)] = model.LabelValue(k.AZ)
+	}
+
+	if k.ID != "" {
+		labels[model.LabelName(boshJobIDLabel)] = model.LabelValue(k.ID)
+	}
+
+	if k.Index != "" {
+		labels[model.LabelName(boshJobIndexLabel)] = model.LabelValue(k.Index)
+	}
+
+	if k.ProcessName != "" {
+		labels[model.LabelName(boshJobProcessNameLabel)] = model.LabelValue(k.ProcessName)
+	}
+
+	return labels
 }
 
 type TargetGroups []TargetGroup
@@ -44,9 +78,12 @@ type TargetGroup struct {
 
 type ServiceDiscoveryCollector struct {
 	serviceDiscoveryFilename                        string
+	groupBy                                         string
+	labelsFilter                                    *filters.LabelsFilter
 	azsFilter                                       *filters.AZsFilter
 	processesFilter                                 *filters.RegexpFilter
 	cidrsFilter                                     *filters.CidrFilter
+	ipFamily                                        string
 	lastServiceDiscoveryScrapeTimestampMetric       prometheus.Gauge
 	lastServiceDiscoveryScrapeDurationSecondsMetric prometheus.Gauge
 	mu                                              *sync.Mutex
@@ -58,9 +95,12 @@ func NewServiceDiscoveryCollector(
 	boshName string,
 	boshUUID string,
 	serviceDiscoveryFilename string,
+	groupBy string,
+	labelsFilter *filters.LabelsFilter,
 	azsFilter *filters.AZsFilter,
 	processesFilter *filters.RegexpFilter,
 	cidrsFilter *filters.CidrFilter,
+	ipFamily string,
 ) *ServiceDiscoveryCollector {
 	lastServiceDiscoveryScrapeTimestampMetric := prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -92,9 +132,12 @@ func NewServiceDiscoveryCollector(
 
 	collector := &ServiceDiscoveryCollector{
 		serviceDiscoveryFilename: serviceDiscoveryFilename,
+		groupBy:                  groupBy,
+		labelsFilter:             labelsFilter,
 		azsFilter:                azsFilter,
 		processesFilter:          processesFilter,
 		cidrsFilter:              cidrsFilter,
+		ipFamily:                 ipFamily,
 		lastServiceDiscoveryScrapeTimestampMetric:       lastServiceDiscoveryScrapeTimestampMetric,
 		lastServiceDiscoveryScrapeDurationSecondsMetric: lastServiceDiscoveryScrapeDurationSecondsMetric,
 		mu: &sync.Mutex{},
@@ -127,12 +170,28 @@ func (c *ServiceDiscoveryCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *ServiceDiscoveryCollector) getLabelGroupKey(
 	deployment deployments.DeploymentInfo,
 	instance deployments.Instance,
-	process deployments.Process,
 ) LabelGroupKey {
-	return LabelGroupKey{
+	key := LabelGroupKey{
 		DeploymentName: deployment.Name,
-		ProcessName:    process.Name,
 	}
+
+	if c.groupBy == ServiceDiscoveryGroupByJob || c.labelsFilter.Enabled(filters.InstanceGroupLabel) {
+		key.JobName = instance.Name
+	}
+
+	if c.groupBy == ServiceDiscoveryGroupByJob || c.labelsFilter.Enabled(filters.AZLabel) {
+		key.AZ = instance.AZ
+	}
+
+	if c.labelsFilter.Enabled(filters.IDLabel) {
+		key.ID = instance.ID
+	}
+
+	if c.labelsFilter.Enabled(filters.IndexLabel) {
+		key.Index = instance.Index
+	}
+
+	return key
 }
 
 func (c *ServiceDiscoveryCollector) createLabelGroups(deployments []deployments.DeploymentInfo) LabelGroups {
@@ -140,8 +199,15 @@ func (c *ServiceDiscoveryCollector) createLabelGroups(deployments []deployments.
 
 	for _, deployment := range deployments {
 		for _, instance := range deployment.Instances {
-			ip, found := c.cidrsFilter.Select(instance.IPs)
-			if !found || !c.azsFilter.Enabled(instance.AZ) {
+			ips := c.cidrsFilter.SelectFamily(instance.IPs, c.ipFamily)
+			if len(ips) == 0 || !c.azsFilter.Enabled(instance.AZ) {
+				continue
+			}
+
+			instanceKey := c.getLabelGroupKey(deployment, instance)
+
+			if c.groupBy == ServiceDiscoveryGroupByJob {
+				labelGroups[instanceKey] = append(labelGroups[instanceKey], ips...)
 				continue
 			}
 
@@ -149,11 +215,9 @@ func (c *ServiceDiscoveryCollector) createLabelGroups(deployments []deployments.
 				if !c.processesFilter.Enabled(process.Name) {
 					continue
 				}
-				key := c.getLabelGroupKey(deployment, instance, process)
-				if _, ok := labelGroups[key]; !ok {
-					labelGroups[key] = []string{}
-				}
-				labelGroups[key] = append(labelGroups[key], ip)
+				key := instanceKey
+				key.ProcessName = process.Name
+				labelGroups[key] = append(labelGroups[key], ips...)
 			}
 		}
 	}
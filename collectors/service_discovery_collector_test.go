@@ -29,9 +29,12 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 		boshUUID                  string
 		tmpfile                   *os.File
 		serviceDiscoveryFilename  string
+		groupBy                   string
+		labelsFilter              *filters.LabelsFilter
 		azsFilter                 *filters.AZsFilter
 		processesFilter           *filters.RegexpFilter
 		cidrsFilter               *filters.CidrFilter
+		ipFamily                  string
 		serviceDiscoveryCollector *ServiceDiscoveryCollector
 
 		lastServiceDiscoveryScrapeTimestampMetric       prometheus.Gauge
@@ -46,9 +49,12 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 		tmpfile, err = os.CreateTemp("", "service_discovery_collector_test_")
 		Expect(err).ToNot(HaveOccurred())
 		serviceDiscoveryFilename = tmpfile.Name()
+		groupBy = ServiceDiscoveryGroupByProcess
+		labelsFilter, err = filters.NewLabelsFilter([]string{})
 		azsFilter = filters.NewAZsFilter([]string{})
 		cidrsFilter, err = filters.NewCidrFilter([]string{"0.0.0.0/0"})
 		processesFilter, err = filters.NewRegexpFilter([]string{})
+		ipFamily = filters.IPv4Family
 
 		lastServiceDiscoveryScrapeTimestampMetric = prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -91,9 +97,12 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 			boshName,
 			boshUUID,
 			serviceDiscoveryFilename,
+			groupBy,
+			labelsFilter,
 			azsFilter,
 			processesFilter,
 			cidrsFilter,
+			ipFamily,
 		)
 	})
 
@@ -126,6 +135,8 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 			job1Name            = "fake-job-1-name"
 			job2Name            = "fake-job-2-name"
 			job1AZ              = "fake-job-1-az"
+			job1ID              = "fake-job-1-id"
+			job1Index           = "0"
 			job2AZ              = "fake-job-2-az"
 			job1IP              = "1.2.3.4"
 			job2IP              = "5.6.7.8"
@@ -167,6 +178,8 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 			deployment1Instances = []deployments.Instance{
 				{
 					Name:      job1Name,
+					ID:        job1ID,
+					Index:     job1Index,
 					IPs:       []string{job1IP},
 					AZ:        job1AZ,
 					Processes: deployment1Processes,
@@ -302,6 +315,54 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 			})
 		})
 
+		Context("when an instance has multiple IPs", func() {
+			BeforeEach(func() {
+				cidrsFilter, err = filters.NewCidrFilter([]string{"0.0.0.0/0", "::/0"})
+				deployment1Info.Instances[0].IPs = []string{"fd00::1", job1IP}
+				deployment1Info.Instances[0].Processes = deployment1Processes[:1]
+				deploymentsInfo = []deployments.DeploymentInfo{deployment1Info}
+			})
+
+			It("writes a target groups file with the preferred IP family", func() {
+				Eventually(metrics).Should(Receive())
+				targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+					{"targets":["1.2.3.4"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_process_name":"fake-process-1-name"}}
+				]`))
+			})
+
+			Context("when preferring ipv6", func() {
+				BeforeEach(func() {
+					ipFamily = filters.IPv6Family
+				})
+
+				It("writes a target groups file with the IPv6 address", func() {
+					Eventually(metrics).Should(Receive())
+					targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+						{"targets":["fd00::1"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_process_name":"fake-process-1-name"}}
+					]`))
+				})
+			})
+
+			Context("when selecting all IP families", func() {
+				BeforeEach(func() {
+					ipFamily = filters.AllFamily
+				})
+
+				It("writes a target per IP", func() {
+					Eventually(metrics).Should(Receive())
+					targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+						{"targets":["1.2.3.4","fd00::1"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_process_name":"fake-process-1-name"}}
+					]`))
+				})
+			})
+		})
+
 		Context("when there are no processes", func() {
 			BeforeEach(func() {
 				deployment1Info.Instances[0].Processes = []deployments.Process{}
@@ -322,5 +383,54 @@ var _ = Describe("ServiceDiscoveryCollector", func() {
 				Consistently(errMetrics).ShouldNot(Receive())
 			})
 		})
+
+		Context("when instance labels are enabled", func() {
+			BeforeEach(func() {
+				labelsFilter, err = filters.NewLabelsFilter([]string{filters.AZLabel, filters.InstanceGroupLabel, filters.IndexLabel, filters.IDLabel})
+				deploymentsInfo = []deployments.DeploymentInfo{deployment1Info}
+			})
+
+			It("writes a target groups file with the instance labels", func() {
+				Eventually(metrics).Should(Receive())
+				targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+					{"targets":["1.2.3.4"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_name":"fake-job-1-name","__meta_bosh_job_az":"fake-job-1-az","__meta_bosh_job_index":"0","__meta_bosh_job_id":"fake-job-1-id","__meta_bosh_job_process_name":"fake-process-1-name"}},
+					{"targets":["1.2.3.4"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_name":"fake-job-1-name","__meta_bosh_job_az":"fake-job-1-az","__meta_bosh_job_index":"0","__meta_bosh_job_id":"fake-job-1-id","__meta_bosh_job_process_name":"fake-process-2-name"}}
+				]`))
+			})
+		})
+
+		Context("when grouping by job", func() {
+			BeforeEach(func() {
+				groupBy = ServiceDiscoveryGroupByJob
+			})
+
+			It("writes a target groups file grouped by job", func() {
+				Eventually(metrics).Should(Receive())
+				targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+					{"targets":["1.2.3.4"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_name":"fake-job-1-name","__meta_bosh_job_az":"fake-job-1-az"}},
+					{"targets":["5.6.7.8"],"labels":{"__meta_bosh_deployment":"fake-deployment-2-name","__meta_bosh_job_name":"fake-job-2-name","__meta_bosh_job_az":"fake-job-2-az"}}
+				]`))
+			})
+
+			Context("when there are no processes", func() {
+				BeforeEach(func() {
+					deployment1Info.Instances[0].Processes = []deployments.Process{}
+					deploymentsInfo = []deployments.DeploymentInfo{deployment1Info}
+				})
+
+				It("writes a target groups file with the job", func() {
+					Eventually(metrics).Should(Receive())
+					targetGroups, err := os.ReadFile(serviceDiscoveryFilename)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(targetGroups)).To(MatchUnorderedJSON(`[
+						{"targets":["1.2.3.4"],"labels":{"__meta_bosh_deployment":"fake-deployment-1-name","__meta_bosh_job_name":"fake-job-1-name","__meta_bosh_job_az":"fake-job-1-az"}}
+					]`))
+				})
+			})
+		})
 	})
 })
@@ -0,0 +1,126 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/tasks"
+)
+
+type TasksCollector struct {
+	tasksFetcher                         *tasks.Fetcher
+	tasksMetric                          *prometheus.GaugeVec
+	lastTasksScrapeErrorMetric           prometheus.Gauge
+	lastTasksScrapeTimestampMetric       prometheus.Gauge
+	lastTasksScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewTasksCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	tasksFetcher *tasks.Fetcher,
+) *TasksCollector {
+	tasksMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "tasks_total",
+			Help:      "Number of recent BOSH Tasks by state.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_task_state"},
+	)
+
+	lastTasksScrapeErrorMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_tasks_scrape_error",
+			Help:      "Whether the last scrape of Task metrics from BOSH resulted in an error (1 for error, 0 for success).",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastTasksScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_tasks_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of Task metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastTasksScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_tasks_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of Task metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &TasksCollector{
+		tasksFetcher:                         tasksFetcher,
+		tasksMetric:                          tasksMetric,
+		lastTasksScrapeErrorMetric:           lastTasksScrapeErrorMetric,
+		lastTasksScrapeTimestampMetric:       lastTasksScrapeTimestampMetric,
+		lastTasksScrapeDurationSecondsMetric: lastTasksScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *TasksCollector) Collect(ch chan<- prometheus.Metric) {
+	var begun = time.Now()
+
+	scrapeError := 0
+	c.tasksMetric.Reset()
+
+	tasksInfo, err := c.tasksFetcher.Tasks()
+	if err != nil {
+		log.Error(err)
+		scrapeError = 1
+	}
+
+	for _, task := range tasksInfo {
+		c.tasksMetric.WithLabelValues(task.Deployment, task.State).Inc()
+	}
+	c.tasksMetric.Collect(ch)
+
+	c.lastTasksScrapeErrorMetric.Set(float64(scrapeError))
+	c.lastTasksScrapeErrorMetric.Collect(ch)
+
+	c.lastTasksScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastTasksScrapeTimestampMetric.Collect(ch)
+
+	c.lastTasksScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastTasksScrapeDurationSecondsMetric.Collect(ch)
+}
+
+func (c *TasksCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.tasksMetric.Describe(ch)
+	c.lastTasksScrapeErrorMetric.Describe(ch)
+	c.lastTasksScrapeTimestampMetric.Describe(ch)
+	c.lastTasksScrapeDurationSecondsMetric.Describe(ch)
+}
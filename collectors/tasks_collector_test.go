@@ -0,0 +1,200 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/tasks"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("TasksCollector", func() {
+	var (
+		namespace      string
+		environment    string
+		boshName       string
+		boshUUID       string
+		boshClient     *directorfakes.FakeDirector
+		tasksFetcher   *tasks.Fetcher
+		tasksCollector *TasksCollector
+
+		tasksMetric                          *prometheus.GaugeVec
+		lastTasksScrapeErrorMetric           prometheus.Gauge
+		lastTasksScrapeTimestampMetric       prometheus.Gauge
+		lastTasksScrapeDurationSecondsMetric prometheus.Gauge
+
+		deploymentName = "fake-deployment-name"
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+
+		tasksMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "tasks_total",
+				Help:      "Number of recent BOSH Tasks by state.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_task_state"},
+		)
+
+		lastTasksScrapeErrorMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_tasks_scrape_error",
+				Help:      "Whether the last scrape of Task metrics from BOSH resulted in an error (1 for error, 0 for success).",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastTasksScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_tasks_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of Task metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastTasksScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_tasks_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of Task metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		tasksFetcher = tasks.NewFetcher(boshClient, 100, nil)
+		tasksCollector = NewTasksCollector(namespace, environment, boshName, boshUUID, tasksFetcher)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go tasksCollector.Describe(descriptions)
+		})
+
+		It("returns a tasks_total metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(tasksMetric.WithLabelValues(
+				deploymentName,
+				"done",
+			).Desc())))
+		})
+
+		It("returns a last_tasks_scrape_error metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastTasksScrapeErrorMetric.Desc())))
+		})
+
+		It("returns a last_tasks_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastTasksScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_tasks_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastTasksScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			metrics chan prometheus.Metric
+		)
+
+		BeforeEach(func() {
+			boshClient.RecentTasksReturns([]director.Task{
+				&directorfakes.FakeTask{
+					StateStub:          func() string { return "done" },
+					DeploymentNameStub: func() string { return deploymentName },
+				},
+				&directorfakes.FakeTask{
+					StateStub:          func() string { return "done" },
+					DeploymentNameStub: func() string { return deploymentName },
+				},
+				&directorfakes.FakeTask{
+					StateStub:          func() string { return "error" },
+					DeploymentNameStub: func() string { return "" },
+				},
+			}, nil)
+
+			tasksMetric.WithLabelValues(deploymentName, "done").Set(2)
+			tasksMetric.WithLabelValues("", "error").Set(1)
+			lastTasksScrapeErrorMetric.Set(0)
+
+			metrics = make(chan prometheus.Metric)
+		})
+
+		JustBeforeEach(func() {
+			go tasksCollector.Collect(metrics)
+		})
+
+		It("returns a tasks_total metric for each deployment and state", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(tasksMetric.WithLabelValues(deploymentName, "done"))))
+		})
+
+		It("returns a tasks_total metric for tasks without a deployment", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(tasksMetric.WithLabelValues("", "error"))))
+		})
+
+		It("returns a last_tasks_scrape_error metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(lastTasksScrapeErrorMetric)))
+		})
+
+		Context("when reading the recent tasks fails", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{}, errors.New("no tasks"))
+
+				lastTasksScrapeErrorMetric.Set(1)
+			})
+
+			It("does not return a tasks_total metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(tasksMetric.WithLabelValues(deploymentName, "done"))))
+			})
+
+			It("returns a failed last_tasks_scrape_error metric", func() {
+				Eventually(metrics).Should(Receive(PrometheusMetric(lastTasksScrapeErrorMetric)))
+			})
+		})
+	})
+})
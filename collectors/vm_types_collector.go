@@ -0,0 +1,181 @@
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
+	"github.com/bosh-prometheus/bosh_exporter/vmtypes"
+)
+
+type VMTypesCollector struct {
+	vmTypesFetcher                         *vmtypes.Fetcher
+	azsFilter                              *filters.AZsFilter
+	jobVMRequestedCPUMetric                *prometheus.GaugeVec
+	jobVMRequestedRAMMBMetric              *prometheus.GaugeVec
+	jobVMRequestedDiskMBMetric             *prometheus.GaugeVec
+	lastVMTypesScrapeTimestampMetric       prometheus.Gauge
+	lastVMTypesScrapeDurationSecondsMetric prometheus.Gauge
+}
+
+func NewVMTypesCollector(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	vmTypesFetcher *vmtypes.Fetcher,
+	azsFilter *filters.AZsFilter,
+) *VMTypesCollector {
+	jobVMRequestedCPUMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "vm_requested_cpu",
+			Help:      "Number of CPUs requested by the BOSH Job VM Type in the Cloud Config.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+	)
+
+	jobVMRequestedRAMMBMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "vm_requested_ram_mb",
+			Help:      "RAM in MB requested by the BOSH Job VM Type in the Cloud Config.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+	)
+
+	jobVMRequestedDiskMBMetric := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "job",
+			Name:      "vm_requested_disk_mb",
+			Help:      "Disk in MB requested by the BOSH Job VM Type in the Cloud Config.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+		[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+	)
+
+	lastVMTypesScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_vm_types_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape of VM Type metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	lastVMTypesScrapeDurationSecondsMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_vm_types_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of VM Type metrics from BOSH.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	collector := &VMTypesCollector{
+		vmTypesFetcher:                         vmTypesFetcher,
+		azsFilter:                              azsFilter,
+		jobVMRequestedCPUMetric:                jobVMRequestedCPUMetric,
+		jobVMRequestedRAMMBMetric:              jobVMRequestedRAMMBMetric,
+		jobVMRequestedDiskMBMetric:             jobVMRequestedDiskMBMetric,
+		lastVMTypesScrapeTimestampMetric:       lastVMTypesScrapeTimestampMetric,
+		lastVMTypesScrapeDurationSecondsMetric: lastVMTypesScrapeDurationSecondsMetric,
+	}
+	return collector
+}
+
+func (c *VMTypesCollector) Collect(deployments []deployments.DeploymentInfo, ch chan<- prometheus.Metric) error {
+	var begun = time.Now()
+
+	c.jobVMRequestedCPUMetric.Reset()
+	c.jobVMRequestedRAMMBMetric.Reset()
+	c.jobVMRequestedDiskMBMetric.Reset()
+
+	vmTypesInfo, err := c.vmTypesFetcher.VMTypes()
+	if err == nil {
+		c.reportJobVMRequestedMetrics(deployments, vmTypesInfo)
+	}
+
+	c.jobVMRequestedCPUMetric.Collect(ch)
+	c.jobVMRequestedRAMMBMetric.Collect(ch)
+	c.jobVMRequestedDiskMBMetric.Collect(ch)
+
+	c.lastVMTypesScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+	c.lastVMTypesScrapeTimestampMetric.Collect(ch)
+
+	c.lastVMTypesScrapeDurationSecondsMetric.Set(time.Since(begun).Seconds())
+	c.lastVMTypesScrapeDurationSecondsMetric.Collect(ch)
+
+	return err
+}
+
+func (c *VMTypesCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.jobVMRequestedCPUMetric.Describe(ch)
+	c.jobVMRequestedRAMMBMetric.Describe(ch)
+	c.jobVMRequestedDiskMBMetric.Describe(ch)
+	c.lastVMTypesScrapeTimestampMetric.Describe(ch)
+	c.lastVMTypesScrapeDurationSecondsMetric.Describe(ch)
+}
+
+// reportJobVMRequestedMetrics cross-references the VM Type of every instance
+// against the Cloud Config. Instances whose VM Type cannot be resolved (e.g.
+// using a resource pool or vm_resources) are omitted.
+func (c *VMTypesCollector) reportJobVMRequestedMetrics(deployments []deployments.DeploymentInfo, vmTypesInfo []vmtypes.VMTypeInfo) {
+	vmTypes := make(map[string]vmtypes.VMTypeInfo)
+	for _, vmType := range vmTypesInfo {
+		vmTypes[vmType.Name] = vmType
+	}
+
+	for _, deployment := range deployments {
+		for _, instance := range deployment.Instances {
+			if !c.azsFilter.Enabled(instance.AZ) {
+				continue
+			}
+
+			vmType, ok := vmTypes[instance.VMType]
+			if !ok {
+				continue
+			}
+
+			labels := []string{deployment.Name, instance.Name, instance.ID, instance.Index, instance.AZ, instance.VMType}
+			if vmType.CPU != nil {
+				c.jobVMRequestedCPUMetric.WithLabelValues(labels...).Set(*vmType.CPU)
+			}
+			if vmType.RAMMB != nil {
+				c.jobVMRequestedRAMMBMetric.WithLabelValues(labels...).Set(*vmType.RAMMB)
+			}
+			if vmType.DiskMB != nil {
+				c.jobVMRequestedDiskMBMetric.WithLabelValues(labels...).Set(*vmType.DiskMB)
+			}
+		}
+	}
+}
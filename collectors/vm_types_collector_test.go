@@ -0,0 +1,342 @@
+package collectors_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
+	"github.com/bosh-prometheus/bosh_exporter/vmtypes"
+
+	. "github.com/bosh-prometheus/bosh_exporter/collectors"
+	. "github.com/bosh-prometheus/bosh_exporter/utils/test_matchers"
+)
+
+var _ = Describe("VMTypesCollector", func() {
+	var (
+		namespace        string
+		environment      string
+		boshName         string
+		boshUUID         string
+		boshClient       *directorfakes.FakeDirector
+		azsFilter        *filters.AZsFilter
+		vmTypesCollector *VMTypesCollector
+
+		jobVMRequestedCPUMetric                *prometheus.GaugeVec
+		jobVMRequestedRAMMBMetric              *prometheus.GaugeVec
+		jobVMRequestedDiskMBMetric             *prometheus.GaugeVec
+		lastVMTypesScrapeTimestampMetric       prometheus.Gauge
+		lastVMTypesScrapeDurationSecondsMetric prometheus.Gauge
+
+		deploymentName = "fake-deployment-name"
+		jobName        = "fake-job-name"
+		jobID          = "fake-job-id"
+		jobIndex       = "0"
+		jobAZ          = "fake-job-az"
+		jobVMType      = "fake-job-vm-type"
+	)
+
+	BeforeEach(func() {
+		namespace = "test_exporter"
+		environment = "test_environment"
+		boshName = "test_bosh_name"
+		boshUUID = "test_bosh_uuid"
+		boshClient = &directorfakes.FakeDirector{}
+		boshClient.ListConfigsReturns([]director.Config{
+			{
+				ID:   "1",
+				Name: "default",
+				Type: "cloud",
+				Content: `
+vm_types:
+- name: fake-job-vm-type
+  cloud_properties:
+    cpu: 2
+    ram: 4096
+`,
+			},
+		}, nil)
+		azsFilter = filters.NewAZsFilter([]string{})
+
+		jobVMRequestedCPUMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "vm_requested_cpu",
+				Help:      "Number of CPUs requested by the BOSH Job VM Type in the Cloud Config.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+		)
+
+		jobVMRequestedRAMMBMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "vm_requested_ram_mb",
+				Help:      "RAM in MB requested by the BOSH Job VM Type in the Cloud Config.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+		)
+
+		jobVMRequestedDiskMBMetric = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "job",
+				Name:      "vm_requested_disk_mb",
+				Help:      "Disk in MB requested by the BOSH Job VM Type in the Cloud Config.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+			[]string{"bosh_deployment", "bosh_job_name", "bosh_job_id", "bosh_job_index", "bosh_job_az", "bosh_job_vm_type"},
+		)
+
+		lastVMTypesScrapeTimestampMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_vm_types_scrape_timestamp",
+				Help:      "Number of seconds since 1970 since last scrape of VM Type metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+
+		lastVMTypesScrapeDurationSecondsMetric = prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "",
+				Name:      "last_vm_types_scrape_duration_seconds",
+				Help:      "Duration of the last scrape of VM Type metrics from BOSH.",
+				ConstLabels: prometheus.Labels{
+					"environment": environment,
+					"bosh_name":   boshName,
+					"bosh_uuid":   boshUUID,
+				},
+			},
+		)
+	})
+
+	JustBeforeEach(func() {
+		vmTypesCollector = NewVMTypesCollector(namespace, environment, boshName, boshUUID, vmtypes.NewFetcher(boshClient), azsFilter)
+	})
+
+	Describe("Describe", func() {
+		var (
+			descriptions chan *prometheus.Desc
+		)
+
+		BeforeEach(func() {
+			descriptions = make(chan *prometheus.Desc)
+		})
+
+		JustBeforeEach(func() {
+			go vmTypesCollector.Describe(descriptions)
+		})
+
+		It("returns a job_vm_requested_cpu metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobVMRequestedCPUMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			).Desc())))
+		})
+
+		It("returns a job_vm_requested_ram_mb metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobVMRequestedRAMMBMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			).Desc())))
+		})
+
+		It("returns a job_vm_requested_disk_mb metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(jobVMRequestedDiskMBMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			).Desc())))
+		})
+
+		It("returns a last_vm_types_scrape_timestamp metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastVMTypesScrapeTimestampMetric.Desc())))
+		})
+
+		It("returns a last_vm_types_scrape_duration_seconds metric description", func() {
+			Eventually(descriptions).Should(Receive(Equal(lastVMTypesScrapeDurationSecondsMetric.Desc())))
+		})
+	})
+
+	Describe("Collect", func() {
+		var (
+			instances       []deployments.Instance
+			deploymentsInfo []deployments.DeploymentInfo
+
+			metrics    chan prometheus.Metric
+			errMetrics chan error
+		)
+
+		BeforeEach(func() {
+			instances = []deployments.Instance{
+				{
+					Name:   jobName,
+					ID:     jobID,
+					Index:  jobIndex,
+					AZ:     jobAZ,
+					VMType: jobVMType,
+				},
+			}
+
+			deploymentsInfo = []deployments.DeploymentInfo{
+				{
+					Name:      deploymentName,
+					Instances: instances,
+				},
+			}
+
+			jobVMRequestedCPUMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobVMType).Set(2)
+			jobVMRequestedRAMMBMetric.WithLabelValues(deploymentName, jobName, jobID, jobIndex, jobAZ, jobVMType).Set(4096)
+
+			metrics = make(chan prometheus.Metric)
+			errMetrics = make(chan error, 1)
+		})
+
+		JustBeforeEach(func() {
+			// The Collect goroutine can outlive the spec while it blocks on
+			// metrics, so it only uses the channels of its own spec.
+			collector, deploymentsInfo, metrics, errMetrics := vmTypesCollector, deploymentsInfo, metrics, errMetrics
+			go func() {
+				if err := collector.Collect(deploymentsInfo, metrics); err != nil {
+					errMetrics <- err
+				}
+			}()
+		})
+
+		It("returns a job_vm_requested_cpu metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobVMRequestedCPUMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("returns a job_vm_requested_ram_mb metric", func() {
+			Eventually(metrics).Should(Receive(PrometheusMetric(jobVMRequestedRAMMBMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		It("does not return a job_vm_requested_disk_mb metric when the vm type does not request disk", func() {
+			Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobVMRequestedDiskMBMetric.WithLabelValues(
+				deploymentName,
+				jobName,
+				jobID,
+				jobIndex,
+				jobAZ,
+				jobVMType,
+			))))
+			Consistently(errMetrics).ShouldNot(Receive())
+		})
+
+		Context("when the vm type cannot be resolved", func() {
+			BeforeEach(func() {
+				deploymentsInfo[0].Instances[0].VMType = "unknown-vm-type"
+			})
+
+			It("does not return a job_vm_requested_cpu metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobVMRequestedCPUMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					"unknown-vm-type",
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when the instance az is not enabled", func() {
+			BeforeEach(func() {
+				azsFilter = filters.NewAZsFilter([]string{"another-az"})
+			})
+
+			It("does not return a job_vm_requested_cpu metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobVMRequestedCPUMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobVMType,
+				))))
+				Consistently(errMetrics).ShouldNot(Receive())
+			})
+		})
+
+		Context("when reading the cloud configs fails", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsReturns([]director.Config{}, errors.New("no configs"))
+			})
+
+			It("does not return a job_vm_requested_cpu metric", func() {
+				Consistently(metrics).ShouldNot(Receive(PrometheusMetric(jobVMRequestedCPUMetric.WithLabelValues(
+					deploymentName,
+					jobName,
+					jobID,
+					jobIndex,
+					jobAZ,
+					jobVMType,
+				))))
+			})
+
+			It("returns an error", func() {
+				go func() {
+					for range metrics {
+					}
+				}()
+				Eventually(errMetrics).Should(Receive())
+			})
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package configs
+
+const (
+	CloudConfigType   = "cloud"
+	RuntimeConfigType = "runtime"
+)
+
+type ConfigInfo struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,45 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+)
+
+type Fetcher struct {
+	boshClient director.Director
+}
+
+func NewFetcher(boshClient director.Director) *Fetcher {
+	return &Fetcher{boshClient: boshClient}
+}
+
+// Configs returns the latest cloud and runtime configs of every name.
+func (f *Fetcher) Configs() ([]ConfigInfo, error) {
+	var configsInfo []ConfigInfo
+
+	for _, configType := range []string{CloudConfigType, RuntimeConfigType} {
+		log.Debugf("Reading latest %s Configs...", configType)
+		configs, err := f.boshClient.ListConfigs(1, director.ConfigsFilter{Type: configType})
+		if err != nil {
+			return []ConfigInfo{}, fmt.Errorf("Error while reading %s Configs: %v", configType, err)
+		}
+
+		for _, config := range configs {
+			configID, err := strconv.Atoi(config.ID)
+			if err != nil {
+				return []ConfigInfo{}, fmt.Errorf("Error while converting %s Config `%s` ID `%s`: %v", configType, config.Name, config.ID, err)
+			}
+
+			configsInfo = append(configsInfo, ConfigInfo{
+				ID:   configID,
+				Type: configType,
+				Name: config.Name,
+			})
+		}
+	}
+
+	return configsInfo, nil
+}
@@ -0,0 +1,98 @@
+package configs_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/configs"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		boshClient     *directorfakes.FakeDirector
+		configsFetcher *Fetcher
+	)
+
+	BeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+	})
+
+	JustBeforeEach(func() {
+		configsFetcher = NewFetcher(boshClient)
+	})
+
+	Describe("Configs", func() {
+		var (
+			configs []ConfigInfo
+			err     error
+		)
+
+		BeforeEach(func() {
+			boshClient.ListConfigsStub = func(limit int, filter director.ConfigsFilter) ([]director.Config, error) {
+				switch filter.Type {
+				case "cloud":
+					return []director.Config{{ID: "3", Name: "default", Type: "cloud"}}, nil
+				case "runtime":
+					return []director.Config{
+						{ID: "5", Name: "default", Type: "runtime"},
+						{ID: "7", Name: "dns", Type: "runtime"},
+					}, nil
+				}
+				return []director.Config{}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			configs, err = configsFetcher.Configs()
+		})
+
+		It("returns the latest cloud and runtime configs", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configs).To(Equal([]ConfigInfo{
+				{ID: 3, Type: "cloud", Name: "default"},
+				{ID: 5, Type: "runtime", Name: "default"},
+				{ID: 7, Type: "runtime", Name: "dns"},
+			}))
+		})
+
+		It("only reads the latest configs", func() {
+			Expect(boshClient.ListConfigsCallCount()).To(Equal(2))
+			limit, _ := boshClient.ListConfigsArgsForCall(0)
+			Expect(limit).To(Equal(1))
+		})
+
+		Context("when a config ID is not a number", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsStub = nil
+				boshClient.ListConfigsReturns([]director.Config{{ID: "fake-config-id", Name: "default"}}, nil)
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while converting cloud Config `default` ID"))
+			})
+		})
+
+		Context("when reading the configs fails", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsStub = nil
+				boshClient.ListConfigsReturns([]director.Config{}, errors.New("no configs"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading cloud Configs"))
+			})
+		})
+	})
+})
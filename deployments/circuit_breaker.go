@@ -0,0 +1,93 @@
+package deployments
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/log"
+)
+
+var ErrCircuitOpen = errors.New("BOSH Director circuit breaker is open")
+
+// CircuitBreaker is a DeploymentsSource that stops calling the wrapped source
+// for cooldown once threshold consecutive scrapes failed without returning any
+// deployment, so an unavailable BOSH Director is not hammered on every scrape.
+// Once the cooldown expires, the circuit is half-open: a single scrape goes
+// through as a trial while the concurrent ones are still skipped, and the
+// circuit opens back if the trial fails too.
+type CircuitBreaker struct {
+	source    DeploymentsSource
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+	up        bool
+}
+
+func NewCircuitBreaker(source DeploymentsSource, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		source:    source,
+		threshold: threshold,
+		cooldown:  cooldown,
+		up:        true,
+	}
+}
+
+func (b *CircuitBreaker) Deployments() ([]DeploymentInfo, error) {
+	b.mu.Lock()
+	if time.Now().Before(b.openUntil) || b.trial {
+		b.mu.Unlock()
+		return []DeploymentInfo{}, ErrCircuitOpen
+	}
+	b.trial = b.failures >= b.threshold
+	b.mu.Unlock()
+
+	deployments, err := b.source.Deployments()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	// Aborting a scrape over the maximum of instances is not a failure of
+	// the BOSH Director.
+	if err == nil || len(deployments) > 0 || isMaxInstances(err) {
+		b.failures = 0
+		b.up = true
+		return deployments, err
+	}
+
+	b.failures++
+	b.up = false
+	if b.failures >= b.threshold {
+		log.Warnf("Skipping BOSH Director scrapes for %s after %d consecutive failures", b.cooldown, b.failures)
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+
+	return deployments, err
+}
+
+// State returns whether the last scrape read deployments from the BOSH
+// Director, and whether the circuit is currently open.
+func (b *CircuitBreaker) State() (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	open := time.Now().Before(b.openUntil)
+
+	return b.up && !open, open
+}
+
+// Closed returns whether the BOSH Director may be called besides the
+// deployments scrape, that is neither while the circuit is open nor while it
+// is half-open and waiting for the result of its trial.
+func (b *CircuitBreaker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures < b.threshold
+}
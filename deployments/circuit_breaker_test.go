@@ -0,0 +1,202 @@
+package deployments_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type blockingDeploymentsSource struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingDeploymentsSource) Deployments() ([]DeploymentInfo, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return []DeploymentInfo{{Name: "fake-deployment-name"}}, nil
+}
+
+// halfOpenDeploymentsSource fails the given number of times before reading
+// the deployments of source.
+type halfOpenDeploymentsSource struct {
+	failures int
+	source   DeploymentsSource
+}
+
+func (s *halfOpenDeploymentsSource) Deployments() ([]DeploymentInfo, error) {
+	if s.failures > 0 {
+		s.failures--
+		return []DeploymentInfo{}, errors.New("fake-error")
+	}
+	return s.source.Deployments()
+}
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		source         *fakeDeploymentsSource
+		cooldown       time.Duration
+		circuitBreaker *CircuitBreaker
+	)
+
+	BeforeEach(func() {
+		source = &fakeDeploymentsSource{deployments: []DeploymentInfo{{Name: "fake-deployment-name"}}}
+		cooldown = time.Hour
+	})
+
+	JustBeforeEach(func() {
+		circuitBreaker = NewCircuitBreaker(source, 2, cooldown)
+	})
+
+	It("is up and closed before the first scrape", func() {
+		up, open := circuitBreaker.State()
+		Expect(up).To(BeTrue())
+		Expect(open).To(BeFalse())
+	})
+
+	It("is closed before the first scrape", func() {
+		Expect(circuitBreaker.Closed()).To(BeTrue())
+	})
+
+	It("returns the deployments of the source", func() {
+		deploymentsInfo, err := circuitBreaker.Deployments()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deploymentsInfo).To(Equal(source.deployments))
+		Expect(source.calls).To(Equal(1))
+	})
+
+	Context("when the source fails", func() {
+		BeforeEach(func() {
+			source.deployments = []DeploymentInfo{}
+			source.err = errors.New("fake-error")
+		})
+
+		It("is down but stays closed below the threshold", func() {
+			_, err := circuitBreaker.Deployments()
+			Expect(err).To(MatchError("fake-error"))
+
+			up, open := circuitBreaker.State()
+			Expect(up).To(BeFalse())
+			Expect(open).To(BeFalse())
+			Expect(circuitBreaker.Closed()).To(BeTrue())
+		})
+
+		It("opens after the threshold of consecutive failures", func() {
+			circuitBreaker.Deployments()
+			circuitBreaker.Deployments()
+
+			_, err := circuitBreaker.Deployments()
+			Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+			Expect(source.calls).To(Equal(2))
+
+			up, open := circuitBreaker.State()
+			Expect(up).To(BeFalse())
+			Expect(open).To(BeTrue())
+			Expect(circuitBreaker.Closed()).To(BeFalse())
+		})
+
+		It("resets the consecutive failures after a successful scrape", func() {
+			circuitBreaker.Deployments()
+
+			source.deployments = []DeploymentInfo{{Name: "fake-deployment-name"}}
+			source.err = nil
+			_, err := circuitBreaker.Deployments()
+			Expect(err).ToNot(HaveOccurred())
+
+			source.deployments = []DeploymentInfo{}
+			source.err = errors.New("fake-error")
+			circuitBreaker.Deployments()
+
+			up, open := circuitBreaker.State()
+			Expect(up).To(BeFalse())
+			Expect(open).To(BeFalse())
+		})
+
+		Context("and the cooldown expires", func() {
+			BeforeEach(func() {
+				cooldown = 10 * time.Millisecond
+			})
+
+			It("calls the source again and opens back if it still fails", func() {
+				circuitBreaker.Deployments()
+				circuitBreaker.Deployments()
+				time.Sleep(2 * cooldown)
+
+				_, err := circuitBreaker.Deployments()
+				Expect(err).To(MatchError("fake-error"))
+				Expect(source.calls).To(Equal(3))
+
+				_, open := circuitBreaker.State()
+				Expect(open).To(BeTrue())
+			})
+
+			It("is not closed until a trial succeeds", func() {
+				circuitBreaker.Deployments()
+				circuitBreaker.Deployments()
+				time.Sleep(2 * cooldown)
+
+				Expect(circuitBreaker.Closed()).To(BeFalse())
+			})
+
+			It("lets a single trial through at a time", func() {
+				trialSource := &blockingDeploymentsSource{
+					started: make(chan struct{}),
+					release: make(chan struct{}),
+				}
+				circuitBreaker = NewCircuitBreaker(&halfOpenDeploymentsSource{failures: 2, source: trialSource}, 2, cooldown)
+				circuitBreaker.Deployments()
+				circuitBreaker.Deployments()
+				time.Sleep(2 * cooldown)
+
+				trialDone := make(chan error)
+				go func() {
+					_, err := circuitBreaker.Deployments()
+					trialDone <- err
+				}()
+				Eventually(trialSource.started).Should(Receive())
+
+				_, err := circuitBreaker.Deployments()
+				Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+
+				close(trialSource.release)
+				Eventually(trialDone).Should(Receive(BeNil()))
+				Expect(circuitBreaker.Closed()).To(BeTrue())
+			})
+
+			It("closes once the source succeeds", func() {
+				circuitBreaker.Deployments()
+				circuitBreaker.Deployments()
+				time.Sleep(2 * cooldown)
+
+				source.deployments = []DeploymentInfo{{Name: "fake-deployment-name"}}
+				source.err = nil
+				_, err := circuitBreaker.Deployments()
+				Expect(err).ToNot(HaveOccurred())
+
+				up, open := circuitBreaker.State()
+				Expect(up).To(BeTrue())
+				Expect(open).To(BeFalse())
+			})
+		})
+	})
+
+	Context("when the scrape exceeds the maximum of instances", func() {
+		BeforeEach(func() {
+			source.deployments = []DeploymentInfo{}
+			source.err = &MaxInstancesError{Max: 1}
+		})
+
+		It("does not count it as a failure", func() {
+			circuitBreaker.Deployments()
+			circuitBreaker.Deployments()
+
+			up, open := circuitBreaker.State()
+			Expect(up).To(BeTrue())
+			Expect(open).To(BeFalse())
+		})
+	})
+})
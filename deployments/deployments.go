@@ -1,76 +1,205 @@
 package deployments
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrDeploymentNotFound = errors.New("deployment not found")
+
+const (
+	DirectorErrorTimeout   = "timeout"
+	DirectorErrorAuth      = "auth"
+	DirectorErrorRateLimit = "rate_limit"
+	DirectorErrorOther     = "other"
+)
+
+// NoVMReason is the UnhealthyReason of instances without a VM, set when they
+// are reported as unhealthy rather than skipped.
+const NoVMReason = "no_vm"
+
+// Errors returned by the Fetcher can be matched against these sentinels with
+// errors.Is to tell the causes of a failed director call apart.
+var (
+	ErrDirectorTimeout = errors.New("BOSH Director request timed out")
+	ErrUnauthorized    = errors.New("BOSH Director request was not authorized")
+	ErrRateLimited     = errors.New("BOSH Director request was rate limited")
+)
+
 type DeploymentInfo struct {
-	Name      string
-	Instances []Instance
-	Releases  []Release
-	Stemcells []Stemcell
+	Name             string            `json:"name"`
+	Instances        []Instance        `json:"instances"`
+	Errands          []Errand          `json:"errands"`
+	Releases         []Release         `json:"releases"`
+	Stemcells        []Stemcell        `json:"stemcells"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	Stale            *bool             `json:"stale,omitempty"`
+	FetchDuration    time.Duration     `json:"fetch_duration"`
+	MetadataCacheHit *bool             `json:"metadata_cache_hit,omitempty"`
+}
+
+type DeploymentError struct {
+	Deployment string
+	Err        error
+}
+
+func (e *DeploymentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeploymentError) Unwrap() error {
+	return e.Err
+}
+
+type InstancesTimeoutError struct {
+	Deployment string
+	Timeout    time.Duration
+}
+
+func (e *InstancesTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %s while reading Instances for deployment `%s`", e.Timeout, e.Deployment)
+}
+
+// MaxInstancesError is returned by the Fetcher when the deployments of a
+// single scrape hold more instances than the configured maximum.
+type MaxInstancesError struct {
+	Max int
+}
+
+func (e *MaxInstancesError) Error() string {
+	return fmt.Sprintf("Aborted reading deployments after exceeding the maximum of %d instances", e.Max)
+}
+
+// DirectorRequestError is returned by the Fetcher when a call to the BOSH
+// Director fails. errors.Is matches it against the sentinel error of its
+// category, if any.
+type DirectorRequestError struct {
+	Endpoint string
+	Category string
+	Err      error
+}
+
+func (e *DirectorRequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DirectorRequestError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DirectorRequestError) Is(target error) bool {
+	switch e.Category {
+	case DirectorErrorTimeout:
+		return target == ErrDirectorTimeout
+	case DirectorErrorAuth:
+		return target == ErrUnauthorized
+	case DirectorErrorRateLimit:
+		return target == ErrRateLimited
+	}
+
+	return false
 }
 
 type Instance struct {
-	AgentID            string
-	Name               string
-	ID                 string
-	Index              string
-	Bootstrap          bool
-	IPs                []string
-	AZ                 string
-	VMType             string
-	ResourcePool       string
-	ResurrectionPaused bool
-	Healthy            bool
-	Processes          []Process
-	Vitals             Vitals
+	AgentID            string    `json:"agent_id"`
+	Name               string    `json:"name"`
+	ID                 string    `json:"id"`
+	Index              string    `json:"index"`
+	Bootstrap          bool      `json:"bootstrap"`
+	IPs                []string  `json:"ips"`
+	DNS                []string  `json:"dns"`
+	AZ                 string    `json:"az"`
+	VMType             string    `json:"vm_type"`
+	ResourcePool       string    `json:"resource_pool"`
+	VMID               string    `json:"vm_cid"`
+	State              string    `json:"state"`
+	ResurrectionPaused bool      `json:"resurrection_paused"`
+	Ignore             bool      `json:"ignore"`
+	Healthy            bool      `json:"healthy"`
+	NoVM               bool      `json:"no_vm"`
+	UnhealthyReason    string    `json:"unhealthy_reason,omitempty"`
+	Processes          []Process `json:"processes"`
+	Vitals             Vitals    `json:"vitals"`
+	Stemcell           Stemcell  `json:"stemcell"`
+
+	// VMCreatedAt is nil when the BOSH Director does not report when the VM
+	// of the instance was created.
+	VMCreatedAt *time.Time `json:"vm_created_at,omitempty"`
+
+	// ExcludedProcesses and ExcludedFailingProcesses count the processes
+	// left out of Processes by the exclusion patterns, when requested.
+	ExcludedProcesses        int `json:"excluded_processes,omitempty"`
+	ExcludedFailingProcesses int `json:"excluded_failing_processes,omitempty"`
+}
+
+type Errand struct {
+	Name string `json:"name"`
 }
 
 type Process struct {
-	Name    string
-	Uptime  *uint64
-	Healthy bool
-	CPU     CPU
-	Mem     MemInt
+	Name    string  `json:"name"`
+	Uptime  *uint64 `json:"uptime"`
+	Healthy bool    `json:"healthy"`
+	CPU     CPU     `json:"cpu"`
+	Mem     MemInt  `json:"mem"`
 }
 
 type Vitals struct {
-	CPU            CPU
-	Mem            Mem
-	Swap           Mem
-	Uptime         *uint64
-	Load           []string
-	SystemDisk     Disk
-	EphemeralDisk  Disk
-	PersistentDisk Disk
+	CPU            CPU      `json:"cpu"`
+	Mem            Mem      `json:"mem"`
+	Swap           Mem      `json:"swap"`
+	Uptime         *uint64  `json:"uptime"`
+	Load           []string `json:"load"`
+	SystemDisk     Disk     `json:"system_disk"`
+	EphemeralDisk  Disk     `json:"ephemeral_disk"`
+	PersistentDisk Disk     `json:"persistent_disk"`
 }
 
 type CPU struct {
-	Total *float64
-	Sys   string
-	User  string
-	Wait  string
+	Total *float64 `json:"total"`
+	Sys   string   `json:"sys"`
+	User  string   `json:"user"`
+	Wait  string   `json:"wait"`
 }
 
 type Mem struct {
-	KB      string
-	Percent string
+	KB      string `json:"kb"`
+	Percent string `json:"percent"`
 }
 
 type MemInt struct {
-	KB      *uint64
-	Percent *float64
+	KB      *uint64  `json:"kb"`
+	Percent *float64 `json:"percent"`
 }
 
 type Disk struct {
-	InodePercent string
-	Percent      string
+	InodePercent string `json:"inode_percent"`
+	Percent      string `json:"percent"`
 }
 
 type Release struct {
-	Name    string
-	Version string
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// UploadedRelease is a release version uploaded to the BOSH Director. InUse
+// is true when any deployment references it.
+type UploadedRelease struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	InUse   bool   `json:"in_use"`
 }
 
 type Stemcell struct {
-	Name    string
-	Version string
-	OSName  string
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	OSName     string `json:"os_name"`
+	CPI        string `json:"cpi"`
+	APIVersion string `json:"api_version"`
+
+	// UpgradeAvailable is true when a newer stemcell version for the same
+	// operating system is uploaded to the BOSH Director, and nil when the
+	// stemcells of the BOSH Director could not be read.
+	UpgradeAvailable *bool `json:"upgrade_available,omitempty"`
 }
@@ -1,104 +1,421 @@
 package deployments
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloudfoundry/bosh-cli/director"
+	semver "github.com/cppforlife/go-semi-semantic/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v2"
 
 	"github.com/bosh-prometheus/bosh_exporter/filters"
 )
 
 type Fetcher struct {
-	deploymentsFilter filters.DeploymentsFilter
+	deploymentsFilter      filters.DeploymentsFilter
+	instanceGroupsFilter   filters.InstanceGroupsFilter
+	azsFilter              filters.AZsFilter
+	boshClient             director.Director
+	maxInFlight            int
+	continueOnError        bool
+	metadataCache          *metadataCache
+	catalogCache           *catalogCache
+	fetchTimeout           time.Duration
+	retrier                retrier
+	includeNoVMInstances   bool
+	noVMAsUnhealthy        bool
+	instancesThreshold     int
+	instancesTimeout       time.Duration
+	maxInstances           int
+	excludeProcessesFilter *filters.RegexpFilter
+	countExcludedProcesses bool
+	tagKeys                []string
+	requestDuration        prometheus.ObserverVec
+	requestErrors          *prometheus.CounterVec
+	deploymentsScraped     prometheus.Gauge
+	deploymentsFiltered    prometheus.Gauge
+	deploymentFetchErrors  *prometheus.CounterVec
+	instancesTimeouts      *prometheus.CounterVec
+
+	// ctx is the parent of every fetch, cancelled by Stop.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// FetcherOptions configures a Fetcher. The metrics left nil are not
+// reported.
+type FetcherOptions struct {
+	DeploymentsFilter      filters.DeploymentsFilter
+	InstanceGroupsFilter   filters.InstanceGroupsFilter
+	AZsFilter              filters.AZsFilter
+	MaxInFlight            int
+	ContinueOnError        bool
+	MetadataCacheTTL       time.Duration
+	FetchTimeout           time.Duration
+	RetryAttempts          int
+	RetryBackoff           time.Duration
+	IncludeNoVMInstances   bool
+	NoVMAsUnhealthy        bool
+	RequestsPerSecond      float64
+	InstancesThreshold     int
+	InstancesTimeout       time.Duration
+	MaxInstances           int
+	ExcludeProcessesFilter *filters.RegexpFilter
+	CountExcludedProcesses bool
+	TagKeys                []string
+	RequestDuration        prometheus.ObserverVec
+	RequestErrors          *prometheus.CounterVec
+	DeploymentsScraped     prometheus.Gauge
+	DeploymentsFiltered    prometheus.Gauge
+	DeploymentFetchErrors  *prometheus.CounterVec
+	InstancesTimeouts      *prometheus.CounterVec
 }
 
-func NewFetcher(deploymentsFilter filters.DeploymentsFilter) *Fetcher {
-	return &Fetcher{deploymentsFilter: deploymentsFilter}
+func NewFetcher(boshClient director.Director, options FetcherOptions) *Fetcher {
+	fetcher := &Fetcher{
+		deploymentsFilter:      options.DeploymentsFilter,
+		instanceGroupsFilter:   options.InstanceGroupsFilter,
+		azsFilter:              options.AZsFilter,
+		boshClient:             boshClient,
+		maxInFlight:            options.MaxInFlight,
+		continueOnError:        options.ContinueOnError,
+		fetchTimeout:           options.FetchTimeout,
+		retrier:                retrier{attempts: options.RetryAttempts, backoff: options.RetryBackoff},
+		includeNoVMInstances:   options.IncludeNoVMInstances,
+		noVMAsUnhealthy:        options.NoVMAsUnhealthy,
+		instancesThreshold:     options.InstancesThreshold,
+		instancesTimeout:       options.InstancesTimeout,
+		maxInstances:           options.MaxInstances,
+		excludeProcessesFilter: options.ExcludeProcessesFilter,
+		countExcludedProcesses: options.CountExcludedProcesses,
+		tagKeys:                options.TagKeys,
+		requestDuration:        options.RequestDuration,
+		requestErrors:          options.RequestErrors,
+		deploymentsScraped:     options.DeploymentsScraped,
+		deploymentsFiltered:    options.DeploymentsFiltered,
+		deploymentFetchErrors:  options.DeploymentFetchErrors,
+		instancesTimeouts:      options.InstancesTimeouts,
+	}
+
+	fetcher.ctx, fetcher.cancel = context.WithCancel(context.Background())
+
+	// All fetch goroutines share the limiter, so calls are spaced globally
+	// rather than per deployment.
+	if options.RequestsPerSecond > 0 {
+		fetcher.retrier.limiter = rate.NewLimiter(rate.Limit(options.RequestsPerSecond), 1)
+	}
+
+	if options.MetadataCacheTTL > 0 {
+		fetcher.metadataCache = newMetadataCache(options.MetadataCacheTTL)
+		fetcher.catalogCache = newCatalogCache(options.MetadataCacheTTL)
+	}
+
+	return fetcher
 }
 
 func (f *Fetcher) Deployments() ([]DeploymentInfo, error) {
+	ctx := f.ctx
+	if f.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.fetchTimeout)
+		defer cancel()
+	}
+
+	return f.DeploymentsContext(ctx)
+}
+
+func (f *Fetcher) DeploymentsContext(ctx context.Context) ([]DeploymentInfo, error) {
 	var deploymentsInfo = []DeploymentInfo{}
+	var deploymentsErrors = []error{}
+	var maxInstancesErr error
 	var mutex = &sync.Mutex{}
 	var wg = &sync.WaitGroup{}
 
-	deployments, err := f.deploymentsFilter.GetDeployments()
+	begun := time.Now()
+	deployments, filtered, err := f.deploymentsFilter.GetDeployments()
+	f.observeRequest("deployments", begun)
 	if err != nil {
+		return deploymentsInfo, f.directorRequestError("deployments", err)
+	}
+	f.observeDeployments(len(deployments), filtered)
+
+	// The catalog only adds the stale and upgrade available flags, which are
+	// left out when it cannot be read rather than failing the whole scrape.
+	catalog, err := f.cachedDirectorCatalog(ctx)
+	if err != nil {
+		log.Error(err)
+	}
+
+	if err := ctx.Err(); err != nil {
 		return deploymentsInfo, err
 	}
 
+	maxInFlight := f.maxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = len(deployments)
+	}
+	var semaphore = make(chan struct{}, maxInFlight)
+
+	// Exceeding the maximum number of instances cancels the fetches of the
+	// remaining deployments.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	counter := &instancesCounter{max: f.maxInstances}
+
 	for _, deployment := range deployments {
 		wg.Add(1)
 		go func(deployment director.Deployment) {
 			defer wg.Done()
-			deploymentInfo, err := f.fetchDeploymentInfo(deployment)
-			if err != nil {
-				log.Error(err)
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
 				return
 			}
+			defer func() { <-semaphore }()
+
+			deploymentInfo, err := f.fetchDeploymentInfo(ctx, deployment, catalog, counter)
 
 			mutex.Lock()
+			defer mutex.Unlock()
+			if isMaxInstances(err) && maxInstancesErr == nil {
+				maxInstancesErr = err
+				cancel()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				log.With("deployment", deployment.Name()).Error(err)
+				f.observeDeploymentError(deployment.Name(), err)
+				if f.continueOnError {
+					deploymentsErrors = append(deploymentsErrors, &DeploymentError{Deployment: deployment.Name(), Err: err})
+				}
+				return
+			}
+
+			log.With("deployment", deploymentInfo.Name).With("duration", deploymentInfo.FetchDuration.Seconds()).Debugf("Read deployment")
 			deploymentsInfo = append(deploymentsInfo, *deploymentInfo)
-			mutex.Unlock()
 		}(deployment)
 	}
-	wg.Wait()
 
-	return deploymentsInfo, nil
+	var done = make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if maxInstancesErr != nil {
+		log.Error(maxInstancesErr)
+		return []DeploymentInfo{}, maxInstancesErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		log.Errorf("Aborted reading deployments: %v", err)
+		deploymentsErrors = append(deploymentsErrors, err)
+	}
+
+	sort.Slice(deploymentsInfo, func(i, j int) bool {
+		return deploymentsInfo[i].Name < deploymentsInfo[j].Name
+	})
+
+	return deploymentsInfo, errors.Join(deploymentsErrors...)
+}
+
+// Stop cancels the fetches in progress, which return as soon as possible
+// with the deployments read so far, and makes the following ones fail. The
+// requests already sent to the BOSH Director are not interrupted.
+func (f *Fetcher) Stop() {
+	f.cancel()
+}
+
+// Deployment fetches a single deployment among the ones matched by the
+// deployments filter. The error wraps ErrDeploymentNotFound when there is no
+// such deployment.
+func (f *Fetcher) Deployment(name string) (*DeploymentInfo, error) {
+	begun := time.Now()
+	deployments, _, err := f.deploymentsFilter.GetDeployments()
+	f.observeRequest("deployments", begun)
+	if err != nil {
+		return nil, f.directorRequestError("deployments", err)
+	}
+
+	for _, deployment := range deployments {
+		if deployment.Name() != name {
+			continue
+		}
+
+		catalog, err := f.cachedDirectorCatalog(f.ctx)
+		if err != nil {
+			log.Error(err)
+		}
+
+		return f.fetchDeploymentInfo(f.ctx, deployment, catalog, &instancesCounter{max: f.maxInstances})
+	}
+
+	return nil, fmt.Errorf("Error while reading deployment `%s`: %w", name, ErrDeploymentNotFound)
+}
+
+// observeDeploymentError counts a deployment that failed to be fetched,
+// whether or not the failure is reported because of continue on error.
+func (f *Fetcher) observeDeploymentError(deployment string, err error) {
+	if f.deploymentFetchErrors != nil {
+		f.deploymentFetchErrors.WithLabelValues(deployment).Inc()
+	}
+
+	var instancesTimeoutError *InstancesTimeoutError
+	if f.instancesTimeouts != nil && errors.As(err, &instancesTimeoutError) {
+		f.instancesTimeouts.WithLabelValues(deployment).Inc()
+	}
 }
 
-func (f *Fetcher) fetchDeploymentInfo(deployment director.Deployment) (*DeploymentInfo, error) {
+// fetchDeploymentInfo reads the instances, errands, releases, stemcells and
+// tags of a deployment concurrently. It returns the first error in that order.
+func (f *Fetcher) fetchDeploymentInfo(ctx context.Context, deployment director.Deployment, catalog *directorCatalog, counter *instancesCounter) (*DeploymentInfo, error) {
+	var begun = time.Now()
+	var wg = &sync.WaitGroup{}
+
 	deploymentInfo := &DeploymentInfo{
 		Name: deployment.Name(),
 	}
 
-	instances, err := f.fetchDeploymentInstances(deployment)
-	if err != nil {
-		return deploymentInfo, err
+	var releases []Release
+	var stemcells []Stemcell
+	var cached bool
+
+	if f.metadataCache != nil {
+		releases, stemcells, cached = f.metadataCache.get(deploymentInfo.Name)
+		deploymentInfo.MetadataCacheHit = &cached
+	}
+
+	var instances []Instance
+	var errands []Errand
+	var tags map[string]string
+	var instancesErr, errandsErr, releasesErr, stemcellsErr, tagsErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		instances, instancesErr = f.fetchDeploymentInstances(ctx, deployment, counter)
+	}()
+	go func() {
+		defer wg.Done()
+		errands, errandsErr = f.fetchDeploymentErrands(ctx, deployment)
+	}()
+
+	if len(f.tagKeys) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tags, tagsErr = f.fetchDeploymentTags(ctx, deployment)
+		}()
+	}
+
+	if cached {
+		log.With("deployment", deploymentInfo.Name).Debugf("Using cached Releases and Stemcells")
+	} else {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			releases, releasesErr = f.fetchDeploymentReleases(ctx, deployment)
+		}()
+		go func() {
+			defer wg.Done()
+			stemcells, stemcellsErr = f.fetchDeploymentStemcells(ctx, deployment)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range []error{instancesErr, errandsErr, releasesErr, stemcellsErr, tagsErr} {
+		if err != nil {
+			return deploymentInfo, err
+		}
 	}
 	deploymentInfo.Instances = instances
+	deploymentInfo.Errands = errands
+	deploymentInfo.Tags = tags
 
-	releases, err := f.fetchDeploymentReleases(deployment)
-	if err != nil {
-		return deploymentInfo, err
+	if !cached && f.metadataCache != nil {
+		f.metadataCache.set(deploymentInfo.Name, releases, stemcells)
 	}
 	deploymentInfo.Releases = releases
+	deploymentInfo.Stemcells = catalog.stemcellsWithUpgradesAvailable(stemcellsWithAPIVersions(stemcells, instances))
+	deploymentInfo.Stale = catalog.stale(deploymentInfo.Releases, deploymentInfo.Stemcells)
 
-	stemcells, err := f.fetchDeploymentStemcells(deployment)
-	if err != nil {
-		return deploymentInfo, err
-	}
-	deploymentInfo.Stemcells = stemcells
+	deploymentInfo.FetchDuration = time.Since(begun)
 
 	return deploymentInfo, nil
 }
 
-func (f *Fetcher) fetchDeploymentInstances(deployment director.Deployment) ([]Instance, error) {
+func (f *Fetcher) fetchDeploymentInstances(ctx context.Context, deployment director.Deployment, counter *instancesCounter) ([]Instance, error) {
 	deploymentInstances := []Instance{}
 
-	log.Debugf("Reading Instances for deployment `%s`:", deployment.Name())
-	instances, err := deployment.InstanceInfos()
+	log.With("deployment", deployment.Name()).Debugf("Reading Instances...")
+	var instances []director.VMInfo
+	err := f.request(ctx, "instances", fmt.Sprintf("reading Instances for deployment `%s`", deployment.Name()), func() (err error) {
+		instances, err = f.instanceInfos(deployment)
+		return err
+	})
 	if err != nil {
-		return deploymentInstances, fmt.Errorf("Error while reading Instances for deployment `%s`: %v", deployment.Name(), err)
+		return deploymentInstances, fmt.Errorf("Error while reading Instances for deployment `%s`: %w", deployment.Name(), err)
 	}
 
+	// The director has no paged instances endpoint, so the whole list is
+	// returned by a single task; warn about deployments that may hit timeouts.
+	if f.instancesThreshold > 0 && len(instances) > f.instancesThreshold {
+		log.With("deployment", deployment.Name()).With("instances", len(instances)).Warnf("Instances list exceeds the threshold of %d instances", f.instancesThreshold)
+	}
+	deploymentInstances = make([]Instance, 0, len(instances))
+
 	for _, instance := range instances {
-		if instance.VMID == "" {
+		if instance.VMID == "" && !f.includeNoVMInstances && !f.noVMAsUnhealthy {
 			continue
 		}
 
+		if !f.instanceGroupsFilter.Enabled(instance.JobName) {
+			continue
+		}
+
+		if !f.azsFilter.Enabled(instance.AZ) {
+			continue
+		}
+
+		if !counter.add() {
+			return []Instance{}, &MaxInstancesError{Max: counter.max}
+		}
+
 		deploymentInstance := Instance{
 			AgentID:            instance.AgentID,
 			Name:               instance.JobName,
 			ID:                 instance.ID,
 			Bootstrap:          instance.Bootstrap,
 			IPs:                instance.IPs,
+			DNS:                instance.DNS,
 			AZ:                 instance.AZ,
 			VMType:             instance.VMType,
 			ResourcePool:       instance.ResourcePool,
+			VMID:               instance.VMID,
+			State:              instance.State,
 			ResurrectionPaused: instance.ResurrectionPaused,
+			Ignore:             instance.Ignore,
 			Healthy:            instance.IsRunning(),
 			Vitals: Vitals{
 				CPU: CPU{
@@ -135,14 +452,39 @@ func (f *Fetcher) fetchDeploymentInstances(deployment director.Deployment) ([]In
 			deploymentInstance.Index = strconv.Itoa(int(*instance.Index))
 		}
 
+		if !instance.VMCreatedAt.IsZero() {
+			vmCreatedAt := instance.VMCreatedAt
+			deploymentInstance.VMCreatedAt = &vmCreatedAt
+		}
+
+		deploymentInstance.Stemcell = Stemcell{
+			Name:    instance.Stemcell.Name,
+			Version: instance.Stemcell.Version,
+		}
+		if instance.Stemcell.ApiVersion != 0 {
+			deploymentInstance.Stemcell.APIVersion = strconv.Itoa(instance.Stemcell.ApiVersion)
+		}
+
 		deploymentProcesses := []Process{}
 		for _, process := range instance.Processes {
+			if f.excludeProcessesFilter != nil && f.excludeProcessesFilter.Matches(process.Name) {
+				if f.countExcludedProcesses {
+					deploymentInstance.ExcludedProcesses++
+					if !process.IsRunning() {
+						deploymentInstance.ExcludedFailingProcesses++
+					}
+				}
+				continue
+			}
+
 			deploymentProcess := Process{
 				Name:    process.Name,
 				Uptime:  process.Uptime.Seconds,
 				Healthy: process.IsRunning(),
 				CPU: CPU{
 					Total: process.CPU.Total,
+					Sys:   process.CPU.Sys,
+					User:  process.CPU.User,
 				},
 				Mem: MemInt{
 					KB:      process.Mem.KB,
@@ -153,19 +495,358 @@ func (f *Fetcher) fetchDeploymentInstances(deployment director.Deployment) ([]In
 		}
 		deploymentInstance.Processes = deploymentProcesses
 
+		if instance.VMID == "" {
+			deploymentInstance.NoVM = true
+			deploymentInstance.Healthy = false
+			if f.noVMAsUnhealthy {
+				deploymentInstance.UnhealthyReason = NoVMReason
+			}
+		}
+
 		deploymentInstances = append(deploymentInstances, deploymentInstance)
 	}
 
+	sort.SliceStable(deploymentInstances, func(i, j int) bool {
+		if deploymentInstances[i].Name != deploymentInstances[j].Name {
+			return deploymentInstances[i].Name < deploymentInstances[j].Name
+		}
+		return lessIndex(deploymentInstances[i].Index, deploymentInstances[j].Index)
+	})
+
 	return deploymentInstances, nil
 }
 
-func (f *Fetcher) fetchDeploymentReleases(deployment director.Deployment) ([]Release, error) {
+// instancesCounter counts the instances of all the deployments fetched by a
+// single scrape. A max of 0 disables the limit.
+type instancesCounter struct {
+	max   int
+	count int64
+}
+
+func (c *instancesCounter) add() bool {
+	if c.max <= 0 {
+		return true
+	}
+
+	return atomic.AddInt64(&c.count, 1) <= int64(c.max)
+}
+
+func isMaxInstances(err error) bool {
+	var maxInstancesError *MaxInstancesError
+	return errors.As(err, &maxInstancesError)
+}
+
+func lessIndex(a string, b string) bool {
+	aIndex, aErr := strconv.Atoi(a)
+	bIndex, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return a < b
+	}
+
+	return aIndex < bIndex
+}
+
+// request retries a director call, turning its errors into a
+// DirectorRequestError counted by endpoint and category.
+func (f *Fetcher) request(ctx context.Context, endpoint string, description string, fn func() error) error {
+	return f.retrier.do(ctx, description, func() error {
+		if err := fn(); err != nil {
+			return f.directorRequestError(endpoint, err)
+		}
+		return nil
+	})
+}
+
+func (f *Fetcher) directorRequestError(endpoint string, err error) error {
+	requestErr := &DirectorRequestError{Endpoint: endpoint, Category: directorErrorCategory(err), Err: err}
+	if f.requestErrors != nil {
+		f.requestErrors.WithLabelValues(endpoint, requestErr.Category).Inc()
+	}
+
+	return requestErr
+}
+
+// observeDeployments records how many deployments the last scrape read, and
+// how many the deployments filter left out.
+func (f *Fetcher) observeDeployments(scraped int, filtered int) {
+	if f.deploymentsScraped != nil {
+		f.deploymentsScraped.Set(float64(scraped))
+	}
+	if f.deploymentsFiltered != nil {
+		f.deploymentsFiltered.Set(float64(filtered))
+	}
+}
+
+// observeRequest records the duration of a single director call, including
+// failed attempts that are retried.
+func (f *Fetcher) observeRequest(endpoint string, begun time.Time) {
+	if f.requestDuration == nil {
+		return
+	}
+
+	f.requestDuration.WithLabelValues(endpoint).Observe(time.Since(begun).Seconds())
+}
+
+// instanceInfos bounds the InstanceInfos call by the instances timeout. The
+// director client takes no context, so a timed out call is left running in
+// the background and its result discarded.
+func (f *Fetcher) instanceInfos(deployment director.Deployment) ([]director.VMInfo, error) {
+	if f.instancesTimeout <= 0 {
+		defer f.observeRequest("instances", time.Now())
+		return deployment.InstanceInfos()
+	}
+
+	type result struct {
+		instances []director.VMInfo
+		err       error
+	}
+
+	resultChannel := make(chan result, 1)
+	go func() {
+		begun := time.Now()
+		instances, err := deployment.InstanceInfos()
+		f.observeRequest("instances", begun)
+		resultChannel <- result{instances: instances, err: err}
+	}()
+
+	select {
+	case r := <-resultChannel:
+		return r.instances, r.err
+	case <-time.After(f.instancesTimeout):
+		return nil, &InstancesTimeoutError{Deployment: deployment.Name(), Timeout: f.instancesTimeout}
+	}
+}
+
+func (f *Fetcher) fetchDeploymentErrands(ctx context.Context, deployment director.Deployment) ([]Errand, error) {
+	deploymentErrands := []Errand{}
+
+	log.With("deployment", deployment.Name()).Debugf("Reading Errands...")
+	var errands []director.Errand
+	err := f.request(ctx, "errands", fmt.Sprintf("reading Errands for deployment `%s`", deployment.Name()), func() (err error) {
+		defer f.observeRequest("errands", time.Now())
+		errands, err = deployment.Errands()
+		return err
+	})
+	if err != nil {
+		return deploymentErrands, fmt.Errorf("Error while reading Errands for deployment `%s`: %w", deployment.Name(), err)
+	}
+
+	for _, errand := range errands {
+		deploymentErrands = append(deploymentErrands, Errand{Name: errand.Name})
+	}
+
+	return deploymentErrands, nil
+}
+
+// fetchDeploymentTags reads the top-level tags of the deployment manifest,
+// keeping only the configured tag keys.
+func (f *Fetcher) fetchDeploymentTags(ctx context.Context, deployment director.Deployment) (map[string]string, error) {
+	log.With("deployment", deployment.Name()).Debugf("Reading Manifest tags...")
+	var manifest string
+	err := f.request(ctx, "manifest", fmt.Sprintf("reading Manifest for deployment `%s`", deployment.Name()), func() (err error) {
+		defer f.observeRequest("manifest", time.Now())
+		manifest, err = deployment.Manifest()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading Manifest for deployment `%s`: %w", deployment.Name(), err)
+	}
+
+	var parsedManifest struct {
+		Tags map[string]interface{} `yaml:"tags"`
+	}
+	if err := yaml.Unmarshal([]byte(manifest), &parsedManifest); err != nil {
+		return nil, fmt.Errorf("Error while parsing Manifest for deployment `%s`: %v", deployment.Name(), err)
+	}
+
+	var deploymentTags map[string]string
+	for _, key := range f.tagKeys {
+		value, ok := parsedManifest.Tags[key]
+		if !ok || value == nil {
+			continue
+		}
+		if deploymentTags == nil {
+			deploymentTags = make(map[string]string)
+		}
+		deploymentTags[key] = fmt.Sprint(value)
+	}
+
+	return deploymentTags, nil
+}
+
+// Releases returns the releases uploaded to the director, and whether any
+// deployment references them.
+func (f *Fetcher) Releases() ([]UploadedRelease, error) {
+	catalog, err := f.cachedDirectorCatalog(f.ctx)
+	if err != nil {
+		return []UploadedRelease{}, err
+	}
+
+	return catalog.releases, nil
+}
+
+// directorCatalog holds the releases and stemcells uploaded to the director,
+// shared by all deployments. A nil catalog, when it could not be read, flags
+// nothing.
+type directorCatalog struct {
+	releases        []UploadedRelease
+	latestReleases  map[string]semver.Version
+	latestStemcells map[string]semver.Version
+
+	// latestStemcellsByOS holds the newest stemcell version uploaded for
+	// each operating system, regardless of the IaaS the stemcell targets.
+	latestStemcellsByOS map[string]semver.Version
+}
+
+// stale reports whether any of the releases or stemcells is older than the
+// newest version uploaded to the director.
+func (c *directorCatalog) stale(releases []Release, stemcells []Stemcell) *bool {
+	if c == nil {
+		return nil
+	}
+
+	stale := false
+	for _, release := range releases {
+		if isOutdated(c.latestReleases, release.Name, release.Version) {
+			stale = true
+		}
+	}
+
+	for _, stemcell := range stemcells {
+		if isOutdated(c.latestStemcells, stemcell.Name, stemcell.Version) {
+			stale = true
+		}
+	}
+
+	return &stale
+}
+
+// stemcellsWithUpgradesAvailable flags the stemcells for which a newer version
+// of the same operating system is uploaded to the director.
+func (c *directorCatalog) stemcellsWithUpgradesAvailable(stemcells []Stemcell) []Stemcell {
+	if c == nil {
+		return stemcells
+	}
+
+	deploymentStemcells := []Stemcell{}
+
+	for _, stemcell := range stemcells {
+		upgradeAvailable := isOutdated(c.latestStemcellsByOS, stemcell.OSName, stemcell.Version)
+		stemcell.UpgradeAvailable = &upgradeAvailable
+		deploymentStemcells = append(deploymentStemcells, stemcell)
+	}
+
+	return deploymentStemcells
+}
+
+func isOutdated(latestVersions map[string]semver.Version, name string, versionString string) bool {
+	latestVersion, ok := latestVersions[name]
+	if !ok {
+		return false
+	}
+
+	currentVersion, err := semver.NewVersionFromString(versionString)
+	if err != nil {
+		return false
+	}
+
+	return latestVersion.IsGt(currentVersion)
+}
+
+// cachedDirectorCatalog returns the catalog cached within the metadata cache
+// TTL, or reads it again. Failures are not cached.
+func (f *Fetcher) cachedDirectorCatalog(ctx context.Context) (*directorCatalog, error) {
+	if f.catalogCache != nil {
+		if catalog, ok := f.catalogCache.get(); ok {
+			log.Debugf("Using cached director Releases and Stemcells")
+			return catalog, nil
+		}
+	}
+
+	catalog, err := f.fetchDirectorCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.catalogCache != nil {
+		f.catalogCache.set(catalog)
+	}
+
+	return catalog, nil
+}
+
+func (f *Fetcher) fetchDirectorCatalog(ctx context.Context) (*directorCatalog, error) {
+	catalog := &directorCatalog{
+		releases:            []UploadedRelease{},
+		latestReleases:      make(map[string]semver.Version),
+		latestStemcells:     make(map[string]semver.Version),
+		latestStemcellsByOS: make(map[string]semver.Version),
+	}
+
+	log.Debugf("Reading Releases...")
+	var releases []director.Release
+	err := f.request(ctx, "releases", "reading Releases", func() (err error) {
+		defer f.observeRequest("releases", time.Now())
+		releases, err = f.boshClient.Releases()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading Releases: %w", err)
+	}
+
+	for _, release := range releases {
+		// The director marks with `*` the release versions used by any
+		// deployment.
+		catalog.releases = append(catalog.releases, UploadedRelease{
+			Name:    release.Name(),
+			Version: release.Version().AsString(),
+			InUse:   release.VersionMark("*") != "",
+		})
+
+		if latestVersion, ok := catalog.latestReleases[release.Name()]; !ok || release.Version().IsGt(latestVersion) {
+			catalog.latestReleases[release.Name()] = release.Version()
+		}
+	}
+
+	log.Debugf("Reading Stemcells...")
+	var stemcells []director.Stemcell
+	err = f.request(ctx, "stemcells", "reading Stemcells", func() (err error) {
+		defer f.observeRequest("stemcells", time.Now())
+		stemcells, err = f.boshClient.Stemcells()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading Stemcells: %w", err)
+	}
+
+	for _, stemcell := range stemcells {
+		if latestVersion, ok := catalog.latestStemcells[stemcell.Name()]; !ok || stemcell.Version().IsGt(latestVersion) {
+			catalog.latestStemcells[stemcell.Name()] = stemcell.Version()
+		}
+
+		if stemcell.OSName() == "" {
+			continue
+		}
+		if latestVersion, ok := catalog.latestStemcellsByOS[stemcell.OSName()]; !ok || stemcell.Version().IsGt(latestVersion) {
+			catalog.latestStemcellsByOS[stemcell.OSName()] = stemcell.Version()
+		}
+	}
+
+	return catalog, nil
+}
+
+func (f *Fetcher) fetchDeploymentReleases(ctx context.Context, deployment director.Deployment) ([]Release, error) {
 	deploymentReleases := []Release{}
 
-	log.Debugf("Reading Releases for deployment `%s`:", deployment.Name())
-	releases, err := deployment.Releases()
+	log.With("deployment", deployment.Name()).Debugf("Reading Releases...")
+	var releases []director.Release
+	err := f.request(ctx, "releases", fmt.Sprintf("reading Releases for deployment `%s`", deployment.Name()), func() (err error) {
+		defer f.observeRequest("releases", time.Now())
+		releases, err = deployment.Releases()
+		return err
+	})
 	if err != nil {
-		return deploymentReleases, fmt.Errorf("Error while reading Releases for deployment `%s`: %v", deployment.Name(), err)
+		return deploymentReleases, fmt.Errorf("Error while reading Releases for deployment `%s`: %w", deployment.Name(), err)
 	}
 
 	for _, release := range releases {
@@ -179,13 +860,18 @@ func (f *Fetcher) fetchDeploymentReleases(deployment director.Deployment) ([]Rel
 	return deploymentReleases, nil
 }
 
-func (f *Fetcher) fetchDeploymentStemcells(deployment director.Deployment) ([]Stemcell, error) {
+func (f *Fetcher) fetchDeploymentStemcells(ctx context.Context, deployment director.Deployment) ([]Stemcell, error) {
 	deploymentStemcells := []Stemcell{}
 
-	log.Debugf("Reading Stemcells for deployment `%s`:", deployment.Name())
-	stemcells, err := deployment.Stemcells()
+	log.With("deployment", deployment.Name()).Debugf("Reading Stemcells...")
+	var stemcells []director.Stemcell
+	err := f.request(ctx, "stemcells", fmt.Sprintf("reading Stemcells for deployment `%s`", deployment.Name()), func() (err error) {
+		defer f.observeRequest("stemcells", time.Now())
+		stemcells, err = deployment.Stemcells()
+		return err
+	})
 	if err != nil {
-		return deploymentStemcells, fmt.Errorf("Error while reading Stemcells for deployment `%s`: %v", deployment.Name(), err)
+		return deploymentStemcells, fmt.Errorf("Error while reading Stemcells for deployment `%s`: %w", deployment.Name(), err)
 	}
 
 	for _, stemcell := range stemcells {
@@ -193,9 +879,26 @@ func (f *Fetcher) fetchDeploymentStemcells(deployment director.Deployment) ([]St
 			Name:    stemcell.Name(),
 			Version: stemcell.Version().AsString(),
 			OSName:  stemcell.OSName(),
+			CPI:     stemcell.CPI(),
 		}
 		deploymentStemcells = append(deploymentStemcells, deploymentStemcell)
 	}
 
 	return deploymentStemcells, nil
 }
+
+func stemcellsWithAPIVersions(stemcells []Stemcell, instances []Instance) []Stemcell {
+	deploymentStemcells := []Stemcell{}
+
+	for _, stemcell := range stemcells {
+		for _, instance := range instances {
+			if instance.Stemcell.Name == stemcell.Name && instance.Stemcell.Version == stemcell.Version && instance.Stemcell.APIVersion != "" {
+				stemcell.APIVersion = instance.Stemcell.APIVersion
+				break
+			}
+		}
+		deploymentStemcells = append(deploymentStemcells, stemcell)
+	}
+
+	return deploymentStemcells
+}
@@ -1,8 +1,14 @@
 package deployments_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -10,6 +16,8 @@ import (
 	"github.com/cloudfoundry/bosh-cli/director"
 	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
 	"github.com/cppforlife/go-semi-semantic/version"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/log"
 
 	"github.com/bosh-prometheus/bosh_exporter/filters"
@@ -23,21 +31,237 @@ func init() {
 
 var _ = Describe("Fetcher", func() {
 	var (
-		err                error
-		boshDeployments    []string
-		boshClient         *directorfakes.FakeDirector
-		deploymentsFilter  *filters.DeploymentsFilter
-		deploymentsFetcher *Fetcher
+		err                    error
+		boshDeployments        []string
+		instanceGroups         []string
+		azs                    []string
+		maxInFlight            int
+		continueOnError        bool
+		metadataCacheTTL       time.Duration
+		fetchTimeout           time.Duration
+		retryAttempts          int
+		retryBackoff           time.Duration
+		includeNoVMInstances   bool
+		noVMAsUnhealthy        bool
+		requestsPerSecond      float64
+		instancesThreshold     int
+		instancesTimeout       time.Duration
+		maxInstances           int
+		excludeProcesses       []string
+		countExcluded          bool
+		tagKeys                []string
+		requestDuration        prometheus.ObserverVec
+		requestErrors          *prometheus.CounterVec
+		deploymentsScraped     prometheus.Gauge
+		deploymentsFiltered    prometheus.Gauge
+		deploymentFetchErrors  *prometheus.CounterVec
+		instancesTimeouts      *prometheus.CounterVec
+		boshClient             *directorfakes.FakeDirector
+		deploymentsFilter      *filters.DeploymentsFilter
+		instanceGroupsFilter   *filters.InstanceGroupsFilter
+		azsFilter              *filters.AZsFilter
+		excludeProcessesFilter *filters.RegexpFilter
+		deploymentsFetcher     *Fetcher
 	)
 
 	BeforeEach(func() {
 		boshDeployments = []string{}
+		instanceGroups = []string{}
+		azs = []string{}
+		maxInFlight = 0
+		continueOnError = false
+		metadataCacheTTL = 0
+		fetchTimeout = 0
+		retryAttempts = 1
+		retryBackoff = 0
+		includeNoVMInstances = false
+		noVMAsUnhealthy = false
+		requestsPerSecond = 0
+		instancesThreshold = 0
+		instancesTimeout = 0
+		maxInstances = 0
+		excludeProcesses = []string{}
+		countExcluded = false
+		tagKeys = nil
+		requestDuration = nil
+		requestErrors = nil
+		deploymentsScraped = nil
+		deploymentsFiltered = nil
+		deploymentFetchErrors = nil
+		instancesTimeouts = nil
 		boshClient = &directorfakes.FakeDirector{}
 	})
 
 	JustBeforeEach(func() {
-		deploymentsFilter = filters.NewDeploymentsFilter(boshDeployments, boshClient)
-		deploymentsFetcher = NewFetcher(*deploymentsFilter)
+		deploymentsFilter, err = filters.NewDeploymentsFilter(boshDeployments, []string{}, boshClient)
+		Expect(err).ToNot(HaveOccurred())
+		instanceGroupsFilter, err = filters.NewInstanceGroupsFilter(instanceGroups, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		azsFilter = filters.NewAZsFilter(azs)
+		excludeProcessesFilter, err = filters.NewRegexpFilter(excludeProcesses)
+		Expect(err).ToNot(HaveOccurred())
+		deploymentsFetcher = NewFetcher(boshClient, FetcherOptions{
+			DeploymentsFilter:      *deploymentsFilter,
+			InstanceGroupsFilter:   *instanceGroupsFilter,
+			AZsFilter:              *azsFilter,
+			MaxInFlight:            maxInFlight,
+			ContinueOnError:        continueOnError,
+			MetadataCacheTTL:       metadataCacheTTL,
+			FetchTimeout:           fetchTimeout,
+			RetryAttempts:          retryAttempts,
+			RetryBackoff:           retryBackoff,
+			IncludeNoVMInstances:   includeNoVMInstances,
+			NoVMAsUnhealthy:        noVMAsUnhealthy,
+			RequestsPerSecond:      requestsPerSecond,
+			InstancesThreshold:     instancesThreshold,
+			InstancesTimeout:       instancesTimeout,
+			MaxInstances:           maxInstances,
+			ExcludeProcessesFilter: excludeProcessesFilter,
+			CountExcludedProcesses: countExcluded,
+			TagKeys:                tagKeys,
+			RequestDuration:        requestDuration,
+			RequestErrors:          requestErrors,
+			DeploymentsScraped:     deploymentsScraped,
+			DeploymentsFiltered:    deploymentsFiltered,
+			DeploymentFetchErrors:  deploymentFetchErrors,
+			InstancesTimeouts:      instancesTimeouts,
+		})
+	})
+
+	Describe("DeploymentsContext", func() {
+		var (
+			unblock     chan struct{}
+			ctx         context.Context
+			cancel      context.CancelFunc
+			deployments []DeploymentInfo
+			returned    chan struct{}
+		)
+
+		BeforeEach(func() {
+			unblock = make(chan struct{})
+			returned = make(chan struct{})
+			ctx, cancel = context.WithCancel(context.Background())
+
+			// The abandoned fetch outlives the spec, so it must not read
+			// unblock after the next BeforeEach reassigns it.
+			specUnblock := unblock
+			boshClient.DeploymentsReturns([]director.Deployment{
+				&directorfakes.FakeDeployment{
+					NameStub: func() string { return "fake-deployment-name" },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						<-specUnblock
+						return []director.VMInfo{}, nil
+					},
+				},
+			}, nil)
+		})
+
+		AfterEach(func() {
+			cancel()
+			close(unblock)
+		})
+
+		Context("when the context is cancelled", func() {
+			JustBeforeEach(func() {
+				go func() {
+					defer close(returned)
+					deployments, err = deploymentsFetcher.DeploymentsContext(ctx)
+				}()
+				cancel()
+			})
+
+			It("returns promptly with a cancellation error", func() {
+				Eventually(returned).Should(BeClosed())
+				Expect(deployments).To(BeEmpty())
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+
+		Context("when the fetcher is stopped", func() {
+			JustBeforeEach(func() {
+				go func() {
+					defer close(returned)
+					deployments, err = deploymentsFetcher.Deployments()
+				}()
+				deploymentsFetcher.Stop()
+			})
+
+			It("returns promptly with a cancellation error", func() {
+				Eventually(returned).Should(BeClosed())
+				Expect(deployments).To(BeEmpty())
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+
+		Context("when the fetch timeout expires", func() {
+			BeforeEach(func() {
+				fetchTimeout = 10 * time.Millisecond
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					defer close(returned)
+					deployments, err = deploymentsFetcher.Deployments()
+				}()
+			})
+
+			It("returns promptly with a deadline exceeded error", func() {
+				Eventually(returned).Should(BeClosed())
+				Expect(deployments).To(BeEmpty())
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+
+		Context("when the instances timeout expires and continue on error is enabled", func() {
+			BeforeEach(func() {
+				instancesTimeout = 10 * time.Millisecond
+				continueOnError = true
+				deploymentFetchErrors = prometheus.NewCounterVec(
+					prometheus.CounterOpts{
+						Name: "test_deployment_fetch_errors_total",
+						Help: "Test Counter.",
+					},
+					[]string{"bosh_deployment"},
+				)
+				instancesTimeouts = prometheus.NewCounterVec(
+					prometheus.CounterOpts{
+						Name: "test_instances_timeouts_total",
+						Help: "Test Counter.",
+					},
+					[]string{"bosh_deployment"},
+				)
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					defer close(returned)
+					deployments, err = deploymentsFetcher.Deployments()
+				}()
+			})
+
+			It("returns promptly with an instances timeout error for the deployment", func() {
+				Eventually(returned).Should(BeClosed())
+				Expect(deployments).To(BeEmpty())
+
+				var deploymentError *DeploymentError
+				Expect(errors.As(err, &deploymentError)).To(BeTrue())
+				Expect(deploymentError.Deployment).To(Equal("fake-deployment-name"))
+
+				var timeoutError *InstancesTimeoutError
+				Expect(errors.As(err, &timeoutError)).To(BeTrue())
+				Expect(timeoutError.Error()).To(Equal("Timed out after 10ms while reading Instances for deployment `fake-deployment-name`"))
+			})
+
+			It("counts a fetch error and an instances timeout for the deployment", func() {
+				Eventually(returned).Should(BeClosed())
+
+				metric := &dto.Metric{}
+				Expect(deploymentFetchErrors.WithLabelValues("fake-deployment-name").Write(metric)).To(Succeed())
+				Expect(metric.GetCounter().GetValue()).To(Equal(float64(1)))
+				Expect(instancesTimeouts.WithLabelValues("fake-deployment-name").Write(metric)).To(Succeed())
+				Expect(metric.GetCounter().GetValue()).To(Equal(float64(1)))
+			})
+		})
 	})
 
 	Describe("Deployments", func() {
@@ -49,11 +273,14 @@ var _ = Describe("Fetcher", func() {
 			jobIndex                      = 0
 			jobBootstrap                  = true
 			jobIP                         = "1.2.3.4"
+			jobDNS                        = "fake-job-id.fake-job-name.default.fake-deployment-name.bosh"
 			jobAZ                         = "fake-job-az"
 			jobVMType                     = "fake-job-vm-type"
 			jobResourcePool               = "fake-job-resource-pool"
 			jobResurrectionPause          = true
 			jobVMID                       = "fake-job-vmid"
+			jobState                      = "started"
+			errandName                    = "fake-errand-name"
 			processState                  = "running"
 			jobUptimeSeconds              = uint64(3600)
 			jobLoadAvg01                  = float64(0.01)
@@ -76,6 +303,8 @@ var _ = Describe("Fetcher", func() {
 			jobProcessState               = "running"
 			jobProcessUptimeSeconds       = uint64(3600)
 			jobProcessCPUTotal            = float64(0.5)
+			jobProcessCPUUser             = float64(0.3)
+			jobProcessCPUSys              = float64(0.2)
 			jobProcessMemKB               = uint64(2000)
 			jobProcessMemPercent          = float64(20)
 			releaseName                   = "fake-release-name"
@@ -83,11 +312,14 @@ var _ = Describe("Fetcher", func() {
 			stemcellName                  = "fake-stemcell-name"
 			stemcellVersion               = "4.5.6"
 			stemcellOSName                = "fake-stemcell-os-name"
+			stemcellCPI                   = "fake-stemcell-cpi"
+			stemcellAPIVersion            = 2
 
 			processes   []director.VMInfoProcess
 			vitals      director.VMInfoVitals
 			instances   []director.VMInfo
 			release     director.Release
+			errands     []director.Errand
 			releases    []director.Release
 			stemcell    director.Stemcell
 			stemcells   []director.Stemcell
@@ -96,14 +328,19 @@ var _ = Describe("Fetcher", func() {
 
 			deploymentsInfo         []DeploymentInfo
 			expectedDeploymentsInfo []DeploymentInfo
+			fetchDurations          []time.Duration
 		)
 
 		BeforeEach(func() {
 			processes = []director.VMInfoProcess{
 				{
-					Name:   jobProcessName,
-					State:  jobProcessState,
-					CPU:    director.VMInfoVitalsCPU{Total: &jobProcessCPUTotal},
+					Name:  jobProcessName,
+					State: jobProcessState,
+					CPU: director.VMInfoVitalsCPU{
+						Total: &jobProcessCPUTotal,
+						User:  strconv.FormatFloat(jobProcessCPUUser, 'E', -1, 64),
+						Sys:   strconv.FormatFloat(jobProcessCPUSys, 'E', -1, 64),
+					},
 					Mem:    director.VMInfoVitalsMemIntSize{KB: &jobProcessMemKB, Percent: &jobProcessMemPercent},
 					Uptime: director.VMInfoVitalsUptime{Seconds: &jobProcessUptimeSeconds},
 				},
@@ -156,13 +393,20 @@ var _ = Describe("Fetcher", func() {
 					Bootstrap:          jobBootstrap,
 					ProcessState:       processState,
 					IPs:                []string{jobIP},
+					DNS:                []string{jobDNS},
 					AZ:                 jobAZ,
 					VMType:             jobVMType,
 					ResourcePool:       jobResourcePool,
 					ResurrectionPaused: jobResurrectionPause,
 					VMID:               jobVMID,
+					State:              jobState,
 					Vitals:             vitals,
 					Processes:          processes,
+					Stemcell: director.VmInfoStemcell{
+						Name:       stemcellName,
+						Version:    stemcellVersion,
+						ApiVersion: stemcellAPIVersion,
+					},
 				},
 			}
 
@@ -172,18 +416,30 @@ var _ = Describe("Fetcher", func() {
 			}
 			releases = []director.Release{release}
 
+			boshClient.ReleasesReturns([]director.Release{
+				&directorfakes.FakeRelease{
+					NameStub:        func() string { return releaseName },
+					VersionStub:     func() version.Version { return version.MustNewVersionFromString(releaseVersion) },
+					VersionMarkStub: func(mark string) string { return mark },
+				},
+			}, nil)
+
 			stemcell = &directorfakes.FakeStemcell{
 				NameStub:    func() string { return stemcellName },
 				VersionStub: func() version.Version { return version.MustNewVersionFromString(stemcellVersion) },
 				OSNameStub:  func() string { return stemcellOSName },
+				CPIStub:     func() string { return stemcellCPI },
 			}
 			stemcells = []director.Stemcell{stemcell}
 
+			errands = []director.Errand{{Name: errandName}}
+
 			deployment = &directorfakes.FakeDeployment{
 				NameStub:          func() string { return deploymentName },
 				InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
 				ReleasesStub:      func() ([]director.Release, error) { return releases, nil },
 				StemcellsStub:     func() ([]director.Stemcell, error) { return stemcells, nil },
+				ErrandsStub:       func() ([]director.Errand, error) { return errands, nil },
 			}
 
 			deployments = []director.Deployment{deployment}
@@ -200,9 +456,12 @@ var _ = Describe("Fetcher", func() {
 							Index:              strconv.Itoa(int(jobIndex)),
 							Bootstrap:          jobBootstrap,
 							IPs:                []string{jobIP},
+							DNS:                []string{jobDNS},
 							AZ:                 jobAZ,
 							VMType:             jobVMType,
 							ResourcePool:       jobResourcePool,
+							VMID:               jobVMID,
+							State:              jobState,
 							ResurrectionPaused: jobResurrectionPause,
 							Healthy:            true,
 							Processes: []Process{
@@ -210,8 +469,12 @@ var _ = Describe("Fetcher", func() {
 									Name:    jobProcessName,
 									Uptime:  &jobProcessUptimeSeconds,
 									Healthy: true,
-									CPU:     CPU{Total: &jobProcessCPUTotal},
-									Mem:     MemInt{KB: &jobProcessMemKB, Percent: &jobProcessMemPercent},
+									CPU: CPU{
+										Total: &jobProcessCPUTotal,
+										User:  strconv.FormatFloat(jobProcessCPUUser, 'E', -1, 64),
+										Sys:   strconv.FormatFloat(jobProcessCPUSys, 'E', -1, 64),
+									},
+									Mem: MemInt{KB: &jobProcessMemKB, Percent: &jobProcessMemPercent},
 								},
 							},
 							Vitals: Vitals{
@@ -247,20 +510,42 @@ var _ = Describe("Fetcher", func() {
 									Percent:      strconv.Itoa(int(jobPersistentDiskPercent)),
 								},
 							},
+							Stemcell: Stemcell{
+								Name:       stemcellName,
+								Version:    stemcellVersion,
+								APIVersion: strconv.Itoa(stemcellAPIVersion),
+							},
 						},
 					},
+					Errands: []Errand{
+						Errand{Name: errandName},
+					},
 					Releases: []Release{
 						Release{Name: releaseName, Version: releaseVersion},
 					},
 					Stemcells: []Stemcell{
-						Stemcell{Name: stemcellName, Version: stemcellVersion, OSName: stemcellOSName},
+						Stemcell{
+							Name:             stemcellName,
+							Version:          stemcellVersion,
+							OSName:           stemcellOSName,
+							CPI:              stemcellCPI,
+							APIVersion:       strconv.Itoa(stemcellAPIVersion),
+							UpgradeAvailable: new(bool),
+						},
 					},
+					Stale: new(bool),
 				},
 			}
 		})
 
 		JustBeforeEach(func() {
 			deploymentsInfo, err = deploymentsFetcher.Deployments()
+
+			fetchDurations = []time.Duration{}
+			for i := range deploymentsInfo {
+				fetchDurations = append(fetchDurations, deploymentsInfo[i].FetchDuration)
+				deploymentsInfo[i].FetchDuration = 0
+			}
 		})
 
 		It("returns the deployments", func() {
@@ -268,6 +553,126 @@ var _ = Describe("Fetcher", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("returns how long it took to fetch each deployment", func() {
+			Expect(fetchDurations).To(HaveLen(1))
+			Expect(fetchDurations[0]).To(BeNumerically(">", 0))
+		})
+
+		It("does not read the deployment manifest", func() {
+			Expect(deployment.(*directorfakes.FakeDeployment).ManifestCallCount()).To(Equal(0))
+		})
+
+		Context("when the director request duration is observed", func() {
+			var requestDurationMetric *prometheus.HistogramVec
+
+			requestCount := func(endpoint string) uint64 {
+				metric := &dto.Metric{}
+				Expect(requestDurationMetric.WithLabelValues(endpoint).(prometheus.Histogram).Write(metric)).To(Succeed())
+				return metric.GetHistogram().GetSampleCount()
+			}
+
+			BeforeEach(func() {
+				requestDurationMetric = prometheus.NewHistogramVec(
+					prometheus.HistogramOpts{
+						Name: "test_request_duration_seconds",
+						Help: "Test Histogram.",
+					},
+					[]string{"endpoint"},
+				)
+				requestDuration = requestDurationMetric
+			})
+
+			It("observes each director call by endpoint", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestCount("deployments")).To(Equal(uint64(1)))
+				Expect(requestCount("instances")).To(Equal(uint64(1)))
+				Expect(requestCount("errands")).To(Equal(uint64(1)))
+				Expect(requestCount("releases")).To(Equal(uint64(2)))
+				Expect(requestCount("stemcells")).To(Equal(uint64(2)))
+				Expect(requestCount("manifest")).To(Equal(uint64(0)))
+			})
+		})
+
+		Context("when the scraped and filtered deployments are observed", func() {
+			gaugeValue := func(gauge prometheus.Gauge) float64 {
+				metric := &dto.Metric{}
+				Expect(gauge.Write(metric)).To(Succeed())
+				return metric.GetGauge().GetValue()
+			}
+
+			BeforeEach(func() {
+				deploymentsScraped = prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "test_deployments_scraped_total",
+					Help: "Test Gauge.",
+				})
+				deploymentsFiltered = prometheus.NewGauge(prometheus.GaugeOpts{
+					Name: "test_deployments_filtered_total",
+					Help: "Test Gauge.",
+				})
+			})
+
+			It("sets the number of scraped and filtered deployments", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(gaugeValue(deploymentsScraped)).To(Equal(float64(1)))
+				Expect(gaugeValue(deploymentsFiltered)).To(Equal(float64(0)))
+			})
+
+			Context("and the deployments filter excludes every deployment", func() {
+				BeforeEach(func() {
+					boshDeployments = []string{"~^fake-other-"}
+				})
+
+				It("sets the number of scraped and filtered deployments", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(gaugeValue(deploymentsScraped)).To(Equal(float64(0)))
+					Expect(gaugeValue(deploymentsFiltered)).To(Equal(float64(1)))
+				})
+			})
+		})
+
+		Context("when tag keys are configured", func() {
+			BeforeEach(func() {
+				tagKeys = []string{"team", "cost-center", "missing"}
+				deployment.(*directorfakes.FakeDeployment).ManifestReturns("name: fake-deployment-name\ntags:\n  team: fake-team\n  cost-center: 42\n  other: fake-other\n", nil)
+			})
+
+			It("returns the configured tags of the deployment", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentsInfo[0].Tags).To(Equal(map[string]string{"team": "fake-team", "cost-center": "42"}))
+			})
+
+			Context("when the manifest has no tags", func() {
+				BeforeEach(func() {
+					deployment.(*directorfakes.FakeDeployment).ManifestReturns("name: fake-deployment-name\n", nil)
+				})
+
+				It("returns no tags", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deploymentsInfo[0].Tags).To(BeNil())
+				})
+			})
+
+			Context("when the manifest is invalid", func() {
+				BeforeEach(func() {
+					deployment.(*directorfakes.FakeDeployment).ManifestReturns("tags: [", nil)
+				})
+
+				It("does not return the deployment", func() {
+					Expect(deploymentsInfo).To(BeEmpty())
+				})
+			})
+
+			Context("when reading the manifest fails", func() {
+				BeforeEach(func() {
+					deployment.(*directorfakes.FakeDeployment).ManifestReturns("", errors.New("no manifest"))
+				})
+
+				It("does not return the deployment", func() {
+					Expect(deploymentsInfo).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("when instance has no VMID", func() {
 			BeforeEach(func() {
 				instances[0].VMID = ""
@@ -286,125 +691,1301 @@ var _ = Describe("Fetcher", func() {
 			})
 		})
 
-		Context("when there are no deployments", func() {
+		Context("when the instance is ignored", func() {
 			BeforeEach(func() {
-				boshClient.DeploymentsReturns([]director.Deployment{}, nil)
+				instances[0].Ignore = true
 			})
 
-			It("does not return deployments", func() {
-				Expect(deploymentsInfo).To(BeEmpty())
+			It("returns the instance as ignored", func() {
+				Expect(deploymentsInfo[0].Instances[0].Ignore).To(BeTrue())
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when it fails to get the deployment", func() {
+		Context("when the director reports the VM creation time", func() {
+			var vmCreatedAt = time.Date(2023, time.November, 14, 22, 13, 20, 0, time.UTC)
+
 			BeforeEach(func() {
-				boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("no deployments"))
+				instances[0].VMCreatedAt = vmCreatedAt
 			})
 
-			It("does not return deployments", func() {
-				Expect(deploymentsInfo).To(BeEmpty())
-				Expect(err).To(HaveOccurred())
+			It("returns the VM creation time", func() {
+				Expect(deploymentsInfo[0].Instances[0].VMCreatedAt).To(Equal(&vmCreatedAt))
+				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when there are no instances", func() {
+		It("does not return a VM creation time when the director does not report it", func() {
+			Expect(deploymentsInfo[0].Instances[0].VMCreatedAt).To(BeNil())
+		})
+
+		Context("when the director only reports CPU vitals", func() {
+			BeforeEach(func() {
+				instances[0].Vitals = director.VMInfoVitals{
+					CPU: director.VMInfoVitalsCPU{
+						Sys:  strconv.FormatFloat(jobCPUSys, 'E', -1, 64),
+						User: strconv.FormatFloat(jobCPUUser, 'E', -1, 64),
+						Wait: strconv.FormatFloat(jobCPUWait, 'E', -1, 64),
+					},
+				}
+			})
+
+			It("returns the CPU vitals and leaves the others empty", func() {
+				Expect(deploymentsInfo[0].Instances[0].Vitals).To(Equal(Vitals{
+					CPU: CPU{
+						Sys:  strconv.FormatFloat(jobCPUSys, 'E', -1, 64),
+						User: strconv.FormatFloat(jobCPUUser, 'E', -1, 64),
+						Wait: strconv.FormatFloat(jobCPUWait, 'E', -1, 64),
+					},
+				}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when processes are excluded", func() {
+			BeforeEach(func() {
+				processes = append(processes, director.VMInfoProcess{
+					Name:  "bosh-dns",
+					State: "failing",
+				})
+				instances[0].Processes = processes
+				excludeProcesses = []string{"^bosh-dns"}
+			})
+
+			It("does not return the excluded processes", func() {
+				Expect(deploymentsInfo[0].Instances[0].Processes).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Instances[0].Processes[0].Name).To(Equal(jobProcessName))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("does not count the excluded processes", func() {
+				Expect(deploymentsInfo[0].Instances[0].ExcludedProcesses).To(Equal(0))
+				Expect(deploymentsInfo[0].Instances[0].ExcludedFailingProcesses).To(Equal(0))
+			})
+
+			Context("and excluded processes are counted", func() {
+				BeforeEach(func() {
+					countExcluded = true
+				})
+
+				It("counts the excluded processes", func() {
+					Expect(deploymentsInfo[0].Instances[0].Processes).To(HaveLen(1))
+					Expect(deploymentsInfo[0].Instances[0].ExcludedProcesses).To(Equal(1))
+					Expect(deploymentsInfo[0].Instances[0].ExcludedFailingProcesses).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when instance has no VMID and VM-less instances are included", func() {
+			BeforeEach(func() {
+				instances[0].VMID = ""
+				includeNoVMInstances = true
+			})
+
+			It("returns the instance as unhealthy without a VM", func() {
+				Expect(deploymentsInfo[0].Instances).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Instances[0].Name).To(Equal(jobName))
+				Expect(deploymentsInfo[0].Instances[0].NoVM).To(BeTrue())
+				Expect(deploymentsInfo[0].Instances[0].Healthy).To(BeFalse())
+				Expect(deploymentsInfo[0].Instances[0].UnhealthyReason).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when instance has no VMID and VM-less instances are reported as unhealthy", func() {
+			BeforeEach(func() {
+				instances[0].VMID = ""
+				noVMAsUnhealthy = true
+			})
+
+			It("returns the instance as unhealthy with the no_vm reason", func() {
+				Expect(deploymentsInfo[0].Instances).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Instances[0].Name).To(Equal(jobName))
+				Expect(deploymentsInfo[0].Instances[0].VMID).To(BeEmpty())
+				Expect(deploymentsInfo[0].Instances[0].NoVM).To(BeTrue())
+				Expect(deploymentsInfo[0].Instances[0].Healthy).To(BeFalse())
+				Expect(deploymentsInfo[0].Instances[0].UnhealthyReason).To(Equal(NoVMReason))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when reading the instances fails with transient errors", func() {
+			var (
+				failures     int
+				instanceCall int
+			)
+
 			BeforeEach(func() {
+				retryAttempts = 3
+				retryBackoff = time.Millisecond
+				failures = 2
+				instanceCall = 0
 				deployment = &directorfakes.FakeDeployment{
-					NameStub:      func() string { return deploymentName },
+					NameStub: func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						instanceCall++
+						if instanceCall <= failures {
+							return nil, errors.New("Director responded with non-successful status code '502' response 'Bad Gateway'")
+						}
+						return instances, nil
+					},
 					ReleasesStub:  func() ([]director.Release, error) { return releases, nil },
 					StemcellsStub: func() ([]director.Stemcell, error) { return stemcells, nil },
+					ErrandsStub:   func() ([]director.Errand, error) { return errands, nil },
 				}
 				deployments = []director.Deployment{deployment}
 				boshClient.DeploymentsReturns(deployments, nil)
 			})
 
-			It("does not return instances", func() {
-				Expect(deploymentsInfo[0].Instances).To(BeEmpty())
+			It("retries until it succeeds", func() {
+				Expect(instanceCall).To(Equal(3))
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			Context("and it runs out of attempts", func() {
+				BeforeEach(func() {
+					failures = 3
+				})
+
+				It("does not return the deployment", func() {
+					Expect(instanceCall).To(Equal(3))
+					Expect(deploymentsInfo).To(BeEmpty())
+				})
+			})
 		})
 
-		Context("when it fails to get the deployment instances", func() {
+		Context("when reading the instances fails with a client error", func() {
+			var (
+				instanceCall int
+			)
+
 			BeforeEach(func() {
+				retryAttempts = 3
+				retryBackoff = time.Millisecond
+				instanceCall = 0
 				deployment = &directorfakes.FakeDeployment{
-					NameStub:          func() string { return deploymentName },
-					InstanceInfosStub: func() ([]director.VMInfo, error) { return nil, errors.New("no instances") },
+					NameStub: func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						instanceCall++
+						return nil, errors.New("Director responded with non-successful status code '401' response 'Unauthorized'")
+					},
 				}
 				deployments = []director.Deployment{deployment}
 				boshClient.DeploymentsReturns(deployments, nil)
 			})
 
-			It("does not return deployments", func() {
+			It("does not retry", func() {
+				Expect(instanceCall).To(Equal(1))
 				Expect(deploymentsInfo).To(BeEmpty())
+			})
+		})
+
+		Context("when reading the director releases fails with a network error", func() {
+			BeforeEach(func() {
+				retryAttempts = 2
+				retryBackoff = time.Millisecond
+				boshClient.ReleasesReturnsOnCall(0, nil, errors.New("Performing request GET '/releases': dial tcp: connection refused"))
+				boshClient.ReleasesReturnsOnCall(1, []director.Release{
+					&directorfakes.FakeRelease{
+						NameStub:        func() string { return releaseName },
+						VersionStub:     func() version.Version { return version.MustNewVersionFromString(releaseVersion) },
+						VersionMarkStub: func(mark string) string { return mark },
+					},
+				}, nil)
+			})
+
+			It("retries until it succeeds", func() {
+				Expect(boshClient.ReleasesCallCount()).To(Equal(2))
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when there are no releases", func() {
+		It("returns the deployment as not stale", func() {
+			Expect(deploymentsInfo[0].Stale).To(HaveValue(BeFalse()))
+		})
+
+		Context("when a newer release version is uploaded to the director", func() {
 			BeforeEach(func() {
-				deployment = &directorfakes.FakeDeployment{
-					NameStub:          func() string { return deploymentName },
-					InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
-					StemcellsStub:     func() ([]director.Stemcell, error) { return stemcells, nil },
-				}
-				deployments = []director.Deployment{deployment}
-				boshClient.DeploymentsReturns(deployments, nil)
+				boshClient.ReleasesReturns([]director.Release{
+					&directorfakes.FakeRelease{
+						NameStub:        func() string { return releaseName },
+						VersionStub:     func() version.Version { return version.MustNewVersionFromString(releaseVersion) },
+						VersionMarkStub: func(mark string) string { return mark },
+					},
+					&directorfakes.FakeRelease{
+						NameStub:    func() string { return releaseName },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("1.10.0") },
+					},
+				}, nil)
 			})
 
-			It("does not return releases", func() {
-				Expect(deploymentsInfo[0].Releases).To(BeEmpty())
+			It("returns the deployment as stale", func() {
+				Expect(deploymentsInfo[0].Stale).To(HaveValue(BeTrue()))
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when it fails to get the deployment releases", func() {
+		Context("when a newer stemcell version is uploaded to the director", func() {
 			BeforeEach(func() {
-				deployment = &directorfakes.FakeDeployment{
-					NameStub:     func() string { return deploymentName },
-					ReleasesStub: func() ([]director.Release, error) { return nil, errors.New("no releases") },
-				}
-				deployments = []director.Deployment{deployment}
-				boshClient.DeploymentsReturns(deployments, nil)
+				boshClient.StemcellsReturns([]director.Stemcell{
+					stemcell,
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return stemcellName },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("4.6") },
+					},
+				}, nil)
 			})
 
-			It("does not return deployments", func() {
-				Expect(deploymentsInfo).To(BeEmpty())
+			It("returns the deployment as stale", func() {
+				Expect(deploymentsInfo[0].Stale).To(HaveValue(BeTrue()))
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when there are no stemcells", func() {
+		Context("when an older stemcell version is uploaded to the director", func() {
 			BeforeEach(func() {
-				deployment = &directorfakes.FakeDeployment{
-					NameStub:          func() string { return deploymentName },
-					InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
-					ReleasesStub:      func() ([]director.Release, error) { return releases, nil },
-				}
-				deployments = []director.Deployment{deployment}
-				boshClient.DeploymentsReturns(deployments, nil)
+				boshClient.StemcellsReturns([]director.Stemcell{
+					stemcell,
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return stemcellName },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("4.5.5") },
+					},
+				}, nil)
 			})
 
-			It("does not return stemcells", func() {
-				Expect(deploymentsInfo[0].Stemcells).To(BeEmpty())
+			It("returns the deployment as not stale", func() {
+				Expect(deploymentsInfo[0].Stale).To(HaveValue(BeFalse()))
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
-		Context("when it fails to get the deployment stemcells", func() {
+		Context("when a newer stemcell version for the same OS is uploaded to the director", func() {
 			BeforeEach(func() {
-				deployment = &directorfakes.FakeDeployment{
-					NameStub:      func() string { return deploymentName },
-					StemcellsStub: func() ([]director.Stemcell, error) { return nil, errors.New("no stemcells") },
+				boshClient.StemcellsReturns([]director.Stemcell{
+					stemcell,
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return "fake-other-stemcell-name" },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("4.6") },
+						OSNameStub:  func() string { return stemcellOSName },
+					},
+				}, nil)
+			})
+
+			It("returns the stemcell with an upgrade available", func() {
+				Expect(deploymentsInfo[0].Stemcells).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Stemcells[0].UpgradeAvailable).To(HaveValue(BeTrue()))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when a newer stemcell version for another OS is uploaded to the director", func() {
+			BeforeEach(func() {
+				boshClient.StemcellsReturns([]director.Stemcell{
+					stemcell,
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return "fake-other-stemcell-name" },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("4.6") },
+						OSNameStub:  func() string { return "fake-other-stemcell-os-name" },
+					},
+				}, nil)
+			})
+
+			It("returns the stemcell without an upgrade available", func() {
+				Expect(deploymentsInfo[0].Stemcells).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Stemcells[0].UpgradeAvailable).To(HaveValue(BeFalse()))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the stemcell versions only differ in a multi-digit segment", func() {
+			BeforeEach(func() {
+				stemcells = []director.Stemcell{
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return stemcellName },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("621.99") },
+						OSNameStub:  func() string { return stemcellOSName },
+					},
 				}
-				deployments = []director.Deployment{deployment}
-				boshClient.DeploymentsReturns(deployments, nil)
+
+				boshClient.StemcellsReturns([]director.Stemcell{
+					stemcells[0],
+					&directorfakes.FakeStemcell{
+						NameStub:    func() string { return stemcellName },
+						VersionStub: func() version.Version { return version.MustNewVersionFromString("621.125") },
+						OSNameStub:  func() string { return stemcellOSName },
+					},
+				}, nil)
 			})
 
-			It("does not return deployments", func() {
-				Expect(deploymentsInfo).To(BeEmpty())
+			It("compares the segments numerically", func() {
+				Expect(deploymentsInfo[0].Stemcells).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Stemcells[0].UpgradeAvailable).To(HaveValue(BeTrue()))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when it fails to get the director stemcells", func() {
+			BeforeEach(func() {
+				boshClient.StemcellsReturns(nil, errors.New("no stemcells"))
+			})
+
+			It("returns the deployments without the stale and upgrade available flags", func() {
+				Expect(deploymentsInfo).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Stale).To(BeNil())
+				Expect(deploymentsInfo[0].Stemcells[0].UpgradeAvailable).To(BeNil())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when it fails to get the director releases", func() {
+			BeforeEach(func() {
+				boshClient.ReleasesReturns(nil, errors.New("no releases"))
+			})
+
+			It("returns the deployments without the stale and upgrade available flags", func() {
+				Expect(deploymentsInfo).To(HaveLen(1))
+				Expect(deploymentsInfo[0].Releases).To(Equal(expectedDeploymentsInfo[0].Releases))
+				Expect(deploymentsInfo[0].Stale).To(BeNil())
+				Expect(deploymentsInfo[0].Stemcells[0].UpgradeAvailable).To(BeNil())
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		Context("when the instances do not report a stemcell api version", func() {
+			BeforeEach(func() {
+				instances[0].Stemcell.ApiVersion = 0
+			})
+
+			It("returns the stemcells without an api version", func() {
+				Expect(deploymentsInfo[0].Stemcells[0].APIVersion).To(BeEmpty())
+				Expect(deploymentsInfo[0].Stemcells[0].CPI).To(Equal(stemcellCPI))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the metadata cache is enabled", func() {
+			BeforeEach(func() {
+				metadataCacheTTL = time.Hour
+			})
+
+			It("reports a cache miss on the first fetch", func() {
+				Expect(deploymentsInfo[0].MetadataCacheHit).To(HaveValue(BeFalse()))
+				Expect(deploymentsInfo[0].Releases).To(Equal(expectedDeploymentsInfo[0].Releases))
+				Expect(deploymentsInfo[0].Stemcells).To(Equal(expectedDeploymentsInfo[0].Stemcells))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and the deployment is fetched again within the TTL", func() {
+				JustBeforeEach(func() {
+					deploymentsInfo, err = deploymentsFetcher.Deployments()
+				})
+
+				It("does not read releases and stemcells from the director again", func() {
+					fakeDeployment := deployment.(*directorfakes.FakeDeployment)
+					Expect(fakeDeployment.InstanceInfosCallCount()).To(Equal(2))
+					Expect(fakeDeployment.ReleasesCallCount()).To(Equal(1))
+					Expect(fakeDeployment.StemcellsCallCount()).To(Equal(1))
+				})
+
+				It("does not read the director releases and stemcells again", func() {
+					Expect(boshClient.ReleasesCallCount()).To(Equal(1))
+					Expect(boshClient.StemcellsCallCount()).To(Equal(1))
+				})
+
+				It("returns the cached releases and stemcells", func() {
+					Expect(deploymentsInfo[0].MetadataCacheHit).To(HaveValue(BeTrue()))
+					Expect(deploymentsInfo[0].Releases).To(Equal(expectedDeploymentsInfo[0].Releases))
+					Expect(deploymentsInfo[0].Stemcells).To(Equal(expectedDeploymentsInfo[0].Stemcells))
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("and the deployment is fetched again after the TTL", func() {
+				BeforeEach(func() {
+					metadataCacheTTL = time.Nanosecond
+				})
+
+				JustBeforeEach(func() {
+					time.Sleep(time.Millisecond)
+					deploymentsInfo, err = deploymentsFetcher.Deployments()
+				})
+
+				It("reads releases and stemcells from the director again", func() {
+					fakeDeployment := deployment.(*directorfakes.FakeDeployment)
+					Expect(fakeDeployment.ReleasesCallCount()).To(Equal(2))
+					Expect(fakeDeployment.StemcellsCallCount()).To(Equal(2))
+					Expect(deploymentsInfo[0].MetadataCacheHit).To(HaveValue(BeFalse()))
+				})
+
+				It("reads the director releases and stemcells again", func() {
+					Expect(boshClient.ReleasesCallCount()).To(Equal(2))
+					Expect(boshClient.StemcellsCallCount()).To(Equal(2))
+				})
+			})
+		})
+
+		Context("when the instance group is enabled", func() {
+			BeforeEach(func() {
+				instanceGroups = []string{jobName}
+			})
+
+			It("returns the instance", func() {
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the instance group is not enabled", func() {
+			BeforeEach(func() {
+				instanceGroups = []string{"fake-other-job-name"}
+			})
+
+			It("does not return the instance", func() {
+				Expect(deploymentsInfo[0].Instances).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the instances list exceeds the threshold", func() {
+			BeforeEach(func() {
+				instancesThreshold = 1
+				instances = append(instances, instances[0])
+			})
+
+			It("still returns all the instances", func() {
+				Expect(deploymentsInfo[0].Instances).To(HaveLen(2))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the instances do not exceed the maximum", func() {
+			BeforeEach(func() {
+				maxInstances = 2
+				instances = append(instances, instances[0])
+			})
+
+			It("returns all the instances", func() {
+				Expect(deploymentsInfo[0].Instances).To(HaveLen(2))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		// withSpecFixtures makes the stubs of deployment return the fixtures
+		// of the current spec, as the fetches abandoned after exceeding the
+		// maximum of instances can call them after the spec ends.
+		withSpecFixtures := func(deployment *directorfakes.FakeDeployment) {
+			instances, releases, stemcells, errands := instances, releases, stemcells, errands
+			deployment.InstanceInfosStub = func() ([]director.VMInfo, error) { return instances, nil }
+			deployment.ReleasesStub = func() ([]director.Release, error) { return releases, nil }
+			deployment.StemcellsStub = func() ([]director.Stemcell, error) { return stemcells, nil }
+			deployment.ErrandsStub = func() ([]director.Errand, error) { return errands, nil }
+		}
+
+		Context("when the instances exceed the maximum", func() {
+			BeforeEach(func() {
+				maxInstances = 1
+				instances = append(instances, instances[0])
+				withSpecFixtures(deployment.(*directorfakes.FakeDeployment))
+			})
+
+			It("aborts with a max instances error", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+
+				var maxInstancesError *MaxInstancesError
+				Expect(errors.As(err, &maxInstancesError)).To(BeTrue())
+				Expect(err.Error()).To(Equal("Aborted reading deployments after exceeding the maximum of 1 instances"))
+			})
+		})
+
+		Context("when the instances of all deployments exceed the maximum", func() {
+			BeforeEach(func() {
+				maxInstances = 1
+				continueOnError = true
+				otherDeployment := &directorfakes.FakeDeployment{
+					NameStub: func() string { return deploymentName + "-other" },
+				}
+				withSpecFixtures(deployment.(*directorfakes.FakeDeployment))
+				withSpecFixtures(otherDeployment)
+				deployments = append(deployments, otherDeployment)
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("aborts with a max instances error", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+
+				var maxInstancesError *MaxInstancesError
+				Expect(errors.As(err, &maxInstancesError)).To(BeTrue())
+				Expect(maxInstancesError.Max).To(Equal(1))
+			})
+		})
+
+		Context("when the instance AZ is enabled", func() {
+			BeforeEach(func() {
+				azs = []string{"fake-other-az", jobAZ}
+			})
+
+			It("returns the instance", func() {
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the instance AZ is not enabled", func() {
+			BeforeEach(func() {
+				azs = []string{"fake-other-az"}
+			})
+
+			It("does not return the instance", func() {
+				Expect(deploymentsInfo[0].Instances).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when there are more deployments than the max in flight limit", func() {
+			var (
+				mutex       *sync.Mutex
+				inFlight    int
+				maxObserved int
+			)
+
+			BeforeEach(func() {
+				maxInFlight = 3
+				mutex = &sync.Mutex{}
+				inFlight = 0
+				maxObserved = 0
+
+				deployments = []director.Deployment{}
+				for i := 0; i < 20; i++ {
+					name := fmt.Sprintf("%s-%d", deploymentName, i)
+					deployments = append(deployments, &directorfakes.FakeDeployment{
+						NameStub: func() string { return name },
+						InstanceInfosStub: func() ([]director.VMInfo, error) {
+							mutex.Lock()
+							inFlight++
+							if inFlight > maxObserved {
+								maxObserved = inFlight
+							}
+							mutex.Unlock()
+
+							time.Sleep(10 * time.Millisecond)
+
+							mutex.Lock()
+							inFlight--
+							mutex.Unlock()
+							return instances, nil
+						},
+						ReleasesStub:  func() ([]director.Release, error) { return releases, nil },
+						StemcellsStub: func() ([]director.Stemcell, error) { return stemcells, nil },
+					})
+				}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("returns all the deployments", func() {
+				Expect(deploymentsInfo).To(HaveLen(20))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("never fetches more deployments than the max in flight limit concurrently", func() {
+				Expect(maxObserved).To(BeNumerically(">", 0))
+				Expect(maxObserved).To(BeNumerically("<=", maxInFlight))
+			})
+		})
+
+		Context("when a single deployment is fetched", func() {
+			var (
+				deploymentInfo *DeploymentInfo
+				deploymentErr  error
+			)
+
+			JustBeforeEach(func() {
+				deploymentInfo, deploymentErr = deploymentsFetcher.Deployment(deploymentName)
+			})
+
+			It("returns the deployment", func() {
+				Expect(deploymentErr).ToNot(HaveOccurred())
+				deploymentInfo.FetchDuration = 0
+				Expect(*deploymentInfo).To(Equal(expectedDeploymentsInfo[0]))
+			})
+
+			Context("and it does not exist", func() {
+				BeforeEach(func() {
+					boshClient.DeploymentsReturns([]director.Deployment{}, nil)
+				})
+
+				It("returns a deployment not found error", func() {
+					Expect(deploymentErr).To(MatchError(ErrDeploymentNotFound))
+					Expect(deploymentErr.Error()).To(ContainSubstring("Error while reading deployment `fake-deployment-name`"))
+				})
+			})
+
+			Context("and it is not matched by the deployments filter", func() {
+				BeforeEach(func() {
+					boshDeployments = []string{"~another-.*"}
+				})
+
+				It("returns a deployment not found error", func() {
+					Expect(deploymentErr).To(MatchError(ErrDeploymentNotFound))
+				})
+			})
+		})
+
+		Context("when the director requests per second are limited", func() {
+			var (
+				mutex     *sync.Mutex
+				callTimes []time.Time
+			)
+
+			BeforeEach(func() {
+				requestsPerSecond = 50
+				mutex = &sync.Mutex{}
+				callTimes = []time.Time{}
+
+				recordCall := func() {
+					mutex.Lock()
+					defer mutex.Unlock()
+					callTimes = append(callTimes, time.Now())
+				}
+
+				deployments = []director.Deployment{}
+				for i := 0; i < 3; i++ {
+					name := fmt.Sprintf("%s-%d", deploymentName, i)
+					deployments = append(deployments, &directorfakes.FakeDeployment{
+						NameStub: func() string { return name },
+						InstanceInfosStub: func() ([]director.VMInfo, error) {
+							recordCall()
+							return instances, nil
+						},
+						ErrandsStub: func() ([]director.Errand, error) {
+							recordCall()
+							return errands, nil
+						},
+						ReleasesStub: func() ([]director.Release, error) {
+							recordCall()
+							return releases, nil
+						},
+						StemcellsStub: func() ([]director.Stemcell, error) {
+							recordCall()
+							return stemcells, nil
+						},
+					})
+				}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("returns all the deployments", func() {
+				Expect(deploymentsInfo).To(HaveLen(3))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("spaces the director calls of all deployments", func() {
+				Expect(callTimes).To(HaveLen(12))
+				sort.Slice(callTimes, func(i, j int) bool { return callTimes[i].Before(callTimes[j]) })
+
+				interval := time.Duration(float64(time.Second) / requestsPerSecond)
+				span := callTimes[len(callTimes)-1].Sub(callTimes[0])
+				Expect(span).To(BeNumerically(">=", time.Duration(len(callTimes)-1)*interval*8/10))
+			})
+		})
+
+		Context("when there are multiple deployments and instances", func() {
+			var (
+				deploymentNames [][]string
+				instanceNames   [][]string
+			)
+
+			BeforeEach(func() {
+				newInstance := func(jobName string, jobIndex int) director.VMInfo {
+					return director.VMInfo{
+						JobName: jobName,
+						Index:   &jobIndex,
+						VMID:    jobVMID,
+					}
+				}
+
+				deployments = []director.Deployment{}
+				for _, suffix := range []string{"c", "a", "b"} {
+					name := deploymentName + "-" + suffix
+					deployments = append(deployments, &directorfakes.FakeDeployment{
+						NameStub: func() string { return name },
+						InstanceInfosStub: func() ([]director.VMInfo, error) {
+							return []director.VMInfo{
+								newInstance("fake-job-name-b", 1),
+								newInstance("fake-job-name-a", 10),
+								newInstance("fake-job-name-a", 2),
+							}, nil
+						},
+						ReleasesStub:  func() ([]director.Release, error) { return releases, nil },
+						StemcellsStub: func() ([]director.Stemcell, error) { return stemcells, nil },
+					})
+				}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			JustBeforeEach(func() {
+				deploymentNames = [][]string{}
+				instanceNames = [][]string{}
+				for i := 0; i < 5; i++ {
+					deploymentsInfo, err = deploymentsFetcher.Deployments()
+					Expect(err).ToNot(HaveOccurred())
+
+					names := []string{}
+					for _, deploymentInfo := range deploymentsInfo {
+						names = append(names, deploymentInfo.Name)
+					}
+					deploymentNames = append(deploymentNames, names)
+
+					instances := []string{}
+					for _, instance := range deploymentsInfo[0].Instances {
+						instances = append(instances, instance.Name+"/"+instance.Index)
+					}
+					instanceNames = append(instanceNames, instances)
+				}
+			})
+
+			It("returns the deployments sorted by name", func() {
+				for _, names := range deploymentNames {
+					Expect(names).To(Equal([]string{deploymentName + "-a", deploymentName + "-b", deploymentName + "-c"}))
+				}
+			})
+
+			It("returns the instances sorted by job name and index", func() {
+				for _, instances := range instanceNames {
+					Expect(instances).To(Equal([]string{"fake-job-name-a/2", "fake-job-name-a/10", "fake-job-name-b/1"}))
+				}
+			})
+		})
+
+		Context("when there are no deployments", func() {
+			BeforeEach(func() {
+				boshClient.DeploymentsReturns([]director.Deployment{}, nil)
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when it fails to get the deployment", func() {
+			BeforeEach(func() {
+				boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("no deployments"))
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when there are no instances", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:      func() string { return deploymentName },
+					ReleasesStub:  func() ([]director.Release, error) { return releases, nil },
+					StemcellsStub: func() ([]director.Stemcell, error) { return stemcells, nil },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return instances", func() {
+				Expect(deploymentsInfo[0].Instances).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when a director call fails", func() {
+			// instancesCall is allocated for every spec, as the InstanceInfos
+			// call can outlive the spec when the instances time out.
+			var instancesCall *struct {
+				err   error
+				delay time.Duration
+			}
+
+			requestErrorsCount := func(endpoint string, category string) float64 {
+				metric := &dto.Metric{}
+				Expect(requestErrors.WithLabelValues(endpoint, category).Write(metric)).To(Succeed())
+				return metric.GetCounter().GetValue()
+			}
+
+			BeforeEach(func() {
+				instancesCall = &struct {
+					err   error
+					delay time.Duration
+				}{err: errors.New("no instances")}
+				call := instancesCall
+				requestErrors = prometheus.NewCounterVec(
+					prometheus.CounterOpts{
+						Name: "test_request_errors_total",
+						Help: "Test Counter.",
+					},
+					[]string{"endpoint", "category"},
+				)
+				deployment = &directorfakes.FakeDeployment{
+					NameStub: func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						time.Sleep(call.delay)
+						return nil, call.err
+					},
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("counts other errors", func() {
+				Expect(requestErrorsCount("instances", DirectorErrorOther)).To(Equal(float64(1)))
+			})
+
+			Context("when the director rejects the credentials", func() {
+				BeforeEach(func() {
+					instancesCall.err = errors.New("Director responded with non-successful status code '401' response 'Unauthorized'")
+				})
+
+				It("counts an auth error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorAuth)).To(Equal(float64(1)))
+				})
+
+				It("returns an auth error", func() {
+					_, err := deploymentsFetcher.Deployment(deploymentName)
+					Expect(errors.Is(err, ErrUnauthorized)).To(BeTrue())
+					Expect(errors.Is(err, ErrDirectorTimeout)).To(BeFalse())
+
+					var requestErr *DirectorRequestError
+					Expect(errors.As(err, &requestErr)).To(BeTrue())
+					Expect(requestErr.Endpoint).To(Equal("instances"))
+				})
+			})
+
+			Context("when the director forbids the request", func() {
+				BeforeEach(func() {
+					instancesCall.err = errors.New("Director responded with non-successful status code '403' response 'Forbidden'")
+				})
+
+				It("counts an auth error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorAuth)).To(Equal(float64(1)))
+				})
+			})
+
+			Context("when the director rate limits the request", func() {
+				BeforeEach(func() {
+					instancesCall.err = errors.New("Director responded with non-successful status code '429' response 'Too Many Requests'")
+				})
+
+				It("counts a rate_limit error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorRateLimit)).To(Equal(float64(1)))
+				})
+
+				It("returns a rate limit error", func() {
+					_, err := deploymentsFetcher.Deployment(deploymentName)
+					Expect(errors.Is(err, ErrRateLimited)).To(BeTrue())
+				})
+			})
+
+			Context("when the director client times out", func() {
+				BeforeEach(func() {
+					instancesCall.err = errors.New("Performing request GET '/deployments/fake-deployment-name/instances': net/http: request canceled (Client.Timeout exceeded while awaiting headers)")
+				})
+
+				It("counts a timeout error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorTimeout)).To(Equal(float64(1)))
+				})
+
+				It("returns a timeout error", func() {
+					_, err := deploymentsFetcher.Deployment(deploymentName)
+					Expect(errors.Is(err, ErrDirectorTimeout)).To(BeTrue())
+				})
+			})
+
+			Context("when reading the instances exceeds the instances timeout", func() {
+				BeforeEach(func() {
+					instancesCall.err = nil
+					instancesCall.delay = 100 * time.Millisecond
+					instancesTimeout = 10 * time.Millisecond
+				})
+
+				It("counts a timeout error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorTimeout)).To(Equal(float64(1)))
+				})
+			})
+
+			Context("when the director fails with a server error", func() {
+				BeforeEach(func() {
+					instancesCall.err = errors.New("Director responded with non-successful status code '500' response 'Internal Server Error'")
+				})
+
+				It("counts an other error", func() {
+					Expect(requestErrorsCount("instances", DirectorErrorOther)).To(Equal(float64(1)))
+				})
+			})
+
+			Context("when reading the deployments fails", func() {
+				BeforeEach(func() {
+					boshClient.DeploymentsReturns(nil, errors.New("Director responded with non-successful status code '429' response 'Too Many Requests'"))
+				})
+
+				It("returns a rate limit error", func() {
+					Expect(errors.Is(err, ErrRateLimited)).To(BeTrue())
+					Expect(requestErrorsCount("deployments", DirectorErrorRateLimit)).To(Equal(float64(1)))
+				})
+			})
+		})
+
+		Context("when the fetch timeout expires during a retry backoff", func() {
+			var (
+				instanceCalls *int32
+			)
+
+			BeforeEach(func() {
+				retryAttempts = 3
+				retryBackoff = 50 * time.Millisecond
+				fetchTimeout = 10 * time.Millisecond
+				// The abandoned fetch outlives the spec, so it must not
+				// count into instanceCalls once the next spec reassigns it.
+				calls := new(int32)
+				instanceCalls = calls
+				deployment = &directorfakes.FakeDeployment{
+					NameStub: func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						atomic.AddInt32(calls, 1)
+						return nil, errors.New("Director responded with non-successful status code '502' response 'Bad Gateway'")
+					},
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("stops retrying the deployment", func() {
+				calls := instanceCalls
+				Eventually(func() int32 { return atomic.LoadInt32(calls) }).Should(Equal(int32(1)))
+				Consistently(func() int32 { return atomic.LoadInt32(calls) }).Should(Equal(int32(1)))
+			})
+		})
+
+		Context("when the fetch timeout expires while waiting for the limiter", func() {
+			var (
+				limitedDeployment *directorfakes.FakeDeployment
+			)
+
+			BeforeEach(func() {
+				requestsPerSecond = 20
+				fetchTimeout = 10 * time.Millisecond
+				limitedDeployment = &directorfakes.FakeDeployment{
+					NameStub: func() string { return deploymentName },
+				}
+				deployments = []director.Deployment{limitedDeployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not read the deployment once the fetch timeout expires", func() {
+				specDeployment := limitedDeployment
+				Consistently(func() int {
+					return specDeployment.InstanceInfosCallCount() + specDeployment.ReleasesCallCount() + specDeployment.StemcellsCallCount()
+				}).Should(BeZero())
+			})
+		})
+
+		Context("when it fails to get the deployment instances", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:          func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) { return nil, errors.New("no instances") },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+				deploymentFetchErrors = prometheus.NewCounterVec(
+					prometheus.CounterOpts{
+						Name: "test_deployment_fetch_errors_total",
+						Help: "Test Counter.",
+					},
+					[]string{"bosh_deployment"},
+				)
+				instancesTimeouts = prometheus.NewCounterVec(
+					prometheus.CounterOpts{
+						Name: "test_instances_timeouts_total",
+						Help: "Test Counter.",
+					},
+					[]string{"bosh_deployment"},
+				)
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("counts a fetch error for the deployment", func() {
+				metric := &dto.Metric{}
+				Expect(deploymentFetchErrors.WithLabelValues(deploymentName).Write(metric)).To(Succeed())
+				Expect(metric.GetCounter().GetValue()).To(Equal(float64(1)))
+			})
+
+			It("does not count an instances timeout for the deployment", func() {
+				metric := &dto.Metric{}
+				Expect(instancesTimeouts.WithLabelValues(deploymentName).Write(metric)).To(Succeed())
+				Expect(metric.GetCounter().GetValue()).To(Equal(float64(0)))
+			})
+		})
+
+		Context("when it fails to get some of the deployments and continue on error is enabled", func() {
+			BeforeEach(func() {
+				continueOnError = true
+				deployments = []director.Deployment{
+					deployment,
+					&directorfakes.FakeDeployment{
+						NameStub:          func() string { return deploymentName + "-failing" },
+						InstanceInfosStub: func() ([]director.VMInfo, error) { return nil, errors.New("no instances") },
+					},
+					&directorfakes.FakeDeployment{
+						NameStub:      func() string { return deploymentName + "-broken" },
+						ReleasesStub:  func() ([]director.Release, error) { return nil, errors.New("no releases") },
+						StemcellsStub: func() ([]director.Stemcell, error) { return stemcells, nil },
+					},
+				}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("returns the healthy deployments", func() {
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
+			})
+
+			It("returns an error listing every failing deployment", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(deploymentName + "-failing"))
+				Expect(err.Error()).To(ContainSubstring(deploymentName + "-broken"))
+			})
+
+			It("returns an error identifying every failing deployment", func() {
+				failedDeployments := []string{}
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var deploymentError *DeploymentError
+					Expect(errors.As(e, &deploymentError)).To(BeTrue())
+					failedDeployments = append(failedDeployments, deploymentError.Deployment)
+				}
+				Expect(failedDeployments).To(ConsistOf(deploymentName+"-failing", deploymentName+"-broken"))
+			})
+		})
+
+		Context("when there are no releases", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:          func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
+					StemcellsStub:     func() ([]director.Stemcell, error) { return stemcells, nil },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return releases", func() {
+				Expect(deploymentsInfo[0].Releases).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when it fails to get the deployment releases", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:     func() string { return deploymentName },
+					ReleasesStub: func() ([]director.Release, error) { return nil, errors.New("no releases") },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when there are no stemcells", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:          func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
+					ReleasesStub:      func() ([]director.Release, error) { return releases, nil },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return stemcells", func() {
+				Expect(deploymentsInfo[0].Stemcells).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when the instances, errands, releases and stemcells are read", func() {
+			var started *sync.WaitGroup
+
+			BeforeEach(func() {
+				started = &sync.WaitGroup{}
+				started.Add(4)
+				waitForAll := func() error {
+					started.Done()
+					allStarted := make(chan struct{})
+					go func() {
+						started.Wait()
+						close(allStarted)
+					}()
+					select {
+					case <-allStarted:
+						return nil
+					case <-time.After(5 * time.Second):
+						return errors.New("not read concurrently")
+					}
+				}
+
+				deployment = &directorfakes.FakeDeployment{
+					NameStub: func() string { return deploymentName },
+					InstanceInfosStub: func() ([]director.VMInfo, error) {
+						return instances, waitForAll()
+					},
+					ReleasesStub: func() ([]director.Release, error) {
+						return releases, waitForAll()
+					},
+					StemcellsStub: func() ([]director.Stemcell, error) {
+						return stemcells, waitForAll()
+					},
+					ErrandsStub: func() ([]director.Errand, error) {
+						return errands, waitForAll()
+					},
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("reads them concurrently and returns all of them", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
+			})
+		})
+
+		Context("when it fails to get the deployment errands", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:    func() string { return deploymentName },
+					ErrandsStub: func() ([]director.Errand, error) { return nil, errors.New("no errands") },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and continue on error is enabled", func() {
+				BeforeEach(func() {
+					continueOnError = true
+				})
+
+				It("returns the errands error", func() {
+					Expect(deploymentsInfo).To(BeEmpty())
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Error while reading Errands for deployment `fake-deployment-name`: no errands"))
+				})
+			})
+		})
+
+		Context("when it fails to get the deployment stemcells", func() {
+			BeforeEach(func() {
+				deployment = &directorfakes.FakeDeployment{
+					NameStub:      func() string { return deploymentName },
+					StemcellsStub: func() ([]director.Stemcell, error) { return nil, errors.New("no stemcells") },
+				}
+				deployments = []director.Deployment{deployment}
+				boshClient.DeploymentsReturns(deployments, nil)
+			})
+
+			It("does not return deployments", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			Context("and continue on error is enabled", func() {
+				BeforeEach(func() {
+					continueOnError = true
+					deployment = &directorfakes.FakeDeployment{
+						NameStub:          func() string { return deploymentName },
+						InstanceInfosStub: func() ([]director.VMInfo, error) { return instances, nil },
+						ReleasesStub:      func() ([]director.Release, error) { return releases, nil },
+						StemcellsStub:     func() ([]director.Stemcell, error) { return nil, errors.New("no stemcells") },
+					}
+					deployments = []director.Deployment{deployment}
+					boshClient.DeploymentsReturns(deployments, nil)
+				})
+
+				It("returns the stemcells error", func() {
+					Expect(deploymentsInfo).To(BeEmpty())
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Error while reading Stemcells for deployment `fake-deployment-name`: no stemcells"))
+				})
+			})
+		})
+	})
+
+	Describe("Releases", func() {
+		var (
+			uploadedReleases []UploadedRelease
+		)
+
+		BeforeEach(func() {
+			boshClient.ReleasesReturns([]director.Release{
+				&directorfakes.FakeRelease{
+					NameStub:        func() string { return "fake-release-name" },
+					VersionStub:     func() version.Version { return version.MustNewVersionFromString("1.0.0") },
+					VersionMarkStub: func(mark string) string { return "" },
+				},
+				&directorfakes.FakeRelease{
+					NameStub:        func() string { return "fake-release-name" },
+					VersionStub:     func() version.Version { return version.MustNewVersionFromString("1.1.0") },
+					VersionMarkStub: func(mark string) string { return mark },
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			uploadedReleases, err = deploymentsFetcher.Releases()
+		})
+
+		It("returns the releases uploaded to the director and whether they are in use", func() {
+			Expect(uploadedReleases).To(Equal([]UploadedRelease{
+				{Name: "fake-release-name", Version: "1.0.0", InUse: false},
+				{Name: "fake-release-name", Version: "1.1.0", InUse: true},
+			}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the metadata cache is enabled", func() {
+			BeforeEach(func() {
+				metadataCacheTTL = time.Hour
+			})
+
+			JustBeforeEach(func() {
+				uploadedReleases, err = deploymentsFetcher.Releases()
+			})
+
+			It("does not read the releases from the director again within the TTL", func() {
+				Expect(boshClient.ReleasesCallCount()).To(Equal(1))
+				Expect(uploadedReleases).To(HaveLen(2))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when it fails to get the director releases", func() {
+			BeforeEach(func() {
+				boshClient.ReleasesReturns(nil, errors.New("no releases"))
+			})
+
+			It("returns an error", func() {
+				Expect(uploadedReleases).To(BeEmpty())
+				Expect(err).To(HaveOccurred())
+			})
+		})
 	})
 })
@@ -0,0 +1,5 @@
+package deployments
+
+type DeploymentsSource interface {
+	Deployments() ([]DeploymentInfo, error)
+}
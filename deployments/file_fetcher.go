@@ -0,0 +1,33 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/common/log"
+)
+
+type FileFetcher struct {
+	filename string
+}
+
+func NewFileFetcher(filename string) *FileFetcher {
+	return &FileFetcher{filename: filename}
+}
+
+func (f *FileFetcher) Deployments() ([]DeploymentInfo, error) {
+	var deploymentsInfo = []DeploymentInfo{}
+
+	log.Debugf("Reading deployments from `%s`...", f.filename)
+	content, err := os.ReadFile(f.filename)
+	if err != nil {
+		return deploymentsInfo, fmt.Errorf("Error while reading deployments file `%s`: %v", f.filename, err)
+	}
+
+	if err := json.Unmarshal(content, &deploymentsInfo); err != nil {
+		return []DeploymentInfo{}, fmt.Errorf("Error while parsing deployments file `%s`: %v", f.filename, err)
+	}
+
+	return deploymentsInfo, nil
+}
@@ -0,0 +1,107 @@
+package deployments_test
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+var _ = Describe("FileFetcher", func() {
+	var (
+		err             error
+		tmpfile         *os.File
+		filename        string
+		fileFetcher     *FileFetcher
+		deploymentsInfo []DeploymentInfo
+
+		jobProcessUptimeSeconds = uint64(3600)
+		jobProcessCPUTotal      = float64(0.5)
+
+		expectedDeploymentsInfo = []DeploymentInfo{
+			DeploymentInfo{
+				Name: "fake-deployment-name",
+				Instances: []Instance{
+					Instance{
+						Name:    "fake-job-name",
+						ID:      "fake-job-id",
+						Index:   "0",
+						IPs:     []string{"1.2.3.4"},
+						Healthy: true,
+						Processes: []Process{
+							Process{
+								Name:    "fake-process-name",
+								Uptime:  &jobProcessUptimeSeconds,
+								Healthy: true,
+								CPU:     CPU{Total: &jobProcessCPUTotal},
+							},
+						},
+						Vitals: Vitals{
+							Load: []string{"0.01", "0.05", "0.15"},
+						},
+					},
+				},
+				Releases: []Release{
+					Release{Name: "fake-release-name", Version: "1.2.3"},
+				},
+				Stemcells: []Stemcell{
+					Stemcell{Name: "fake-stemcell-name", Version: "4.5.6", OSName: "fake-stemcell-os-name"},
+				},
+			},
+		}
+	)
+
+	BeforeEach(func() {
+		tmpfile, err = os.CreateTemp("", "file_fetcher_test_")
+		Expect(err).ToNot(HaveOccurred())
+		filename = tmpfile.Name()
+
+		content, err := json.Marshal(expectedDeploymentsInfo)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = tmpfile.Write(content)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tmpfile.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		err = os.Remove(tmpfile.Name())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		fileFetcher = NewFileFetcher(filename)
+		deploymentsInfo, err = fileFetcher.Deployments()
+	})
+
+	Describe("Deployments", func() {
+		It("returns the deployments", func() {
+			Expect(deploymentsInfo).To(Equal(expectedDeploymentsInfo))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when the file does not exist", func() {
+			BeforeEach(func() {
+				filename = "/non-existent-directory/deployments.json"
+			})
+
+			It("returns an error", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the file is not valid JSON", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(filename, []byte("not-json"), 0644)).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				Expect(deploymentsInfo).To(BeEmpty())
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
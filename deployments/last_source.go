@@ -0,0 +1,45 @@
+package deployments
+
+import (
+	"sync"
+)
+
+// LastSource is a DeploymentsSource remembering the deployments returned by
+// the last scrape of the wrapped source, so they can be served without
+// reading them from the BOSH Director again.
+type LastSource struct {
+	source DeploymentsSource
+
+	mu          sync.RWMutex
+	deployments []DeploymentInfo
+}
+
+func NewLastSource(source DeploymentsSource) *LastSource {
+	return &LastSource{
+		source:      source,
+		deployments: []DeploymentInfo{},
+	}
+}
+
+// Deployments reads the deployments from the wrapped source. The result is
+// remembered unless the scrape failed without returning any deployment, in
+// which case the previous deployments are kept.
+func (s *LastSource) Deployments() ([]DeploymentInfo, error) {
+	deployments, err := s.source.Deployments()
+	if err == nil || len(deployments) > 0 {
+		s.mu.Lock()
+		s.deployments = deployments
+		s.mu.Unlock()
+	}
+
+	return deployments, err
+}
+
+// Last returns the deployments remembered from the last scrape, or none
+// before the first scrape.
+func (s *LastSource) Last() []DeploymentInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.deployments
+}
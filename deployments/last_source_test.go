@@ -0,0 +1,85 @@
+package deployments_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type fakeDeploymentsSource struct {
+	deployments []DeploymentInfo
+	err         error
+	calls       int
+}
+
+func (s *fakeDeploymentsSource) Deployments() ([]DeploymentInfo, error) {
+	s.calls++
+	return s.deployments, s.err
+}
+
+var _ = Describe("LastSource", func() {
+	var (
+		source     *fakeDeploymentsSource
+		lastSource *LastSource
+
+		firstDeployments  []DeploymentInfo
+		secondDeployments []DeploymentInfo
+	)
+
+	BeforeEach(func() {
+		firstDeployments = []DeploymentInfo{{Name: "fake-deployment-name"}}
+		secondDeployments = []DeploymentInfo{{Name: "fake-other-deployment-name"}}
+		source = &fakeDeploymentsSource{deployments: firstDeployments}
+		lastSource = NewLastSource(source)
+	})
+
+	It("returns no deployments before the first scrape", func() {
+		Expect(lastSource.Last()).To(BeEmpty())
+	})
+
+	It("returns the deployments of the source", func() {
+		deploymentsInfo, err := lastSource.Deployments()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deploymentsInfo).To(Equal(firstDeployments))
+	})
+
+	It("remembers the deployments of the last scrape", func() {
+		_, err := lastSource.Deployments()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastSource.Last()).To(Equal(firstDeployments))
+
+		source.deployments = secondDeployments
+		_, err = lastSource.Deployments()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastSource.Last()).To(Equal(secondDeployments))
+	})
+
+	Context("when a scrape fails without deployments", func() {
+		It("keeps the deployments of the previous scrape", func() {
+			_, err := lastSource.Deployments()
+			Expect(err).ToNot(HaveOccurred())
+
+			source.deployments = nil
+			source.err = errors.New("fake-error")
+			_, err = lastSource.Deployments()
+			Expect(err).To(HaveOccurred())
+			Expect(lastSource.Last()).To(Equal(firstDeployments))
+		})
+	})
+
+	Context("when a scrape fails with partial deployments", func() {
+		It("remembers the partial deployments", func() {
+			_, err := lastSource.Deployments()
+			Expect(err).ToNot(HaveOccurred())
+
+			source.deployments = secondDeployments
+			source.err = errors.New("fake-error")
+			_, err = lastSource.Deployments()
+			Expect(err).To(HaveOccurred())
+			Expect(lastSource.Last()).To(Equal(secondDeployments))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package deployments
+
+import (
+	"sync"
+	"time"
+)
+
+type metadataCacheEntry struct {
+	releases  []Release
+	stemcells []Stemcell
+	expiresAt time.Time
+}
+
+type metadataCache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[string]metadataCacheEntry
+}
+
+func newMetadataCache(ttl time.Duration) *metadataCache {
+	return &metadataCache{
+		ttl:     ttl,
+		entries: make(map[string]metadataCacheEntry),
+	}
+}
+
+func (c *metadataCache) get(deploymentName string) ([]Release, []Stemcell, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[deploymentName]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, deploymentName)
+		return nil, nil, false
+	}
+
+	return entry.releases, entry.stemcells, true
+}
+
+func (c *metadataCache) set(deploymentName string, releases []Release, stemcells []Stemcell) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[deploymentName] = metadataCacheEntry{
+		releases:  releases,
+		stemcells: stemcells,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// catalogCache holds the releases and stemcells uploaded to the director,
+// shared by all deployments, for the same TTL as their metadata.
+type catalogCache struct {
+	ttl       time.Duration
+	mutex     sync.Mutex
+	catalog   *directorCatalog
+	expiresAt time.Time
+}
+
+func newCatalogCache(ttl time.Duration) *catalogCache {
+	return &catalogCache{ttl: ttl}
+}
+
+func (c *catalogCache) get() (*directorCatalog, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.catalog == nil || time.Now().After(c.expiresAt) {
+		c.catalog = nil
+		return nil, false
+	}
+
+	return c.catalog, true
+}
+
+func (c *catalogCache) set(catalog *directorCatalog) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.catalog = catalog
+	c.expiresAt = time.Now().Add(c.ttl)
+}
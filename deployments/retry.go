@@ -0,0 +1,90 @@
+package deployments
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/log"
+	"golang.org/x/time/rate"
+)
+
+var directorStatusCodeRegexp = regexp.MustCompile(`Director responded with non-successful status code '(\d+)'`)
+
+type retrier struct {
+	attempts int
+	backoff  time.Duration
+	limiter  *rate.Limiter
+}
+
+// do calls fn until it succeeds, fails with a permanent error or runs out of
+// attempts, doubling the backoff between attempts. It gives up with the
+// error of ctx as soon as ctx is done, including while waiting for the limiter.
+func (r retrier) do(ctx context.Context, description string, fn func() error) error {
+	var err error
+
+	backoff := r.backoff
+	for attempt := 1; ; attempt++ {
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		err = fn()
+		if err == nil || attempt >= r.attempts || !isTransientError(err) {
+			return err
+		}
+
+		log.With("attempt", attempt).With("backoff", backoff.String()).Debugf("Retrying %s after failure: %v", description, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// The director client does not expose typed errors, so transient failures
+// are recognised by the messages it wraps them with.
+func isTransientError(err error) bool {
+	if matches := directorStatusCodeRegexp.FindStringSubmatch(err.Error()); matches != nil {
+		statusCode, _ := strconv.Atoi(matches[1])
+		return statusCode >= 500 || statusCode == 429
+	}
+
+	return strings.Contains(err.Error(), "Performing request")
+}
+
+func directorErrorCategory(err error) string {
+	var instancesTimeoutErr *InstancesTimeoutError
+	var netErr net.Error
+	if errors.As(err, &instancesTimeoutErr) || errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return DirectorErrorTimeout
+	}
+
+	if matches := directorStatusCodeRegexp.FindStringSubmatch(err.Error()); matches != nil {
+		switch matches[1] {
+		case "401", "403":
+			return DirectorErrorAuth
+		case "429":
+			return DirectorErrorRateLimit
+		case "504":
+			return DirectorErrorTimeout
+		}
+		return DirectorErrorOther
+	}
+
+	// Client timeouts only survive as text once wrapped by the director client.
+	message := err.Error()
+	if strings.Contains(message, "Client.Timeout") || strings.Contains(message, "i/o timeout") || strings.Contains(message, "deadline exceeded") {
+		return DirectorErrorTimeout
+	}
+
+	return DirectorErrorOther
+}
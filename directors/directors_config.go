@@ -0,0 +1,60 @@
+package directors
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	Name                string `yaml:"name"`
+	URL                 string `yaml:"url"`
+	Username            string `yaml:"username"`
+	Password            string `yaml:"password"`
+	PasswordFile        string `yaml:"password_file"`
+	UAAClientID         string `yaml:"uaa_client_id"`
+	UAAClientSecret     string `yaml:"uaa_client_secret"`
+	UAAClientSecretFile string `yaml:"uaa_client_secret_file"`
+	CACertFile          string `yaml:"ca_cert_file"`
+}
+
+type configFile struct {
+	Directors []Config `yaml:"directors"`
+}
+
+// ReadConfigFile reads the list of BOSH Directors to export. Every director
+// needs a unique name, used as the value of the director label of its
+// metrics, an url and a CA certificate file.
+func ReadConfigFile(filename string) ([]Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading Directors file `%s`: %v", filename, err)
+	}
+
+	var file configFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("Error while parsing Directors file `%s`: %v", filename, err)
+	}
+
+	if len(file.Directors) == 0 {
+		return nil, fmt.Errorf("Directors file `%s` does not list any director", filename)
+	}
+
+	names := make(map[string]bool)
+	for i, config := range file.Directors {
+		if config.Name == "" {
+			return nil, fmt.Errorf("Director #%d has no name", i+1)
+		}
+		if names[config.Name] {
+			return nil, fmt.Errorf("Director `%s` is listed more than once", config.Name)
+		}
+		names[config.Name] = true
+
+		if config.URL == "" || config.CACertFile == "" {
+			return nil, fmt.Errorf("Director `%s` requires an url and a ca_cert_file", config.Name)
+		}
+	}
+
+	return file.Directors, nil
+}
@@ -0,0 +1,170 @@
+package directors_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/directors"
+)
+
+var _ = Describe("ReadConfigFile", func() {
+	var (
+		err      error
+		content  string
+		filename string
+		configs  []Config
+	)
+
+	BeforeEach(func() {
+		content = `
+directors:
+- name: fake-director-1
+  url: https://10.0.0.1:25555
+  username: admin
+  password: fake-password
+  ca_cert_file: /fake/ca-1.crt
+- name: fake-director-2
+  url: https://10.0.0.2:25555
+  uaa_client_id: fake-client
+  uaa_client_secret: fake-secret
+  ca_cert_file: /fake/ca-2.crt
+- name: fake-director-3
+  url: https://10.0.0.3:25555
+  username: admin
+  password_file: /fake/password
+  ca_cert_file: /fake/ca-3.crt
+- name: fake-director-4
+  url: https://10.0.0.4:25555
+  uaa_client_id: fake-client
+  uaa_client_secret_file: /fake/client-secret
+  ca_cert_file: /fake/ca-4.crt
+`
+	})
+
+	JustBeforeEach(func() {
+		filename = filepath.Join(GinkgoT().TempDir(), "directors.yml")
+		Expect(os.WriteFile(filename, []byte(content), 0600)).To(Succeed())
+		configs, err = ReadConfigFile(filename)
+	})
+
+	It("returns the directors", func() {
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configs).To(Equal([]Config{
+			{
+				Name:       "fake-director-1",
+				URL:        "https://10.0.0.1:25555",
+				Username:   "admin",
+				Password:   "fake-password",
+				CACertFile: "/fake/ca-1.crt",
+			},
+			{
+				Name:            "fake-director-2",
+				URL:             "https://10.0.0.2:25555",
+				UAAClientID:     "fake-client",
+				UAAClientSecret: "fake-secret",
+				CACertFile:      "/fake/ca-2.crt",
+			},
+			{
+				Name:         "fake-director-3",
+				URL:          "https://10.0.0.3:25555",
+				Username:     "admin",
+				PasswordFile: "/fake/password",
+				CACertFile:   "/fake/ca-3.crt",
+			},
+			{
+				Name:                "fake-director-4",
+				URL:                 "https://10.0.0.4:25555",
+				UAAClientID:         "fake-client",
+				UAAClientSecretFile: "/fake/client-secret",
+				CACertFile:          "/fake/ca-4.crt",
+			},
+		}))
+	})
+
+	Context("when the file does not exist", func() {
+		It("returns an error", func() {
+			_, err := ReadConfigFile(filepath.Join(GinkgoT().TempDir(), "missing.yml"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error while reading Directors file"))
+		})
+	})
+
+	Context("when the file has an unknown field", func() {
+		BeforeEach(func() {
+			content = `
+directors:
+- name: fake-director-1
+  url: https://10.0.0.1:25555
+  ca_cert: /fake/ca-1.crt
+`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error while parsing Directors file"))
+		})
+	})
+
+	Context("when the file does not list any director", func() {
+		BeforeEach(func() {
+			content = "directors: []\n"
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not list any director"))
+		})
+	})
+
+	Context("when a director has no name", func() {
+		BeforeEach(func() {
+			content = `
+directors:
+- url: https://10.0.0.1:25555
+  ca_cert_file: /fake/ca-1.crt
+`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Director #1 has no name"))
+		})
+	})
+
+	Context("when a director is listed twice", func() {
+		BeforeEach(func() {
+			content = `
+directors:
+- name: fake-director-1
+  url: https://10.0.0.1:25555
+  ca_cert_file: /fake/ca-1.crt
+- name: fake-director-1
+  url: https://10.0.0.2:25555
+  ca_cert_file: /fake/ca-2.crt
+`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Director `fake-director-1` is listed more than once"))
+		})
+	})
+
+	Context("when a director has no CA certificate file", func() {
+		BeforeEach(func() {
+			content = `
+directors:
+- name: fake-director-1
+  url: https://10.0.0.1:25555
+`
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Director `fake-director-1` requires an url and a ca_cert_file"))
+		})
+	})
+})
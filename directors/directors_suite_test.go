@@ -0,0 +1,13 @@
+package directors_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDirectors(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Directors Suite")
+}
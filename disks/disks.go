@@ -0,0 +1,14 @@
+package disks
+
+import (
+	"time"
+)
+
+type OrphanedDiskInfo struct {
+	CID           string    `json:"cid"`
+	SizeMB        uint64    `json:"size_mb"`
+	Deployment    string    `json:"deployment"`
+	InstanceGroup string    `json:"instance_group"`
+	AZ            string    `json:"az"`
+	OrphanedAt    time.Time `json:"orphaned_at"`
+}
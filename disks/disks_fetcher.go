@@ -0,0 +1,47 @@
+package disks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+)
+
+type Fetcher struct {
+	boshClient director.Director
+}
+
+func NewFetcher(boshClient director.Director) *Fetcher {
+	return &Fetcher{boshClient: boshClient}
+}
+
+func (f *Fetcher) OrphanedDisks() ([]OrphanedDiskInfo, error) {
+	var orphanedDisksInfo []OrphanedDiskInfo
+
+	log.Debugf("Reading Orphaned Disks...")
+	orphanedDisks, err := f.boshClient.OrphanDisks()
+	if err != nil {
+		return orphanedDisksInfo, fmt.Errorf("Error while reading Orphaned Disks: %v", err)
+	}
+
+	for _, orphanedDisk := range orphanedDisks {
+		orphanedDiskInfo := OrphanedDiskInfo{
+			CID:        orphanedDisk.CID(),
+			SizeMB:     orphanedDisk.Size(),
+			AZ:         orphanedDisk.AZName(),
+			OrphanedAt: orphanedDisk.OrphanedAt(),
+		}
+
+		if deployment := orphanedDisk.Deployment(); deployment != nil {
+			orphanedDiskInfo.Deployment = deployment.Name()
+		}
+
+		// The director reports the instance as `<instance group>/<instance id>`.
+		orphanedDiskInfo.InstanceGroup = strings.SplitN(orphanedDisk.InstanceName(), "/", 2)[0]
+
+		orphanedDisksInfo = append(orphanedDisksInfo, orphanedDiskInfo)
+	}
+
+	return orphanedDisksInfo, nil
+}
@@ -0,0 +1,104 @@
+package disks_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/disks"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		boshClient           *directorfakes.FakeDirector
+		orphanedDisksFetcher *Fetcher
+
+		orphanedAt = time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+	})
+
+	JustBeforeEach(func() {
+		orphanedDisksFetcher = NewFetcher(boshClient)
+	})
+
+	Describe("OrphanedDisks", func() {
+		var (
+			orphanedDisks []OrphanedDiskInfo
+			err           error
+		)
+
+		BeforeEach(func() {
+			boshClient.OrphanDisksReturns([]director.OrphanDisk{
+				&directorfakes.FakeOrphanDisk{
+					CIDStub:  func() string { return "fake-disk-cid" },
+					SizeStub: func() uint64 { return 1024 },
+					DeploymentStub: func() director.Deployment {
+						return &directorfakes.FakeDeployment{NameStub: func() string { return "fake-deployment-name" }}
+					},
+					InstanceNameStub: func() string { return "fake-job-name/fake-job-id" },
+					AZNameStub:       func() string { return "fake-az" },
+					OrphanedAtStub:   func() time.Time { return orphanedAt },
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			orphanedDisks, err = orphanedDisksFetcher.OrphanedDisks()
+		})
+
+		It("returns the orphaned disks", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(orphanedDisks).To(Equal([]OrphanedDiskInfo{
+				{
+					CID:           "fake-disk-cid",
+					SizeMB:        1024,
+					Deployment:    "fake-deployment-name",
+					InstanceGroup: "fake-job-name",
+					AZ:            "fake-az",
+					OrphanedAt:    orphanedAt,
+				},
+			}))
+		})
+
+		Context("when the instance of an orphaned disk is not known", func() {
+			BeforeEach(func() {
+				boshClient.OrphanDisksReturns([]director.OrphanDisk{
+					&directorfakes.FakeOrphanDisk{
+						CIDStub: func() string { return "fake-disk-cid" },
+					},
+				}, nil)
+			})
+
+			It("returns the orphaned disk without deployment and instance group", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(orphanedDisks).To(Equal([]OrphanedDiskInfo{
+					{CID: "fake-disk-cid"},
+				}))
+			})
+		})
+
+		Context("when reading the orphaned disks fails", func() {
+			BeforeEach(func() {
+				boshClient.OrphanDisksReturns([]director.OrphanDisk{}, errors.New("no orphaned disks"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading Orphaned Disks"))
+			})
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package disks_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDisks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Disks Suite")
+}
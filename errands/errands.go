@@ -0,0 +1,8 @@
+package errands
+
+type ErrandRunInfo struct {
+	TaskID     int    `json:"task_id"`
+	Deployment string `json:"deployment"`
+	Name       string `json:"name"`
+	ExitCode   int    `json:"exit_code"`
+}
@@ -0,0 +1,147 @@
+package errands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+)
+
+// errandTaskDescription matches the description the director gives to the
+// tasks it creates when running an errand.
+var errandTaskDescription = regexp.MustCompile(`^run errand (\S+) from deployment (\S+)$`)
+
+type Fetcher struct {
+	boshClient director.Director
+	limit      int
+
+	mu        sync.Mutex
+	exitCodes map[int]*int
+}
+
+func NewFetcher(boshClient director.Director, limit int) *Fetcher {
+	return &Fetcher{
+		boshClient: boshClient,
+		limit:      limit,
+		exitCodes:  make(map[int]*int),
+	}
+}
+
+// ErrandRuns returns the most recent finished run of every errand found in
+// the recent tasks. Exit codes are cached by task, as finished tasks never
+// change, until the task falls out of the recent tasks; runs whose result
+// holds no exit code are omitted. Results that could not be read are not
+// cached, so they are read again by the next call.
+func (f *Fetcher) ErrandRuns() ([]ErrandRunInfo, error) {
+	var errandRunsInfo []ErrandRunInfo
+
+	log.Debugf("Reading %d recent Tasks for Errand runs...", f.limit)
+	tasks, err := f.boshClient.RecentTasks(f.limit, director.TasksFilter{})
+	if err != nil {
+		return errandRunsInfo, fmt.Errorf("Error while reading recent Tasks: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	recent := make(map[int]bool)
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		recent[task.ID()] = true
+		if task.State() != "done" && task.State() != "error" {
+			continue
+		}
+
+		matches := errandTaskDescription.FindStringSubmatch(task.Description())
+		if matches == nil {
+			continue
+		}
+
+		name, deployment := matches[1], matches[2]
+		if seen[deployment+"/"+name] {
+			continue
+		}
+		seen[deployment+"/"+name] = true
+
+		exitCode, ok := f.exitCodes[task.ID()]
+		if !ok {
+			exitCode, err = f.fetchExitCode(task)
+			if err != nil {
+				return nil, err
+			}
+			f.exitCodes[task.ID()] = exitCode
+		}
+
+		if exitCode == nil {
+			continue
+		}
+
+		errandRunsInfo = append(errandRunsInfo, ErrandRunInfo{
+			TaskID:     task.ID(),
+			Deployment: deployment,
+			Name:       name,
+			ExitCode:   *exitCode,
+		})
+	}
+
+	for taskID := range f.exitCodes {
+		if !recent[taskID] {
+			delete(f.exitCodes, taskID)
+		}
+	}
+
+	return errandRunsInfo, nil
+}
+
+// fetchExitCode returns the highest exit code reported by the instances the
+// errand ran on, or nil when the task result holds none.
+func (f *Fetcher) fetchExitCode(task director.Task) (*int, error) {
+	reporter := &resultReporter{}
+
+	// The director reports an error for tasks that did not succeed once their
+	// result output is complete, so that error is only returned when the task
+	// succeeded or its final state could not be read.
+	if err := task.ResultOutput(reporter); err != nil {
+		if task.State() == "done" || reporter.state != task.State() {
+			return nil, fmt.Errorf("Error while reading Result of Task `%d`: %v", task.ID(), err)
+		}
+		log.Debugf("Task `%d` did not succeed: %v", task.ID(), err)
+	}
+
+	var exitCode *int
+	scanner := bufio.NewScanner(bytes.NewReader(reporter.output.Bytes()))
+	for scanner.Scan() {
+		var errandRun struct {
+			ExitCode *int `json:"exit_code"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &errandRun); err != nil || errandRun.ExitCode == nil {
+			continue
+		}
+
+		if exitCode == nil || *errandRun.ExitCode > *exitCode {
+			exitCode = errandRun.ExitCode
+		}
+	}
+
+	return exitCode, nil
+}
+
+type resultReporter struct {
+	output bytes.Buffer
+	state  string
+}
+
+func (r *resultReporter) TaskStarted(int) {}
+
+func (r *resultReporter) TaskFinished(_ int, state string) {
+	r.state = state
+}
+
+func (r *resultReporter) TaskOutputChunk(_ int, chunk []byte) {
+	r.output.Write(chunk)
+}
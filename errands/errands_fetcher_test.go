@@ -0,0 +1,157 @@
+package errands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/errands"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+func errandTask(id int, state string, description string, output string) *directorfakes.FakeTask {
+	return &directorfakes.FakeTask{
+		IDStub:          func() int { return id },
+		StateStub:       func() string { return state },
+		DescriptionStub: func() string { return description },
+		ResultOutputStub: func(reporter director.TaskReporter) error {
+			defer reporter.TaskFinished(id, state)
+			reporter.TaskOutputChunk(id, []byte(output))
+			if state != "done" {
+				return errors.New("task failed")
+			}
+			return nil
+		},
+	}
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		limit          int
+		boshClient     *directorfakes.FakeDirector
+		errandsFetcher *Fetcher
+
+		smokeTestsTask *directorfakes.FakeTask
+	)
+
+	BeforeEach(func() {
+		limit = 50
+		boshClient = &directorfakes.FakeDirector{}
+
+		smokeTestsTask = errandTask(5, "error", "run errand smoke-tests from deployment fake-deployment-name",
+			`{"instance":{"group":"smoke-tests","id":"1"},"exit_code":0}`+"\n"+
+				`{"instance":{"group":"smoke-tests","id":"2"},"exit_code":1}`+"\n")
+
+		boshClient.RecentTasksReturns([]director.Task{
+			errandTask(6, "processing", "run errand smoke-tests from deployment fake-deployment-name", ""),
+			smokeTestsTask,
+			errandTask(4, "done", "run errand smoke-tests from deployment fake-deployment-name",
+				`{"instance":{"group":"smoke-tests","id":"1"},"exit_code":0}`+"\n"),
+			errandTask(3, "done", "create deployment", ""),
+			errandTask(2, "done", "run errand acceptance-tests from deployment fake-deployment-name",
+				`{"instance":{"group":"acceptance-tests","id":"1"},"exit_code":0}`+"\n"),
+			errandTask(1, "done", "run errand broken from deployment fake-deployment-name", "not json\n"),
+		}, nil)
+	})
+
+	JustBeforeEach(func() {
+		errandsFetcher = NewFetcher(boshClient, limit)
+	})
+
+	Describe("ErrandRuns", func() {
+		var (
+			errandRuns []ErrandRunInfo
+			err        error
+		)
+
+		JustBeforeEach(func() {
+			errandRuns, err = errandsFetcher.ErrandRuns()
+		})
+
+		It("returns the last finished run of each errand", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(errandRuns).To(Equal([]ErrandRunInfo{
+				{
+					TaskID:     5,
+					Deployment: "fake-deployment-name",
+					Name:       "smoke-tests",
+					ExitCode:   1,
+				},
+				{
+					TaskID:     2,
+					Deployment: "fake-deployment-name",
+					Name:       "acceptance-tests",
+					ExitCode:   0,
+				},
+			}))
+		})
+
+		It("bounds the number of tasks read from the director", func() {
+			Expect(boshClient.RecentTasksCallCount()).To(Equal(1))
+			readLimit, _ := boshClient.RecentTasksArgsForCall(0)
+			Expect(readLimit).To(Equal(limit))
+		})
+
+		It("reads the result of a task only once", func() {
+			_, err = errandsFetcher.ErrandRuns()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(smokeTestsTask.ResultOutputCallCount()).To(Equal(1))
+		})
+
+		It("forgets the result of a task once it is no longer recent", func() {
+			boshClient.RecentTasksReturns([]director.Task{}, nil)
+			_, err = errandsFetcher.ErrandRuns()
+			Expect(err).ToNot(HaveOccurred())
+
+			boshClient.RecentTasksReturns([]director.Task{smokeTestsTask}, nil)
+			_, err = errandsFetcher.ErrandRuns()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(smokeTestsTask.ResultOutputCallCount()).To(Equal(2))
+		})
+
+		Context("when reading the result of a task fails", func() {
+			BeforeEach(func() {
+				smokeTestsTask.ResultOutputStub = func(director.TaskReporter) error {
+					return errors.New("no result")
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading Result of Task `5`"))
+			})
+
+			It("reads the result again on the next call", func() {
+				smokeTestsTask.ResultOutputStub = errandTask(5, "error", "", `{"exit_code":2}`+"\n").ResultOutputStub
+
+				errandRuns, err = errandsFetcher.ErrandRuns()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errandRuns).To(ContainElement(ErrandRunInfo{
+					TaskID:     5,
+					Deployment: "fake-deployment-name",
+					Name:       "smoke-tests",
+					ExitCode:   2,
+				}))
+			})
+		})
+
+		Context("when reading the recent tasks fails", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{}, errors.New("no tasks"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading recent Tasks"))
+			})
+		})
+	})
+})
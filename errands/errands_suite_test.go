@@ -0,0 +1,13 @@
+package errands_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestErrands(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Errands Suite")
+}
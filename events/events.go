@@ -0,0 +1,8 @@
+package events
+
+type EventInfo struct {
+	ID         int    `json:"id"`
+	Action     string `json:"action"`
+	ObjectType string `json:"object_type"`
+	Deployment string `json:"deployment"`
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+)
+
+type Fetcher struct {
+	boshClient  director.Director
+	lookback    time.Duration
+	lastEventID int
+	mu          *sync.Mutex
+}
+
+func NewFetcher(boshClient director.Director, lookback time.Duration) *Fetcher {
+	return &Fetcher{
+		boshClient: boshClient,
+		lookback:   lookback,
+		mu:         &sync.Mutex{},
+	}
+}
+
+// Events returns the events that occurred within the lookback window and
+// have not been returned by a previous call. The director returns a page of
+// the newest events at a time, so older pages are read with BeforeID until
+// reaching an event already returned or the start of the window. When a page
+// cannot be read, no events are returned so that they are all read again,
+// and counted once, by the next call.
+func (f *Fetcher) Events() ([]EventInfo, error) {
+	var eventsInfo []EventInfo
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	eventsFilter := director.EventsFilter{
+		After: strconv.FormatInt(time.Now().Add(-f.lookback).Unix(), 10),
+	}
+
+	log.Debugf("Reading Events after event `%d`...", f.lastEventID)
+	lastEventID := f.lastEventID
+	beforeEventID := 0
+	for {
+		events, err := f.boshClient.Events(eventsFilter)
+		if err != nil {
+			return []EventInfo{}, fmt.Errorf("Error while reading Events: %v", err)
+		}
+
+		oldestEventID := 0
+		for _, event := range events {
+			eventID, err := strconv.Atoi(event.ID())
+			if err != nil {
+				return []EventInfo{}, fmt.Errorf("Error while converting Event ID `%s`: %v", event.ID(), err)
+			}
+
+			// Events not older than the page asked for are left out, so a
+			// director ignoring BeforeID cannot make this loop forever.
+			if beforeEventID > 0 && eventID >= beforeEventID {
+				continue
+			}
+
+			if oldestEventID == 0 || eventID < oldestEventID {
+				oldestEventID = eventID
+			}
+
+			if eventID <= f.lastEventID {
+				continue
+			}
+
+			if eventID > lastEventID {
+				lastEventID = eventID
+			}
+
+			eventsInfo = append(eventsInfo, EventInfo{
+				ID:         eventID,
+				Action:     event.Action(),
+				ObjectType: event.ObjectType(),
+				Deployment: event.DeploymentName(),
+			})
+		}
+
+		if oldestEventID == 0 || oldestEventID <= f.lastEventID {
+			break
+		}
+		beforeEventID = oldestEventID
+		eventsFilter.BeforeID = strconv.Itoa(beforeEventID)
+	}
+	f.lastEventID = lastEventID
+
+	return eventsInfo, nil
+}
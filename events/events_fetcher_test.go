@@ -0,0 +1,246 @@
+package events_test
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/events"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+func fakeEvent(id string, action string, objectType string, deploymentName string) director.Event {
+	return &directorfakes.FakeEvent{
+		IDStub:             func() string { return id },
+		ActionStub:         func() string { return action },
+		ObjectTypeStub:     func() string { return objectType },
+		DeploymentNameStub: func() string { return deploymentName },
+	}
+}
+
+// directorEvents pages events, given newest first, the way the director does:
+// at most pageSize of them, older than the BeforeID of the filter.
+func directorEvents(pageSize *int, events *[]director.Event) func(director.EventsFilter) ([]director.Event, error) {
+	return func(eventsFilter director.EventsFilter) ([]director.Event, error) {
+		page := []director.Event{}
+		for _, event := range *events {
+			if eventsFilter.BeforeID != "" {
+				eventID, _ := strconv.Atoi(event.ID())
+				beforeID, _ := strconv.Atoi(eventsFilter.BeforeID)
+				if eventID >= beforeID {
+					continue
+				}
+			}
+			if len(page) == *pageSize {
+				break
+			}
+			page = append(page, event)
+		}
+		return page, nil
+	}
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		lookback      time.Duration
+		boshClient    *directorfakes.FakeDirector
+		eventsFetcher *Fetcher
+	)
+
+	BeforeEach(func() {
+		lookback = time.Hour
+		boshClient = &directorfakes.FakeDirector{}
+	})
+
+	JustBeforeEach(func() {
+		eventsFetcher = NewFetcher(boshClient, lookback)
+	})
+
+	Describe("Events", func() {
+		var (
+			pageSize   int
+			boshEvents []director.Event
+			events     []EventInfo
+			err        error
+		)
+
+		BeforeEach(func() {
+			pageSize = 200
+			boshEvents = []director.Event{
+				fakeEvent("12", "update", "deployment", "fake-deployment-name"),
+				fakeEvent("11", "recreate", "vm", "fake-deployment-name"),
+			}
+			boshClient.EventsStub = directorEvents(&pageSize, &boshEvents)
+		})
+
+		JustBeforeEach(func() {
+			events, err = eventsFetcher.Events()
+		})
+
+		It("returns the events", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events).To(Equal([]EventInfo{
+				{
+					ID:         12,
+					Action:     "update",
+					ObjectType: "deployment",
+					Deployment: "fake-deployment-name",
+				},
+				{
+					ID:         11,
+					Action:     "recreate",
+					ObjectType: "vm",
+					Deployment: "fake-deployment-name",
+				},
+			}))
+		})
+
+		It("only reads the events within the lookback window", func() {
+			Expect(boshClient.EventsCallCount()).To(BeNumerically(">", 0))
+			for i := 0; i < boshClient.EventsCallCount(); i++ {
+				eventsFilter := boshClient.EventsArgsForCall(i)
+				after, err := strconv.ParseInt(eventsFilter.After, 10, 64)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(time.Unix(after, 0)).To(BeTemporally("~", time.Now().Add(-lookback), time.Minute))
+			}
+		})
+
+		Context("when the events span several pages", func() {
+			BeforeEach(func() {
+				pageSize = 2
+				boshEvents = append([]director.Event{
+					fakeEvent("15", "create", "deployment", "fake-other-deployment-name"),
+					fakeEvent("14", "start", "instance", "fake-deployment-name"),
+					fakeEvent("13", "delete", "instance", "fake-deployment-name"),
+				}, boshEvents...)
+			})
+
+			It("returns the events of every page", func() {
+				Expect(err).ToNot(HaveOccurred())
+				eventIDs := []int{}
+				for _, event := range events {
+					eventIDs = append(eventIDs, event.ID)
+				}
+				Expect(eventIDs).To(Equal([]int{15, 14, 13, 12, 11}))
+			})
+
+			It("reads the older pages before the oldest event of the previous one", func() {
+				Expect(boshClient.EventsCallCount()).To(Equal(4))
+				Expect(boshClient.EventsArgsForCall(0).BeforeID).To(BeEmpty())
+				Expect(boshClient.EventsArgsForCall(1).BeforeID).To(Equal("14"))
+				Expect(boshClient.EventsArgsForCall(2).BeforeID).To(Equal("12"))
+				Expect(boshClient.EventsArgsForCall(3).BeforeID).To(Equal("11"))
+			})
+
+			It("stops paging at the events already returned", func() {
+				boshEvents = append([]director.Event{
+					fakeEvent("17", "stop", "instance", "fake-deployment-name"),
+					fakeEvent("16", "stop", "instance", "fake-deployment-name"),
+				}, boshEvents...)
+				callCount := boshClient.EventsCallCount()
+
+				events, err = eventsFetcher.Events()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(events).To(HaveLen(2))
+				Expect(boshClient.EventsCallCount()).To(Equal(callCount + 2))
+			})
+			Context("when reading a later page fails", func() {
+				BeforeEach(func() {
+					pages := directorEvents(&pageSize, &boshEvents)
+					boshClient.EventsStub = func(eventsFilter director.EventsFilter) ([]director.Event, error) {
+						if eventsFilter.BeforeID != "" {
+							return nil, errors.New("no events")
+						}
+						return pages(eventsFilter)
+					}
+				})
+
+				It("returns an error and no events", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Error while reading Events"))
+					Expect(events).To(BeEmpty())
+				})
+
+				It("returns the events of the first page once the next call succeeds", func() {
+					boshClient.EventsStub = directorEvents(&pageSize, &boshEvents)
+
+					events, err = eventsFetcher.Events()
+					Expect(err).ToNot(HaveOccurred())
+					eventIDs := []int{}
+					for _, event := range events {
+						eventIDs = append(eventIDs, event.ID)
+					}
+					Expect(eventIDs).To(Equal([]int{15, 14, 13, 12, 11}))
+				})
+			})
+		})
+
+		Context("when the director ignores the BeforeID of the filter", func() {
+			BeforeEach(func() {
+				boshClient.EventsStub = nil
+				boshClient.EventsReturns(boshEvents, nil)
+			})
+
+			It("returns every event once", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(events).To(HaveLen(2))
+				Expect(boshClient.EventsCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when events were already returned", func() {
+			It("only returns the new events", func() {
+				Expect(err).ToNot(HaveOccurred())
+
+				boshEvents = append([]director.Event{
+					fakeEvent("13", "delete", "instance", "fake-deployment-name"),
+				}, boshEvents...)
+				events, err = eventsFetcher.Events()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(events).To(Equal([]EventInfo{
+					{
+						ID:         13,
+						Action:     "delete",
+						ObjectType: "instance",
+						Deployment: "fake-deployment-name",
+					},
+				}))
+			})
+		})
+
+		Context("when an event ID is not a number", func() {
+			BeforeEach(func() {
+				boshEvents = []director.Event{
+					fakeEvent("fake-event-id", "update", "deployment", "fake-deployment-name"),
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while converting Event ID"))
+			})
+		})
+
+		Context("when reading the events fails", func() {
+			BeforeEach(func() {
+				boshClient.EventsStub = nil
+				boshClient.EventsReturns([]director.Event{}, errors.New("no events"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading Events"))
+			})
+		})
+	})
+})
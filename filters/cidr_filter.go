@@ -4,6 +4,12 @@ import (
 	"net"
 )
 
+const (
+	IPv4Family = "ipv4"
+	IPv6Family = "ipv6"
+	AllFamily  = "all"
+)
+
 type CidrFilter struct {
 	cidrFilters []*net.IPNet
 }
@@ -37,3 +43,42 @@ func (f *CidrFilter) Select(ips []string) (string, bool) {
 
 	return "", false
 }
+
+func (f *CidrFilter) SelectAll(ips []string) []string {
+	selected := []string{}
+	seen := make(map[string]bool)
+
+	for _, c := range f.cidrFilters {
+		for _, val := range ips {
+			ip := net.ParseIP(val)
+			if ip == nil || seen[val] {
+				continue
+			}
+			if c.Contains(ip) {
+				selected = append(selected, val)
+				seen[val] = true
+			}
+		}
+	}
+
+	return selected
+}
+
+// SelectFamily returns every matching IP for the `all` family. Otherwise it
+// returns the first matching IP of the given family, falling back to the
+// first matching IP of any family.
+func (f *CidrFilter) SelectFamily(ips []string, family string) []string {
+	selected := f.SelectAll(ips)
+	if family == AllFamily || len(selected) == 0 {
+		return selected
+	}
+
+	for _, val := range selected {
+		isIPv4 := net.ParseIP(val).To4() != nil
+		if isIPv4 == (family == IPv4Family) {
+			return []string{val}
+		}
+	}
+
+	return selected[:1]
+}
@@ -116,4 +116,65 @@ var _ = Describe("Cidr Filter", func() {
 			})
 		})
 	})
+
+	Describe("SelectAll", func() {
+		BeforeEach(func() {
+			cidrs = []string{"10.254.0.0/16", "0.0.0.0/0", "::/0"}
+		})
+
+		It("returns all matching ips once", func() {
+			Expect(cidrFilter.SelectAll([]string{"192.168.0.1", "10.254.12.57", "fd00::1"})).To(Equal([]string{"10.254.12.57", "192.168.0.1", "fd00::1"}))
+		})
+
+		Context("when selecting empty list", func() {
+			It("returns an empty list", func() {
+				Expect(cidrFilter.SelectAll([]string{})).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("SelectFamily", func() {
+		var (
+			ips []string
+		)
+
+		BeforeEach(func() {
+			cidrs = []string{"0.0.0.0/0", "::/0"}
+			ips = []string{"192.168.0.1", "fd00::1", "10.254.12.57"}
+		})
+
+		Context("when preferring ipv4", func() {
+			It("returns the first ipv4 ip", func() {
+				Expect(cidrFilter.SelectFamily(ips, IPv4Family)).To(Equal([]string{"192.168.0.1"}))
+			})
+		})
+
+		Context("when preferring ipv6", func() {
+			It("returns the first ipv6 ip", func() {
+				Expect(cidrFilter.SelectFamily(ips, IPv6Family)).To(Equal([]string{"fd00::1"}))
+			})
+
+			Context("when there are no ipv6 ips", func() {
+				BeforeEach(func() {
+					ips = []string{"192.168.0.1", "10.254.12.57"}
+				})
+
+				It("falls back to the first ip", func() {
+					Expect(cidrFilter.SelectFamily(ips, IPv6Family)).To(Equal([]string{"192.168.0.1"}))
+				})
+			})
+		})
+
+		Context("when selecting all families", func() {
+			It("returns all ips", func() {
+				Expect(cidrFilter.SelectFamily(ips, AllFamily)).To(Equal([]string{"192.168.0.1", "10.254.12.57", "fd00::1"}))
+			})
+		})
+
+		Context("when selecting empty list", func() {
+			It("returns an empty list", func() {
+				Expect(cidrFilter.SelectFamily([]string{}, IPv4Family)).To(BeEmpty())
+			})
+		})
+	})
 })
@@ -78,5 +78,20 @@ var _ = Describe("CollectorsFilter", func() {
 				Expect(collectorsFilter.Enabled(JobsCollector)).To(BeTrue())
 			})
 		})
+
+		Context("when a subset of collectors is enabled", func() {
+			BeforeEach(func() {
+				filters = []string{DeploymentsCollector, ServiceDiscoveryCollector}
+			})
+
+			It("returns true for the enabled collectors", func() {
+				Expect(collectorsFilter.Enabled(DeploymentsCollector)).To(BeTrue())
+				Expect(collectorsFilter.Enabled(ServiceDiscoveryCollector)).To(BeTrue())
+			})
+
+			It("returns false for the other collectors", func() {
+				Expect(collectorsFilter.Enabled(JobsCollector)).To(BeFalse())
+			})
+		})
 	})
 })
@@ -3,41 +3,132 @@ package filters
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-cli/director"
 	"github.com/prometheus/common/log"
 )
 
+const deploymentsRegexpPrefix = "~"
+
 type DeploymentsFilter struct {
 	filters    []string
+	reFilters  []*regexp.Regexp
+	excluded   map[string]bool
 	boshClient director.Director
 }
 
-func NewDeploymentsFilter(filters []string, boshClient director.Director) *DeploymentsFilter {
-	return &DeploymentsFilter{filters: filters, boshClient: boshClient}
+func NewDeploymentsFilter(filters []string, excludedFilters []string, boshClient director.Director) (*DeploymentsFilter, error) {
+	nameFilters := []string{}
+	reFilters := []*regexp.Regexp{}
+	excluded := make(map[string]bool)
+
+	for _, deploymentName := range excludedFilters {
+		excluded[strings.Trim(deploymentName, " ")] = true
+	}
+
+	for _, filter := range filters {
+		filter = strings.Trim(filter, " ")
+		if strings.HasPrefix(filter, deploymentsRegexpPrefix) {
+			re, err := regexp.Compile(strings.TrimPrefix(filter, deploymentsRegexpPrefix))
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Error while compiling deployment regexp `%s`: %v", filter, err))
+			}
+			reFilters = append(reFilters, re)
+			continue
+		}
+		nameFilters = append(nameFilters, filter)
+	}
+
+	return &DeploymentsFilter{filters: nameFilters, reFilters: reFilters, excluded: excluded, boshClient: boshClient}, nil
 }
 
-func (f *DeploymentsFilter) GetDeployments() ([]director.Deployment, error) {
+// GetDeployments returns the deployments matched by the filters, and how many
+// were filtered out. When there are only deployment names, the deployments of
+// the BOSH Director are not listed, so only the excluded names are counted.
+func (f *DeploymentsFilter) GetDeployments() ([]director.Deployment, int, error) {
 	var err error
 	var deployments []director.Deployment
 
+	if len(f.filters) == 0 && len(f.reFilters) == 0 {
+		log.Debugf("Reading deployments...")
+		deployments, err = f.boshClient.Deployments()
+		if err != nil {
+			return deployments, 0, errors.New(fmt.Sprintf("Error while reading deployments: %v", err))
+		}
+		filteredDeployments := f.withoutExcluded(deployments)
+		return filteredDeployments, len(deployments) - len(filteredDeployments), nil
+	}
+
+	filtered := 0
+	deploymentsFound := make(map[string]bool)
+
 	if len(f.filters) > 0 {
 		log.Debugf("Filtering deployments by `%v`...", f.filters)
 		for _, deploymentName := range f.filters {
-			deployment, err := f.boshClient.FindDeployment(strings.Trim(deploymentName, " "))
+			if f.excluded[deploymentName] {
+				filtered++
+				continue
+			}
+			deployment, err := f.boshClient.FindDeployment(deploymentName)
 			if err != nil {
-				return deployments, errors.New(fmt.Sprintf("Error while reading deployment `%s`: %v", deploymentName, err))
+				return deployments, 0, errors.New(fmt.Sprintf("Error while reading deployment `%s`: %v", deploymentName, err))
 			}
 			deployments = append(deployments, deployment)
+			deploymentsFound[deployment.Name()] = true
 		}
-	} else {
-		log.Debugf("Reading deployments...")
-		deployments, err = f.boshClient.Deployments()
+	}
+
+	if len(f.reFilters) > 0 {
+		log.Debugf("Filtering deployments by `%v`...", f.reFilters)
+		allDeployments, err := f.boshClient.Deployments()
 		if err != nil {
-			return deployments, errors.New(fmt.Sprintf("Error while reading deployments: %v", err))
+			return nil, 0, errors.New(fmt.Sprintf("Error while reading deployments: %v", err))
+		}
+
+		// Every listed deployment not matched is filtered out, so the
+		// excluded names counted above would be counted twice.
+		filtered = 0
+		for _, deployment := range allDeployments {
+			if deploymentsFound[deployment.Name()] {
+				continue
+			}
+			if f.excluded[deployment.Name()] || !f.matches(deployment.Name()) {
+				filtered++
+				continue
+			}
+			deployments = append(deployments, deployment)
+			deploymentsFound[deployment.Name()] = true
+		}
+	}
+
+	return deployments, filtered, nil
+}
+
+func (f *DeploymentsFilter) matches(deploymentName string) bool {
+	for _, re := range f.reFilters {
+		if re.MatchString(deploymentName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *DeploymentsFilter) withoutExcluded(deployments []director.Deployment) []director.Deployment {
+	if len(f.excluded) == 0 {
+		return deployments
+	}
+
+	log.Debugf("Excluding deployments...")
+	filteredDeployments := []director.Deployment{}
+	for _, deployment := range deployments {
+		if f.excluded[deployment.Name()] {
+			continue
 		}
+		filteredDeployments = append(filteredDeployments, deployment)
 	}
 
-	return deployments, nil
+	return filteredDeployments
 }
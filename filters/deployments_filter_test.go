@@ -21,6 +21,7 @@ var _ = Describe("DeploymentsFilter", func() {
 	var (
 		err               error
 		filters           []string
+		excludedFilters   []string
 		boshClient        *directorfakes.FakeDirector
 		deploymentsFilter *DeploymentsFilter
 	)
@@ -29,13 +30,16 @@ var _ = Describe("DeploymentsFilter", func() {
 		var (
 			deployment1    director.Deployment
 			deployment2    director.Deployment
+			deployment3    director.Deployment
 			allDeployments []director.Deployment
 
 			deployments []director.Deployment
+			filtered    int
 		)
 
 		BeforeEach(func() {
 			filters = []string{}
+			excludedFilters = []string{}
 			boshClient = &directorfakes.FakeDirector{}
 
 			deployment1 = &directorfakes.FakeDeployment{
@@ -44,12 +48,16 @@ var _ = Describe("DeploymentsFilter", func() {
 			deployment2 = &directorfakes.FakeDeployment{
 				NameStub: func() string { return "fake-deployment-name-2" },
 			}
+			deployment3 = &directorfakes.FakeDeployment{
+				NameStub: func() string { return "fake-other-deployment-name" },
+			}
 			allDeployments = []director.Deployment{}
 		})
 
 		JustBeforeEach(func() {
-			deploymentsFilter = NewDeploymentsFilter(filters, boshClient)
-			deployments, err = deploymentsFilter.GetDeployments()
+			deploymentsFilter, err = NewDeploymentsFilter(filters, excludedFilters, boshClient)
+			Expect(err).ToNot(HaveOccurred())
+			deployments, filtered, err = deploymentsFilter.GetDeployments()
 		})
 
 		Context("when there are no filters", func() {
@@ -62,6 +70,10 @@ var _ = Describe("DeploymentsFilter", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("does not filter out any deployment", func() {
+				Expect(filtered).To(Equal(0))
+			})
+
 			Context("and there are no deployments", func() {
 				BeforeEach(func() {
 					boshClient.DeploymentsReturns([]director.Deployment{}, nil)
@@ -122,5 +134,114 @@ var _ = Describe("DeploymentsFilter", func() {
 				})
 			})
 		})
+
+		Context("when there are regexp filters", func() {
+			BeforeEach(func() {
+				filters = []string{"~fake-deployment-name-.*"}
+				boshClient.DeploymentsReturns([]director.Deployment{deployment1, deployment2, deployment3}, nil)
+			})
+
+			It("returns the deployments matching the regexp", func() {
+				Expect(boshClient.FindDeploymentCallCount()).To(Equal(0))
+				Expect(deployments).To(Equal([]director.Deployment{deployment1, deployment2}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the number of deployments not matching the regexp", func() {
+				Expect(filtered).To(Equal(1))
+			})
+
+			Context("and it fails to get the deployments", func() {
+				BeforeEach(func() {
+					boshClient.DeploymentsReturns(nil, errors.New("no deployments"))
+				})
+
+				It("does not return any deployment", func() {
+					Expect(deployments).To(BeEmpty())
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when there are literal and regexp filters", func() {
+			BeforeEach(func() {
+				filters = []string{"fake-deployment-name-1", " ~-name-[12]$"}
+				boshClient.FindDeploymentReturns(deployment1, nil)
+				boshClient.DeploymentsReturns([]director.Deployment{deployment1, deployment2, deployment3}, nil)
+			})
+
+			It("returns each matching deployment once", func() {
+				Expect(boshClient.FindDeploymentArgsForCall(0)).To(Equal("fake-deployment-name-1"))
+				Expect(deployments).To(Equal([]director.Deployment{deployment1, deployment2}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the number of deployments not matching any filter", func() {
+				Expect(filtered).To(Equal(1))
+			})
+		})
+
+		Context("when there are excluded deployments", func() {
+			BeforeEach(func() {
+				excludedFilters = []string{" fake-deployment-name-2 "}
+				boshClient.DeploymentsReturns([]director.Deployment{deployment1, deployment2, deployment3}, nil)
+			})
+
+			It("returns all deployments except the excluded ones", func() {
+				Expect(deployments).To(Equal([]director.Deployment{deployment1, deployment3}))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the number of excluded deployments", func() {
+				Expect(filtered).To(Equal(1))
+			})
+
+			Context("and the excluded deployment is also a filter", func() {
+				BeforeEach(func() {
+					filters = []string{"fake-deployment-name-1", "fake-deployment-name-2"}
+					boshClient.FindDeploymentReturns(deployment1, nil)
+				})
+
+				It("does not return the excluded deployment", func() {
+					Expect(boshClient.FindDeploymentCallCount()).To(Equal(1))
+					Expect(boshClient.FindDeploymentArgsForCall(0)).To(Equal("fake-deployment-name-1"))
+					Expect(deployments).To(Equal([]director.Deployment{deployment1}))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns the number of excluded deployment names", func() {
+					Expect(filtered).To(Equal(1))
+				})
+			})
+
+			Context("and the excluded deployment matches a regexp filter", func() {
+				BeforeEach(func() {
+					filters = []string{"~fake-deployment-name-.*"}
+				})
+
+				It("does not return the excluded deployment", func() {
+					Expect(deployments).To(Equal([]director.Deployment{deployment1}))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns the number of excluded and not matching deployments", func() {
+					Expect(filtered).To(Equal(2))
+				})
+			})
+		})
+	})
+
+	Describe("NewDeploymentsFilter", func() {
+		Context("when a regexp filter is invalid", func() {
+			BeforeEach(func() {
+				filters = []string{"fake-deployment-name-1", "~["}
+			})
+
+			It("returns an error", func() {
+				deploymentsFilter, err = NewDeploymentsFilter(filters, excludedFilters, boshClient)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("~["))
+			})
+		})
 	})
 })
@@ -0,0 +1,52 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type deprecatedArtifact struct {
+	name    string
+	version string
+}
+
+type DeprecatedFilter struct {
+	deprecatedArtifacts []deprecatedArtifact
+}
+
+// NewDeprecatedFilter parses filters of the form `name/version`, where
+// version may contain `*` wildcards (e.g. `ubuntu-xenial/621.*`).
+func NewDeprecatedFilter(filters []string) (*DeprecatedFilter, error) {
+	deprecatedArtifacts := []deprecatedArtifact{}
+
+	for _, filter := range filters {
+		parts := strings.SplitN(strings.Trim(filter, " "), "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New(fmt.Sprintf("Deprecated filter `%s` is not in the form `name/version`", filter))
+		}
+
+		if _, err := path.Match(parts[1], ""); err != nil {
+			return nil, errors.New(fmt.Sprintf("Deprecated filter `%s` has an invalid version pattern: %v", filter, err))
+		}
+
+		deprecatedArtifacts = append(deprecatedArtifacts, deprecatedArtifact{name: parts[0], version: parts[1]})
+	}
+
+	return &DeprecatedFilter{deprecatedArtifacts: deprecatedArtifacts}, nil
+}
+
+func (f *DeprecatedFilter) Deprecated(name string, version string) bool {
+	for _, artifact := range f.deprecatedArtifacts {
+		if artifact.name != name {
+			continue
+		}
+
+		if matched, _ := path.Match(artifact.version, version); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+package filters_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/filters"
+)
+
+var _ = Describe("DeprecatedFilter", func() {
+	var (
+		err              error
+		filter           []string
+		deprecatedFilter *DeprecatedFilter
+	)
+
+	BeforeEach(func() {
+		filter = []string{"fake-stemcell-name/1.2.3", "fake-release-name/4.*"}
+	})
+
+	JustBeforeEach(func() {
+		deprecatedFilter, err = NewDeprecatedFilter(filter)
+	})
+
+	Describe("New", func() {
+		Context("when filters are valid", func() {
+			It("does not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when a filter has no version", func() {
+			BeforeEach(func() {
+				filter = []string{"fake-stemcell-name"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Deprecated filter `fake-stemcell-name` is not in the form `name/version`"))
+			})
+		})
+
+		Context("when a filter has an invalid version pattern", func() {
+			BeforeEach(func() {
+				filter = []string{"fake-stemcell-name/[1"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Deprecated filter `fake-stemcell-name/[1` has an invalid version pattern"))
+			})
+		})
+	})
+
+	Describe("Deprecated", func() {
+		Context("when name and version match", func() {
+			It("returns true", func() {
+				Expect(deprecatedFilter.Deprecated("fake-stemcell-name", "1.2.3")).To(BeTrue())
+			})
+		})
+
+		Context("when version matches a wildcard", func() {
+			It("returns true", func() {
+				Expect(deprecatedFilter.Deprecated("fake-release-name", "4.5.6")).To(BeTrue())
+			})
+		})
+
+		Context("when version does not match", func() {
+			It("returns false", func() {
+				Expect(deprecatedFilter.Deprecated("fake-stemcell-name", "1.2.4")).To(BeFalse())
+			})
+		})
+
+		Context("when name does not match", func() {
+			It("returns false", func() {
+				Expect(deprecatedFilter.Deprecated("fake-other-name", "1.2.3")).To(BeFalse())
+			})
+		})
+
+		Context("when a filter has leading and/or trailing whitespaces", func() {
+			BeforeEach(func() {
+				filter = []string{"   fake-stemcell-name/1.2.3  "}
+			})
+
+			It("returns true", func() {
+				Expect(deprecatedFilter.Deprecated("fake-stemcell-name", "1.2.3")).To(BeTrue())
+			})
+		})
+
+		Context("when there is no filter", func() {
+			BeforeEach(func() {
+				filter = []string{}
+			})
+
+			It("returns false", func() {
+				Expect(deprecatedFilter.Deprecated("fake-stemcell-name", "1.2.3")).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type InstanceGroupsFilter struct {
+	instanceGroupsEnabled  map[string]bool
+	reInstanceGroups       []*regexp.Regexp
+	instanceGroupsExcluded map[string]bool
+	reExcluded             []*regexp.Regexp
+}
+
+// NewInstanceGroupsFilter returns a filter of the instance groups (job names)
+// to report. Entries prefixed with `~` are matched as regexps, and the excluded
+// instance groups take precedence over the filters.
+func NewInstanceGroupsFilter(filters []string, excludedFilters []string) (*InstanceGroupsFilter, error) {
+	instanceGroupsEnabled, reInstanceGroups, err := parseInstanceGroups(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceGroupsExcluded, reExcluded, err := parseInstanceGroups(excludedFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstanceGroupsFilter{
+		instanceGroupsEnabled:  instanceGroupsEnabled,
+		reInstanceGroups:       reInstanceGroups,
+		instanceGroupsExcluded: instanceGroupsExcluded,
+		reExcluded:             reExcluded,
+	}, nil
+}
+
+func parseInstanceGroups(filters []string) (map[string]bool, []*regexp.Regexp, error) {
+	names := make(map[string]bool)
+	reFilters := []*regexp.Regexp{}
+
+	for _, filter := range filters {
+		filter = strings.Trim(filter, " ")
+		if strings.HasPrefix(filter, deploymentsRegexpPrefix) {
+			re, err := regexp.Compile(strings.TrimPrefix(filter, deploymentsRegexpPrefix))
+			if err != nil {
+				return nil, nil, errors.New(fmt.Sprintf("Error while compiling instance group regexp `%s`: %v", filter, err))
+			}
+			reFilters = append(reFilters, re)
+			continue
+		}
+		names[filter] = true
+	}
+
+	return names, reFilters, nil
+}
+
+func (f *InstanceGroupsFilter) Enabled(instanceGroup string) bool {
+	if matchesInstanceGroup(instanceGroup, f.instanceGroupsExcluded, f.reExcluded) {
+		return false
+	}
+
+	if len(f.instanceGroupsEnabled) == 0 && len(f.reInstanceGroups) == 0 {
+		return true
+	}
+
+	return matchesInstanceGroup(instanceGroup, f.instanceGroupsEnabled, f.reInstanceGroups)
+}
+
+func matchesInstanceGroup(instanceGroup string, names map[string]bool, reFilters []*regexp.Regexp) bool {
+	if names[instanceGroup] {
+		return true
+	}
+
+	for _, re := range reFilters {
+		if re.MatchString(instanceGroup) {
+			return true
+		}
+	}
+
+	return false
+}
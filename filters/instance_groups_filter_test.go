@@ -0,0 +1,130 @@
+package filters_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/filters"
+)
+
+var _ = Describe("InstanceGroupsFilter", func() {
+	var (
+		err                  error
+		filter               []string
+		excludedFilter       []string
+		instanceGroupsFilter *InstanceGroupsFilter
+	)
+
+	BeforeEach(func() {
+		filter = []string{"fake-job-name-1", "fake-job-name-3"}
+		excludedFilter = []string{}
+	})
+
+	JustBeforeEach(func() {
+		instanceGroupsFilter, err = NewInstanceGroupsFilter(filter, excludedFilter)
+	})
+
+	Describe("New", func() {
+		Context("when a regexp filter is invalid", func() {
+			BeforeEach(func() {
+				filter = []string{"~fake-job-name-["}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while compiling instance group regexp `~fake-job-name-[`"))
+			})
+		})
+
+		Context("when a regexp excluded filter is invalid", func() {
+			BeforeEach(func() {
+				excludedFilter = []string{"~fake-job-name-["}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Enabled", func() {
+		Context("when instance group is enabled", func() {
+			It("returns true", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-1")).To(BeTrue())
+			})
+		})
+
+		Context("when instance group is not enabled", func() {
+			It("returns false", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-2")).To(BeFalse())
+			})
+		})
+
+		Context("when there is no filter", func() {
+			BeforeEach(func() {
+				filter = []string{}
+			})
+
+			It("returns true", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-2")).To(BeTrue())
+			})
+		})
+
+		Context("when a filter has leading and/or trailing whitespaces", func() {
+			BeforeEach(func() {
+				filter = []string{"   fake-job-name-1  "}
+			})
+
+			It("returns true", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-1")).To(BeTrue())
+			})
+		})
+
+		Context("when there is a regexp filter", func() {
+			BeforeEach(func() {
+				filter = []string{"~^fake-job-name-[12]$"}
+			})
+
+			It("returns true for the matching instance groups", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-1")).To(BeTrue())
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-2")).To(BeTrue())
+			})
+
+			It("returns false for the other instance groups", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-3")).To(BeFalse())
+			})
+		})
+
+		Context("when there are excluded instance groups", func() {
+			BeforeEach(func() {
+				filter = []string{}
+				excludedFilter = []string{"fake-job-name-1", "~^fake-job-name-[34]$"}
+			})
+
+			It("returns false for the excluded instance groups", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-1")).To(BeFalse())
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-3")).To(BeFalse())
+			})
+
+			It("returns true for the other instance groups", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-2")).To(BeTrue())
+			})
+		})
+
+		Context("when an instance group is both filtered and excluded", func() {
+			BeforeEach(func() {
+				filter = []string{"~^fake-job-name-.*"}
+				excludedFilter = []string{"fake-job-name-3"}
+			})
+
+			It("returns false", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-3")).To(BeFalse())
+			})
+
+			It("returns true for the other filtered instance groups", func() {
+				Expect(instanceGroupsFilter.Enabled("fake-job-name-1")).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AZLabel            = "az"
+	DeploymentLabel    = "deployment"
+	InstanceGroupLabel = "instance_group"
+	IndexLabel         = "index"
+	IDLabel            = "id"
+)
+
+type LabelsFilter struct {
+	labelsEnabled map[string]bool
+}
+
+func NewLabelsFilter(filters []string) (*LabelsFilter, error) {
+	labelsEnabled := make(map[string]bool)
+
+	for _, labelName := range filters {
+		switch strings.Trim(labelName, " ") {
+		case AZLabel:
+			labelsEnabled[AZLabel] = true
+		case DeploymentLabel:
+			labelsEnabled[DeploymentLabel] = true
+		case InstanceGroupLabel:
+			labelsEnabled[InstanceGroupLabel] = true
+		case IndexLabel:
+			labelsEnabled[IndexLabel] = true
+		case IDLabel:
+			labelsEnabled[IDLabel] = true
+		default:
+			return &LabelsFilter{}, errors.New(fmt.Sprintf("Label filter `%s` is not supported", labelName))
+		}
+	}
+
+	return &LabelsFilter{labelsEnabled: labelsEnabled}, nil
+}
+
+func (f *LabelsFilter) Enabled(labelName string) bool {
+	return f.labelsEnabled[labelName]
+}
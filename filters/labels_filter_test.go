@@ -0,0 +1,82 @@
+package filters_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/filters"
+)
+
+var _ = Describe("LabelsFilter", func() {
+	var (
+		err     error
+		filters []string
+
+		labelsFilter *LabelsFilter
+	)
+
+	JustBeforeEach(func() {
+		labelsFilter, err = NewLabelsFilter(filters)
+	})
+
+	Describe("New", func() {
+		Context("when filters are supported", func() {
+			BeforeEach(func() {
+				filters = []string{AZLabel, DeploymentLabel, InstanceGroupLabel, IndexLabel, IDLabel}
+			})
+
+			It("does not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when filters are not supported", func() {
+			BeforeEach(func() {
+				filters = []string{AZLabel, "unknown"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Label filter `unknown` is not supported"))
+			})
+		})
+
+		Context("when a filter has leading and/or trailing whitespaces", func() {
+			BeforeEach(func() {
+				filters = []string{"   " + AZLabel + "  "}
+			})
+
+			It("does not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Enabled", func() {
+		BeforeEach(func() {
+			filters = []string{AZLabel}
+		})
+
+		Context("when label is enabled", func() {
+			It("returns true", func() {
+				Expect(labelsFilter.Enabled(AZLabel)).To(BeTrue())
+			})
+		})
+
+		Context("when label is not enabled", func() {
+			It("returns false", func() {
+				Expect(labelsFilter.Enabled(IDLabel)).To(BeFalse())
+			})
+		})
+
+		Context("when there are no filters", func() {
+			BeforeEach(func() {
+				filters = []string{}
+			})
+
+			It("returns false", func() {
+				Expect(labelsFilter.Enabled(AZLabel)).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type MetricsFilter struct {
+	includePatterns []string
+	excludePatterns []string
+}
+
+// NewMetricsFilter parses glob patterns (e.g. `job_cpu_*`) matched against
+// metric names without the namespace.
+func NewMetricsFilter(includeFilters []string, excludeFilters []string) (*MetricsFilter, error) {
+	includePatterns, err := metricsPatterns(includeFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	excludePatterns, err := metricsPatterns(excludeFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricsFilter{includePatterns: includePatterns, excludePatterns: excludePatterns}, nil
+}
+
+func metricsPatterns(filters []string) ([]string, error) {
+	patterns := []string{}
+
+	for _, filter := range filters {
+		pattern := strings.Trim(filter, " ")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.New(fmt.Sprintf("Metrics filter `%s` is not a valid pattern: %v", filter, err))
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// Enabled reports whether a metric is matched by no exclude pattern and, if
+// include patterns are set, by at least one of them.
+func (f *MetricsFilter) Enabled(metricName string) bool {
+	if matchesAny(f.excludePatterns, metricName) {
+		return false
+	}
+
+	if len(f.includePatterns) == 0 {
+		return true
+	}
+
+	return matchesAny(f.includePatterns, metricName)
+}
+
+func matchesAny(patterns []string, metricName string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, metricName); matched {
+			return true
+		}
+	}
+
+	return false
+}
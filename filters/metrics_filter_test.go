@@ -0,0 +1,99 @@
+package filters_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/bosh-prometheus/bosh_exporter/filters"
+)
+
+var _ = Describe("MetricsFilter", func() {
+	var (
+		err           error
+		includeFilter []string
+		excludeFilter []string
+		metricsFilter *MetricsFilter
+	)
+
+	BeforeEach(func() {
+		includeFilter = []string{}
+		excludeFilter = []string{}
+	})
+
+	JustBeforeEach(func() {
+		metricsFilter, err = NewMetricsFilter(includeFilter, excludeFilter)
+	})
+
+	Describe("New", func() {
+		Context("when filters are valid", func() {
+			BeforeEach(func() {
+				includeFilter = []string{"job_cpu_*"}
+				excludeFilter = []string{"job_cpu_wait"}
+			})
+
+			It("does not return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when an include filter is not a valid pattern", func() {
+			BeforeEach(func() {
+				includeFilter = []string{"job_[cpu"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Metrics filter `job_[cpu` is not a valid pattern"))
+			})
+		})
+
+		Context("when an exclude filter is not a valid pattern", func() {
+			BeforeEach(func() {
+				excludeFilter = []string{"job_[cpu"}
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Metrics filter `job_[cpu` is not a valid pattern"))
+			})
+		})
+	})
+
+	Describe("Enabled", func() {
+		Context("when there are no filters", func() {
+			It("returns true", func() {
+				Expect(metricsFilter.Enabled("job_cpu_sys")).To(BeTrue())
+			})
+		})
+
+		Context("when there are include filters", func() {
+			BeforeEach(func() {
+				includeFilter = []string{"job_cpu_*", "job_*_disk_percent"}
+			})
+
+			It("returns true for matching metrics", func() {
+				Expect(metricsFilter.Enabled("job_cpu_sys")).To(BeTrue())
+				Expect(metricsFilter.Enabled("job_system_disk_percent")).To(BeTrue())
+			})
+
+			It("returns false for other metrics", func() {
+				Expect(metricsFilter.Enabled("job_mem_kb")).To(BeFalse())
+			})
+		})
+
+		Context("when there are exclude filters", func() {
+			BeforeEach(func() {
+				includeFilter = []string{"job_cpu_*"}
+				excludeFilter = []string{"job_cpu_wait"}
+			})
+
+			It("returns false for excluded metrics even if included", func() {
+				Expect(metricsFilter.Enabled("job_cpu_wait")).To(BeFalse())
+			})
+
+			It("returns true for included metrics", func() {
+				Expect(metricsFilter.Enabled("job_cpu_sys")).To(BeTrue())
+			})
+		})
+	})
+})
@@ -36,3 +36,15 @@ func (f *RegexpFilter) Enabled(expr string) bool {
 
 	return false
 }
+
+// Matches reports whether expr matches any of the filters. Unlike Enabled, it
+// returns false when there are no filters, so it suits exclusion lists.
+func (f *RegexpFilter) Matches(expr string) bool {
+	for _, re := range f.reFilters {
+		if re.MatchString(expr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -69,4 +69,32 @@ var _ = Describe("RegexpFilter", func() {
 			})
 		})
 	})
+
+	Describe("Matches", func() {
+		BeforeEach(func() {
+			filters = []string{"bosh_exporter", "[a-z]+_collector"}
+		})
+
+		Context("when there is a match", func() {
+			It("returns true", func() {
+				Expect(regexpFilter.Matches("deployments_collector")).To(BeTrue())
+			})
+		})
+
+		Context("when there is not a match", func() {
+			It("returns false", func() {
+				Expect(regexpFilter.Matches("deployments_exporter")).To(BeFalse())
+			})
+		})
+
+		Context("when there are no filters", func() {
+			BeforeEach(func() {
+				filters = []string{}
+			})
+
+			It("returns false", func() {
+				Expect(regexpFilter.Matches("deployments_exporter")).To(BeFalse())
+			})
+		})
+	})
 })
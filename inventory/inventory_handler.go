@@ -0,0 +1,61 @@
+package inventory
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type Deployment struct {
+	Director         string `json:"director,omitempty"`
+	Name             string `json:"name"`
+	Instances        int    `json:"instances"`
+	HealthyInstances int    `json:"healthy_instances"`
+}
+
+type Handler struct {
+	deploymentsSources map[string]*deployments.LastSource
+}
+
+// NewHandler returns an http.Handler listing, as JSON, the deployments of the
+// last scrape of every source, keyed by director name, with their instance
+// counts. It never reaches the BOSH Director itself.
+func NewHandler(deploymentsSources map[string]*deployments.LastSource) *Handler {
+	return &Handler{
+		deploymentsSources: deploymentsSources,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.deploymentsSources))
+	for name := range h.deploymentsSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deploymentsList := []Deployment{}
+	for _, name := range names {
+		for _, deploymentInfo := range h.deploymentsSources[name].Last() {
+			deployment := Deployment{
+				Director:  name,
+				Name:      deploymentInfo.Name,
+				Instances: len(deploymentInfo.Instances),
+			}
+			for _, instance := range deploymentInfo.Instances {
+				if instance.Healthy {
+					deployment.HealthyInstances++
+				}
+			}
+			deploymentsList = append(deploymentsList, deployment)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deploymentsList); err != nil {
+		log.Errorf("Error encoding deployments: %v", err)
+	}
+}
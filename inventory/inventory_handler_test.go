@@ -0,0 +1,112 @@
+package inventory_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+
+	. "github.com/bosh-prometheus/bosh_exporter/inventory"
+)
+
+type fakeDeploymentsSource struct {
+	deployments []deployments.DeploymentInfo
+}
+
+func (s *fakeDeploymentsSource) Deployments() ([]deployments.DeploymentInfo, error) {
+	return s.deployments, nil
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		deploymentsSources map[string]*deployments.LastSource
+		scrape             bool
+		recorder           *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		scrape = true
+		deploymentsSources = map[string]*deployments.LastSource{
+			"": deployments.NewLastSource(&fakeDeploymentsSource{
+				deployments: []deployments.DeploymentInfo{
+					{
+						Name: "fake-deployment-name",
+						Instances: []deployments.Instance{
+							{Name: "fake-job-name", Healthy: true},
+							{Name: "fake-job-name", Healthy: false},
+							{Name: "fake-other-job-name", Healthy: true},
+						},
+					},
+					{
+						Name: "fake-empty-deployment-name",
+					},
+				},
+			}),
+		}
+	})
+
+	JustBeforeEach(func() {
+		if scrape {
+			for _, deploymentsSource := range deploymentsSources {
+				_, err := deploymentsSource.Deployments()
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+
+		recorder = httptest.NewRecorder()
+		NewHandler(deploymentsSources).ServeHTTP(recorder, httptest.NewRequest("GET", "/deployments", nil))
+	})
+
+	It("returns a 200 status", func() {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	It("returns the scraped deployments with their instance counts", func() {
+		var deploymentsList []Deployment
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &deploymentsList)).To(Succeed())
+		Expect(deploymentsList).To(Equal([]Deployment{
+			{Name: "fake-deployment-name", Instances: 3, HealthyInstances: 2},
+			{Name: "fake-empty-deployment-name", Instances: 0, HealthyInstances: 0},
+		}))
+	})
+
+	It("omits the director name", func() {
+		Expect(recorder.Body.String()).ToNot(ContainSubstring(`"director"`))
+	})
+
+	Context("when no scrape happened yet", func() {
+		BeforeEach(func() {
+			scrape = false
+		})
+
+		It("returns an empty list", func() {
+			Expect(recorder.Body.String()).To(Equal("[]\n"))
+		})
+	})
+
+	Context("when there are several directors", func() {
+		BeforeEach(func() {
+			deploymentsSources = map[string]*deployments.LastSource{
+				"second-director": deployments.NewLastSource(&fakeDeploymentsSource{
+					deployments: []deployments.DeploymentInfo{{Name: "fake-other-deployment-name"}},
+				}),
+				"first-director": deploymentsSources[""],
+			}
+		})
+
+		It("returns the deployments of every director, sorted by director name", func() {
+			var deploymentsList []Deployment
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &deploymentsList)).To(Succeed())
+			Expect(deploymentsList).To(Equal([]Deployment{
+				{Director: "first-director", Name: "fake-deployment-name", Instances: 3, HealthyInstances: 2},
+				{Director: "first-director", Name: "fake-empty-deployment-name", Instances: 0, HealthyInstances: 0},
+				{Director: "second-director", Name: "fake-other-deployment-name", Instances: 0, HealthyInstances: 0},
+			}))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+)
+
+type Handler struct {
+	deploymentsFetcher *deployments.Fetcher
+	circuitBreaker     *deployments.CircuitBreaker
+	newCollector       func(deployments.DeploymentsSource) prometheus.Collector
+}
+
+// NewHandler returns an http.Handler scraping the single deployment named by
+// the deployment query parameter. Every request registers a collector built
+// by newCollector in its own registry, so only that deployment's metrics are
+// returned. The BOSH Director is not called while circuitBreaker, if not nil,
+// skips the scrapes of the deployments.
+func NewHandler(
+	deploymentsFetcher *deployments.Fetcher,
+	circuitBreaker *deployments.CircuitBreaker,
+	newCollector func(deployments.DeploymentsSource) prometheus.Collector,
+) *Handler {
+	return &Handler{
+		deploymentsFetcher: deploymentsFetcher,
+		circuitBreaker:     circuitBreaker,
+		newCollector:       newCollector,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	deploymentName := r.URL.Query().Get("deployment")
+	if deploymentName == "" {
+		http.Error(w, "Missing deployment parameter", http.StatusBadRequest)
+		return
+	}
+
+	if h.circuitBreaker != nil && !h.circuitBreaker.Closed() {
+		http.Error(w, deployments.ErrCircuitOpen.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	deploymentInfo, err := h.deploymentsFetcher.Deployment(deploymentName)
+	if errors.Is(err, deployments.ErrDeploymentNotFound) {
+		http.Error(w, fmt.Sprintf("Deployment `%s` not found", deploymentName), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), errorStatusCode(err))
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(h.newCollector(deploymentSource{deploymentInfo: *deploymentInfo}))
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// errorStatusCode maps a failed director call to the status returned by the
+// probe. Authorization failures concern the exporter credentials rather than
+// the caller, so they are reported as a bad gateway.
+func errorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, deployments.ErrUnauthorized):
+		return http.StatusBadGateway
+	case errors.Is(err, deployments.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, deployments.ErrDirectorTimeout):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+type deploymentSource struct {
+	deploymentInfo deployments.DeploymentInfo
+}
+
+func (s deploymentSource) Deployments() ([]deployments.DeploymentInfo, error) {
+	return []deployments.DeploymentInfo{s.deploymentInfo}, nil
+}
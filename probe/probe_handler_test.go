@@ -0,0 +1,195 @@
+package probe_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+
+	"github.com/bosh-prometheus/bosh_exporter/collectors"
+	"github.com/bosh-prometheus/bosh_exporter/deployments"
+	"github.com/bosh-prometheus/bosh_exporter/filters"
+
+	. "github.com/bosh-prometheus/bosh_exporter/probe"
+)
+
+func init() {
+	_ = log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		boshClient         *directorfakes.FakeDirector
+		deploymentsFetcher *deployments.Fetcher
+		circuitBreaker     *deployments.CircuitBreaker
+		handler            *Handler
+		recorder           *httptest.ResponseRecorder
+		target             string
+	)
+
+	BeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+		boshClient.DeploymentsReturns([]director.Deployment{
+			&directorfakes.FakeDeployment{
+				NameStub: func() string { return "fake-deployment-name" },
+			},
+			&directorfakes.FakeDeployment{
+				NameStub: func() string { return "other-deployment-name" },
+			},
+		}, nil)
+		circuitBreaker = nil
+		target = "/probe?deployment=fake-deployment-name"
+	})
+
+	JustBeforeEach(func() {
+		deploymentsFilter, err := filters.NewDeploymentsFilter([]string{}, []string{}, boshClient)
+		Expect(err).ToNot(HaveOccurred())
+		instanceGroupsFilter, err := filters.NewInstanceGroupsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		deploymentsFetcher = deployments.NewFetcher(boshClient, deployments.FetcherOptions{
+			DeploymentsFilter:    *deploymentsFilter,
+			InstanceGroupsFilter: *instanceGroupsFilter,
+			AZsFilter:            *filters.NewAZsFilter([]string{}),
+			RetryAttempts:        1,
+		})
+
+		collectorsFilter, err := filters.NewCollectorsFilter([]string{filters.DeploymentsCollector})
+		Expect(err).ToNot(HaveOccurred())
+		cidrsFilter, err := filters.NewCidrFilter([]string{})
+		Expect(err).ToNot(HaveOccurred())
+		processesFilter, err := filters.NewRegexpFilter([]string{})
+		Expect(err).ToNot(HaveOccurred())
+		deprecatedFilter, err := filters.NewDeprecatedFilter([]string{})
+		Expect(err).ToNot(HaveOccurred())
+		metricsFilter, err := filters.NewMetricsFilter([]string{}, []string{})
+		Expect(err).ToNot(HaveOccurred())
+		labelsFilter, err := filters.NewLabelsFilter([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		handler = NewHandler(deploymentsFetcher, circuitBreaker, func(deploymentsSource deployments.DeploymentsSource) prometheus.Collector {
+			return collectors.NewBoshCollector(
+				"test_exporter",
+				"test_environment",
+				"test_bosh_name",
+				"test_bosh_uuid",
+				"",
+				collectors.ServiceDiscoveryGroupByProcess,
+				labelsFilter,
+				deploymentsSource,
+				collectorsFilter,
+				filters.NewAZsFilter([]string{}),
+				processesFilter,
+				cidrsFilter,
+				filters.IPv4Family,
+				false,
+				false,
+				false,
+				false,
+				deprecatedFilter,
+				deprecatedFilter,
+				nil,
+				metricsFilter,
+				nil,
+			)
+		})
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+	})
+
+	It("returns a 200 status", func() {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("returns the metrics of the deployment", func() {
+		Expect(recorder.Body.String()).To(ContainSubstring(`bosh_deployment="fake-deployment-name"`))
+	})
+
+	It("does not return the metrics of other deployments", func() {
+		Expect(recorder.Body.String()).ToNot(ContainSubstring(`bosh_deployment="other-deployment-name"`))
+	})
+
+	Context("when the deployment parameter is missing", func() {
+		BeforeEach(func() {
+			target = "/probe"
+		})
+
+		It("returns a 400 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the deployment does not exist", func() {
+		BeforeEach(func() {
+			target = "/probe?deployment=unknown-deployment-name"
+		})
+
+		It("returns a 404 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Deployment `unknown-deployment-name` not found"))
+		})
+	})
+
+	Context("when the deployment cannot be read", func() {
+		BeforeEach(func() {
+			boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("no deployments"))
+		})
+
+		It("returns a 500 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("when the director rejects the credentials", func() {
+		BeforeEach(func() {
+			boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("Director responded with non-successful status code '401' response 'Unauthorized'"))
+		})
+
+		It("returns a 502 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusBadGateway))
+		})
+	})
+
+	Context("when the director rate limits the requests", func() {
+		BeforeEach(func() {
+			boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("Director responded with non-successful status code '429' response 'Too Many Requests'"))
+		})
+
+		It("returns a 429 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("when the director request times out", func() {
+		BeforeEach(func() {
+			boshClient.DeploymentsReturns([]director.Deployment{}, errors.New("Director responded with non-successful status code '504' response 'Gateway Timeout'"))
+		})
+
+		It("returns a 504 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		})
+	})
+
+	Context("when the circuit breaker is open", func() {
+		BeforeEach(func() {
+			// Reading a missing deployments file fails without any deployment.
+			circuitBreaker = deployments.NewCircuitBreaker(deployments.NewFileFetcher("/nonexistent/deployments.json"), 1, time.Hour)
+			circuitBreaker.Deployments()
+		})
+
+		It("returns a 503 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("does not call the director", func() {
+			Expect(boshClient.DeploymentsCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package readiness
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+)
+
+type Status struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type Handler struct {
+	boshClients map[string]director.Director
+	cacheTTL    time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	checkErr  error
+}
+
+// NewHandler returns an http.Handler reporting whether the BOSH Director is
+// reachable and accepts the configured credentials. The result of the
+// director Info call is cached for cacheTTL so probes do not hit the director
+// on every request. A nil boshClient (deployments read from a file) is always
+// ready.
+func NewHandler(boshClient director.Director, cacheTTL time.Duration) *Handler {
+	boshClients := map[string]director.Director{}
+	if boshClient != nil {
+		boshClients[""] = boshClient
+	}
+
+	return NewDirectorsHandler(boshClients, cacheTTL)
+}
+
+// NewDirectorsHandler returns an http.Handler reporting ready only when every
+// BOSH Director, keyed by name, is reachable and authenticated. The reason of
+// a failure is prefixed with the name of the first failing director.
+func NewDirectorsHandler(boshClients map[string]director.Director, cacheTTL time.Duration) *Handler {
+	return &Handler{
+		boshClients: boshClients,
+		cacheTTL:    cacheTTL,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := Status{Status: "ok"}
+	code := http.StatusOK
+
+	if err := h.check(); err != nil {
+		status = Status{Status: "unavailable", Reason: err.Error()}
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Errorf("Error encoding readiness status: %v", err)
+	}
+}
+
+func (h *Handler) check() error {
+	if len(h.boshClients) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.checkedAt.IsZero() && now.Sub(h.checkedAt) < h.cacheTTL {
+		return h.checkErr
+	}
+
+	h.checkErr = h.checkDirectors()
+	h.checkedAt = now
+
+	return h.checkErr
+}
+
+func (h *Handler) checkDirectors() error {
+	names := make([]string, 0, len(h.boshClients))
+	for name := range h.boshClients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := checkDirector(h.boshClients[name]); err != nil {
+			if name != "" {
+				return fmt.Errorf("Director `%s`: %v", name, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkDirector(boshClient director.Director) error {
+	info, err := boshClient.Info()
+	if err != nil {
+		return fmt.Errorf("Error reading BOSH Info: %v", err)
+	}
+
+	if info.User == "" {
+		return errors.New("Not authenticated to the BOSH Director")
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package readiness_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/readiness"
+)
+
+func init() {
+	_ = log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		boshClient *directorfakes.FakeDirector
+		cacheTTL   time.Duration
+		handler    *Handler
+		recorder   *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+		boshClient.InfoReturns(director.Info{Name: "fake-bosh-name", User: "fake-user"}, nil)
+		cacheTTL = time.Hour
+	})
+
+	JustBeforeEach(func() {
+		handler = NewHandler(boshClient, cacheTTL)
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+	})
+
+	Context("when the director is reachable and authenticated", func() {
+		It("returns a 200 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status":"ok"}`))
+		})
+	})
+
+	Context("when the director is not reachable", func() {
+		BeforeEach(func() {
+			boshClient.InfoReturns(director.Info{}, errors.New("connection refused"))
+		})
+
+		It("returns a 503 status with the reason", func() {
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status":"unavailable","reason":"Error reading BOSH Info: connection refused"}`))
+		})
+	})
+
+	Context("when the director does not accept the credentials", func() {
+		BeforeEach(func() {
+			boshClient.InfoReturns(director.Info{Name: "fake-bosh-name"}, nil)
+		})
+
+		It("returns a 503 status with the reason", func() {
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status":"unavailable","reason":"Not authenticated to the BOSH Director"}`))
+		})
+	})
+
+	Context("when the check result is cached", func() {
+		It("does not call the director again", func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ready", nil))
+			Expect(boshClient.InfoCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the check result has expired", func() {
+		BeforeEach(func() {
+			cacheTTL = 0
+		})
+
+		It("calls the director again", func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ready", nil))
+			Expect(boshClient.InfoCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when there is no director", func() {
+		JustBeforeEach(func() {
+			handler = NewHandler(nil, cacheTTL)
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+		})
+
+		It("returns a 200 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status":"ok"}`))
+		})
+	})
+
+	Context("when there are several directors", func() {
+		var otherBoshClient *directorfakes.FakeDirector
+
+		BeforeEach(func() {
+			otherBoshClient = &directorfakes.FakeDirector{}
+			otherBoshClient.InfoReturns(director.Info{Name: "other-bosh-name", User: "fake-user"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			handler = NewDirectorsHandler(map[string]director.Director{
+				"fake-director":  boshClient,
+				"other-director": otherBoshClient,
+			}, cacheTTL)
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+		})
+
+		It("returns a 200 status", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"status":"ok"}`))
+		})
+
+		Context("when one of the directors is not reachable", func() {
+			BeforeEach(func() {
+				otherBoshClient.InfoReturns(director.Info{}, errors.New("connection refused"))
+			})
+
+			It("returns a 503 status with the name of the director", func() {
+				Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"status":"unavailable","reason":"Director ` + "`other-director`" + `: Error reading BOSH Info: connection refused"}`))
+			})
+		})
+	})
+})
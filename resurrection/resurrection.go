@@ -0,0 +1,24 @@
+package resurrection
+
+const ConfigType = "resurrection"
+
+// Config is the content of a BOSH resurrection config.
+type Config struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Enabled bool    `yaml:"enabled"`
+	Include *Filter `yaml:"include"`
+	Exclude *Filter `yaml:"exclude"`
+}
+
+type Filter struct {
+	Deployments    []string `yaml:"deployments"`
+	InstanceGroups []string `yaml:"instance_groups"`
+}
+
+// Global reports whether the rule applies to every instance of the director.
+func (r Rule) Global() bool {
+	return r.Include == nil && r.Exclude == nil
+}
@@ -0,0 +1,60 @@
+package resurrection
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+	"gopkg.in/yaml.v2"
+)
+
+type Fetcher struct {
+	boshClient director.Director
+	cacheTTL   time.Duration
+	mutex      sync.Mutex
+	enabled    bool
+	expiresAt  time.Time
+}
+
+func NewFetcher(boshClient director.Director, cacheTTL time.Duration) *Fetcher {
+	return &Fetcher{boshClient: boshClient, cacheTTL: cacheTTL}
+}
+
+// Enabled reports whether resurrection is enabled director-wide, that is,
+// whether no resurrection config holds a rule disabling it for every
+// instance. The result is cached for the configured TTL.
+func (f *Fetcher) Enabled() (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if time.Now().Before(f.expiresAt) {
+		return f.enabled, nil
+	}
+
+	log.Debugf("Reading latest %s Configs...", ConfigType)
+	configs, err := f.boshClient.ListConfigs(1, director.ConfigsFilter{Type: ConfigType})
+	if err != nil {
+		return false, fmt.Errorf("Error while reading %s Configs: %v", ConfigType, err)
+	}
+
+	enabled := true
+	for _, config := range configs {
+		var resurrectionConfig Config
+		if err := yaml.Unmarshal([]byte(config.Content), &resurrectionConfig); err != nil {
+			return false, fmt.Errorf("Error while parsing %s Config `%s`: %v", ConfigType, config.Name, err)
+		}
+
+		for _, rule := range resurrectionConfig.Rules {
+			if rule.Global() && !rule.Enabled {
+				enabled = false
+			}
+		}
+	}
+
+	f.enabled = enabled
+	f.expiresAt = time.Now().Add(f.cacheTTL)
+
+	return enabled, nil
+}
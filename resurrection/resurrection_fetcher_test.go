@@ -0,0 +1,123 @@
+package resurrection_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/resurrection"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		cacheTTL            time.Duration
+		boshClient          *directorfakes.FakeDirector
+		resurrectionFetcher *Fetcher
+		resurrectionConfigs []director.Config
+		resurrectionEnabled bool
+		err                 error
+	)
+
+	BeforeEach(func() {
+		cacheTTL = 0
+		boshClient = &directorfakes.FakeDirector{}
+		resurrectionConfigs = []director.Config{}
+	})
+
+	JustBeforeEach(func() {
+		boshClient.ListConfigsReturns(resurrectionConfigs, nil)
+		resurrectionFetcher = NewFetcher(boshClient, cacheTTL)
+		resurrectionEnabled, err = resurrectionFetcher.Enabled()
+	})
+
+	It("reads the latest resurrection configs", func() {
+		Expect(boshClient.ListConfigsCallCount()).To(Equal(1))
+		limit, filter := boshClient.ListConfigsArgsForCall(0)
+		Expect(limit).To(Equal(1))
+		Expect(filter).To(Equal(director.ConfigsFilter{Type: "resurrection"}))
+	})
+
+	Context("when there are no resurrection configs", func() {
+		It("returns enabled", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resurrectionEnabled).To(BeTrue())
+		})
+	})
+
+	Context("when a rule disables resurrection for every instance", func() {
+		BeforeEach(func() {
+			resurrectionConfigs = []director.Config{
+				{ID: "1", Name: "default", Type: "resurrection", Content: "rules:\n- enabled: false\n"},
+			}
+		})
+
+		It("returns disabled", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resurrectionEnabled).To(BeFalse())
+		})
+	})
+
+	Context("when a rule disables resurrection for some deployments", func() {
+		BeforeEach(func() {
+			resurrectionConfigs = []director.Config{
+				{ID: "1", Name: "default", Type: "resurrection", Content: "rules:\n- enabled: false\n  include:\n    deployments: [fake-deployment-name]\n"},
+			}
+		})
+
+		It("returns enabled", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resurrectionEnabled).To(BeTrue())
+		})
+	})
+
+	Context("when a resurrection config cannot be parsed", func() {
+		BeforeEach(func() {
+			resurrectionConfigs = []director.Config{
+				{ID: "1", Name: "default", Type: "resurrection", Content: "rules: {"},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error while parsing resurrection Config `default`"))
+		})
+	})
+
+	Context("when reading the resurrection configs fails", func() {
+		JustBeforeEach(func() {
+			boshClient.ListConfigsReturns(nil, errors.New("no configs"))
+			resurrectionEnabled, err = resurrectionFetcher.Enabled()
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Error while reading resurrection Configs: no configs"))
+		})
+	})
+
+	Context("when the resurrection state is cached", func() {
+		BeforeEach(func() {
+			cacheTTL = time.Hour
+		})
+
+		JustBeforeEach(func() {
+			resurrectionEnabled, err = resurrectionFetcher.Enabled()
+		})
+
+		It("does not read the resurrection configs again", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resurrectionEnabled).To(BeTrue())
+			Expect(boshClient.ListConfigsCallCount()).To(Equal(1))
+		})
+	})
+})
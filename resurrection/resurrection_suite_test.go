@@ -0,0 +1,13 @@
+package resurrection_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestResurrection(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resurrection Suite")
+}
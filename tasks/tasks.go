@@ -0,0 +1,7 @@
+package tasks
+
+type TaskInfo struct {
+	ID         int    `json:"id"`
+	State      string `json:"state"`
+	Deployment string `json:"deployment"`
+}
@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+)
+
+type Fetcher struct {
+	boshClient      director.Director
+	limit           int
+	requestDuration prometheus.ObserverVec
+}
+
+func NewFetcher(boshClient director.Director, limit int, requestDuration prometheus.ObserverVec) *Fetcher {
+	return &Fetcher{
+		boshClient:      boshClient,
+		limit:           limit,
+		requestDuration: requestDuration,
+	}
+}
+
+func (f *Fetcher) Tasks() ([]TaskInfo, error) {
+	var tasksInfo []TaskInfo
+
+	log.Debugf("Reading %d recent Tasks...", f.limit)
+	begun := time.Now()
+	tasks, err := f.boshClient.RecentTasks(f.limit, director.TasksFilter{})
+	if f.requestDuration != nil {
+		f.requestDuration.WithLabelValues("tasks").Observe(time.Since(begun).Seconds())
+	}
+	if err != nil {
+		return tasksInfo, fmt.Errorf("Error while reading recent Tasks: %v", err)
+	}
+
+	for _, task := range tasks {
+		tasksInfo = append(tasksInfo, TaskInfo{
+			ID:         task.ID(),
+			State:      task.State(),
+			Deployment: task.DeploymentName(),
+		})
+	}
+
+	return tasksInfo, nil
+}
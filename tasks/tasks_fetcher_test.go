@@ -0,0 +1,119 @@
+package tasks_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/tasks"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		limit           int
+		requestDuration prometheus.ObserverVec
+		boshClient      *directorfakes.FakeDirector
+		tasksFetcher    *Fetcher
+	)
+
+	BeforeEach(func() {
+		limit = 50
+		requestDuration = nil
+		boshClient = &directorfakes.FakeDirector{}
+	})
+
+	JustBeforeEach(func() {
+		tasksFetcher = NewFetcher(boshClient, limit, requestDuration)
+	})
+
+	Describe("Tasks", func() {
+		var (
+			tasks []TaskInfo
+			err   error
+		)
+
+		BeforeEach(func() {
+			boshClient.RecentTasksReturns([]director.Task{
+				&directorfakes.FakeTask{
+					IDStub:             func() int { return 2 },
+					StateStub:          func() string { return "processing" },
+					DeploymentNameStub: func() string { return "fake-deployment-name" },
+				},
+				&directorfakes.FakeTask{
+					IDStub:             func() int { return 1 },
+					StateStub:          func() string { return "error" },
+					DeploymentNameStub: func() string { return "" },
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			tasks, err = tasksFetcher.Tasks()
+		})
+
+		It("returns the recent tasks", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tasks).To(Equal([]TaskInfo{
+				{
+					ID:         2,
+					State:      "processing",
+					Deployment: "fake-deployment-name",
+				},
+				{
+					ID:         1,
+					State:      "error",
+					Deployment: "",
+				},
+			}))
+		})
+
+		It("bounds the number of tasks read from the director", func() {
+			Expect(boshClient.RecentTasksCallCount()).To(Equal(1))
+			readLimit, _ := boshClient.RecentTasksArgsForCall(0)
+			Expect(readLimit).To(Equal(limit))
+		})
+
+		Context("when the director request duration is observed", func() {
+			var requestDurationMetric *prometheus.HistogramVec
+
+			BeforeEach(func() {
+				requestDurationMetric = prometheus.NewHistogramVec(
+					prometheus.HistogramOpts{
+						Name: "test_request_duration_seconds",
+						Help: "Test Histogram.",
+					},
+					[]string{"endpoint"},
+				)
+				requestDuration = requestDurationMetric
+			})
+
+			It("observes the tasks call", func() {
+				metric := &dto.Metric{}
+				Expect(requestDurationMetric.WithLabelValues("tasks").(prometheus.Histogram).Write(metric)).To(Succeed())
+				Expect(metric.GetHistogram().GetSampleCount()).To(Equal(uint64(1)))
+			})
+		})
+
+		Context("when reading the recent tasks fails", func() {
+			BeforeEach(func() {
+				boshClient.RecentTasksReturns([]director.Task{}, errors.New("no tasks"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading recent Tasks"))
+			})
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package textfile
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+)
+
+const Filename = "bosh_exporter.prom"
+
+type Writer struct {
+	filename                          string
+	gatherer                          prometheus.Gatherer
+	interval                          time.Duration
+	registry                          *prometheus.Registry
+	lastTextfileScrapeTimestampMetric prometheus.Gauge
+}
+
+// NewWriter returns a Writer that periodically renders the metrics of
+// gatherer into a bosh_exporter.prom file inside directory, so they can be
+// picked up by the node_exporter textfile collector.
+func NewWriter(
+	namespace string,
+	environment string,
+	boshName string,
+	boshUUID string,
+	directory string,
+	gatherer prometheus.Gatherer,
+	interval time.Duration,
+) *Writer {
+	lastTextfileScrapeTimestampMetric := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "",
+			Name:      "last_textfile_scrape_timestamp",
+			Help:      "Number of seconds since 1970 since last scrape written to the textfile.",
+			ConstLabels: prometheus.Labels{
+				"environment": environment,
+				"bosh_name":   boshName,
+				"bosh_uuid":   boshUUID,
+			},
+		},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(lastTextfileScrapeTimestampMetric)
+
+	return &Writer{
+		filename:                          filepath.Join(directory, Filename),
+		gatherer:                          gatherer,
+		interval:                          interval,
+		registry:                          registry,
+		lastTextfileScrapeTimestampMetric: lastTextfileScrapeTimestampMetric,
+	}
+}
+
+// Write gathers the metrics once and atomically replaces the textfile.
+func (w *Writer) Write() error {
+	w.lastTextfileScrapeTimestampMetric.Set(float64(time.Now().Unix()))
+
+	return prometheus.WriteToTextfile(w.filename, prometheus.Gatherers{w.gatherer, w.registry})
+}
+
+// Run writes the textfile every interval until ctx is done. A write in
+// progress is completed before returning.
+func (w *Writer) Run(ctx context.Context) {
+	log.Infof("Writing metrics to textfile `%s` every %s", w.filename, w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Write(); err != nil {
+			log.Errorf("Error writing metrics to textfile `%s`: %v", w.filename, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package textfile_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/bosh-prometheus/bosh_exporter/textfile"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		err       error
+		directory string
+		registry  *prometheus.Registry
+		writer    *Writer
+	)
+
+	BeforeEach(func() {
+		directory = GinkgoT().TempDir()
+
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "test_namespace",
+			Name:      "test_gauge",
+			Help:      "Test Gauge.",
+		})
+		gauge.Set(float64(1))
+
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(gauge)
+	})
+
+	JustBeforeEach(func() {
+		writer = NewWriter("test_namespace", "test_environment", "fake-bosh-name", "fake-bosh-uuid", directory, registry, 0)
+		err = writer.Write()
+	})
+
+	Describe("Write", func() {
+		It("writes the gathered metrics to the textfile", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			content, err := os.ReadFile(filepath.Join(directory, Filename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("test_namespace_test_gauge 1\n"))
+		})
+
+		It("includes the scrape timestamp", func() {
+			content, err := os.ReadFile(filepath.Join(directory, Filename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(MatchRegexp(`test_namespace_last_textfile_scrape_timestamp\{bosh_name="fake-bosh-name",bosh_uuid="fake-bosh-uuid",environment="test_environment"\} \d`))
+		})
+
+		It("does not leave temporary files behind", func() {
+			entries, err := os.ReadDir(directory)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal(Filename))
+		})
+
+		Context("when the directory does not exist", func() {
+			BeforeEach(func() {
+				directory = filepath.Join(directory, "missing")
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+			done   chan struct{}
+		)
+
+		JustBeforeEach(func() {
+			Expect(os.Remove(filepath.Join(directory, Filename))).To(Succeed())
+
+			ctx, cancel = context.WithCancel(context.Background())
+			DeferCleanup(cancel)
+
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				NewWriter("test_namespace", "test_environment", "fake-bosh-name", "fake-bosh-uuid", directory, registry, time.Hour).Run(ctx)
+			}()
+		})
+
+		It("writes the textfile right away", func() {
+			Eventually(filepath.Join(directory, Filename)).Should(BeAnExistingFile())
+			Consistently(done).ShouldNot(BeClosed())
+		})
+
+		It("returns once the context is done", func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
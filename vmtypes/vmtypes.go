@@ -0,0 +1,8 @@
+package vmtypes
+
+type VMTypeInfo struct {
+	Name   string   `json:"name"`
+	CPU    *float64 `json:"cpu"`
+	RAMMB  *float64 `json:"ram_mb"`
+	DiskMB *float64 `json:"disk_mb"`
+}
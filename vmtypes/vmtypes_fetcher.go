@@ -0,0 +1,78 @@
+package vmtypes
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/prometheus/common/log"
+	"gopkg.in/yaml.v2"
+
+	"github.com/bosh-prometheus/bosh_exporter/configs"
+)
+
+type cloudConfig struct {
+	VMTypes []struct {
+		Name            string                 `yaml:"name"`
+		CloudProperties map[string]interface{} `yaml:"cloud_properties"`
+	} `yaml:"vm_types"`
+}
+
+type Fetcher struct {
+	boshClient director.Director
+}
+
+func NewFetcher(boshClient director.Director) *Fetcher {
+	return &Fetcher{boshClient: boshClient}
+}
+
+// VMTypes returns the resources requested by the vm types of the latest cloud
+// configs. Only CPIs whose cloud properties set cpu, ram or disk (e.g.
+// vSphere) request resources explicitly, so vm types setting none of them are
+// omitted.
+func (f *Fetcher) VMTypes() ([]VMTypeInfo, error) {
+	var vmTypesInfo []VMTypeInfo
+
+	log.Debugf("Reading latest Cloud Configs...")
+	configs, err := f.boshClient.ListConfigs(1, director.ConfigsFilter{Type: configs.CloudConfigType})
+	if err != nil {
+		return vmTypesInfo, fmt.Errorf("Error while reading Cloud Configs: %v", err)
+	}
+
+	for _, config := range configs {
+		var content cloudConfig
+		if err := yaml.Unmarshal([]byte(config.Content), &content); err != nil {
+			return []VMTypeInfo{}, fmt.Errorf("Error while parsing Cloud Config `%s`: %v", config.Name, err)
+		}
+
+		for _, vmType := range content.VMTypes {
+			vmTypeInfo := VMTypeInfo{
+				Name:   vmType.Name,
+				CPU:    numericProperty(vmType.CloudProperties, "cpu"),
+				RAMMB:  numericProperty(vmType.CloudProperties, "ram"),
+				DiskMB: numericProperty(vmType.CloudProperties, "disk"),
+			}
+			if vmTypeInfo.CPU == nil && vmTypeInfo.RAMMB == nil && vmTypeInfo.DiskMB == nil {
+				continue
+			}
+
+			vmTypesInfo = append(vmTypesInfo, vmTypeInfo)
+		}
+	}
+
+	return vmTypesInfo, nil
+}
+
+func numericProperty(properties map[string]interface{}, name string) *float64 {
+	var value float64
+
+	switch property := properties[name].(type) {
+	case int:
+		value = float64(property)
+	case float64:
+		value = property
+	default:
+		return nil
+	}
+
+	return &value
+}
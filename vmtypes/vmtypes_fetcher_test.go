@@ -0,0 +1,121 @@
+package vmtypes_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/director"
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	"github.com/prometheus/common/log"
+
+	. "github.com/bosh-prometheus/bosh_exporter/vmtypes"
+)
+
+func init() {
+	log.Base().SetLevel("fatal")
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		boshClient     *directorfakes.FakeDirector
+		vmTypesFetcher *Fetcher
+	)
+
+	BeforeEach(func() {
+		boshClient = &directorfakes.FakeDirector{}
+	})
+
+	JustBeforeEach(func() {
+		vmTypesFetcher = NewFetcher(boshClient)
+	})
+
+	Describe("VMTypes", func() {
+		var (
+			vmTypes []VMTypeInfo
+			err     error
+		)
+
+		BeforeEach(func() {
+			boshClient.ListConfigsReturns([]director.Config{
+				{
+					ID:   "1",
+					Name: "default",
+					Type: "cloud",
+					Content: `
+vm_types:
+- name: small
+  cloud_properties:
+    cpu: 2
+    ram: 4096
+    disk: 10240
+- name: large
+  cloud_properties:
+    cpu: 8
+    ram: 32768.5
+- name: aws
+  cloud_properties:
+    instance_type: m5.large
+`,
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			vmTypes, err = vmTypesFetcher.VMTypes()
+		})
+
+		It("returns the requested resources of the vm types", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vmTypes).To(Equal([]VMTypeInfo{
+				{
+					Name:   "small",
+					CPU:    float64Ptr(2),
+					RAMMB:  float64Ptr(4096),
+					DiskMB: float64Ptr(10240),
+				},
+				{
+					Name:  "large",
+					CPU:   float64Ptr(8),
+					RAMMB: float64Ptr(32768.5),
+				},
+			}))
+		})
+
+		It("reads the latest cloud configs", func() {
+			Expect(boshClient.ListConfigsCallCount()).To(Equal(1))
+			limit, filter := boshClient.ListConfigsArgsForCall(0)
+			Expect(limit).To(Equal(1))
+			Expect(filter).To(Equal(director.ConfigsFilter{Type: "cloud"}))
+		})
+
+		Context("when a cloud config cannot be parsed", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsReturns([]director.Config{
+					{ID: "1", Name: "default", Type: "cloud", Content: "vm_types: ["},
+				}, nil)
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while parsing Cloud Config `default`"))
+			})
+		})
+
+		Context("when reading the cloud configs fails", func() {
+			BeforeEach(func() {
+				boshClient.ListConfigsReturns([]director.Config{}, errors.New("no configs"))
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Error while reading Cloud Configs"))
+			})
+		})
+	})
+})
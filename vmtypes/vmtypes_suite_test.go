@@ -0,0 +1,13 @@
+package vmtypes_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestVMTypes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "VMTypes Suite")
+}